@@ -0,0 +1,66 @@
+package goDOM
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// CanonicalOptions configures Canonicalize.
+type CanonicalOptions struct {
+	// IgnoreAttributes are the names of the attributes left out, like "nonce". Names are compared case-insensitively.
+	IgnoreAttributes []string
+	// IgnoreElements is a selector of the elements left out together with their content,
+	// like `input[name="csrf_token"], meta[name="csrf-token"]`. See Rewriter.OnElement for the supported selectors.
+	IgnoreElements string
+	// KeepComments includes the comments. Otherwise only conditional comments, like <!--[if IE]>, are kept.
+	KeepComments bool
+}
+
+// Canonicalize returns a normalized representation of the DOM which only changes if the content changes.
+//
+// Attributes are sorted by name and written in double quotes, boolean attributes are written with an empty
+// value and all end tags are written. Whitespace is collapsed and removed where it does not affect rendering,
+// like in Minify, and character references are replaced by the characters. Content of whitespace-sensitive
+// elements like <pre> and <script> is written unchanged. An error is returned if IgnoreElements is invalid.
+// This method is not part of the Javascript Document interface.
+func (d *DOM) Canonicalize(opts CanonicalOptions) (string, error) {
+	m := &minifier{
+		opts: MinifyOptions{
+			KeepComments:          opts.KeepComments,
+			KeepEndTags:           true,
+			KeepDefaultAttributes: true,
+			KeepAttributeQuotes:   true,
+		},
+		r:                &renderer{opts: RenderOptions{Quote: QuoteDouble, SortAttributes: true}},
+		ignoreAttributes: opts.IgnoreAttributes,
+		xml:              d.isXML(),
+	}
+	if opts.IgnoreElements != "" {
+		s, err := parseSelector(opts.IgnoreElements)
+		if err != nil {
+			return "", err
+		}
+		m.ignoreElements = s
+	}
+	return m.write(d), nil
+}
+
+// Hash returns the hex-encoded SHA-256 checksum of the canonical representation of the DOM,
+// see Canonicalize. Subtrees with the same content have the same hash.
+// This method is not part of the Javascript Document interface.
+func (d *DOM) Hash() string {
+	hash, _ := d.HashWithOptions(CanonicalOptions{})
+	return hash
+}
+
+// HashWithOptions returns the hex-encoded SHA-256 checksum of the representation of the DOM returned
+// by Canonicalize with the given options. An error is returned if the options are invalid.
+// This method is not part of the Javascript Document interface.
+func (d *DOM) HashWithOptions(opts CanonicalOptions) (string, error) {
+	canonical, err := d.Canonicalize(opts)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(canonical))
+	return hex.EncodeToString(sum[:]), nil
+}
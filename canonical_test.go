@@ -0,0 +1,80 @@
+package goDOM_test
+
+import (
+	"testing"
+
+	"github.com/richi0/goDOM"
+)
+
+func canonicalize(t *testing.T, dom *goDOM.DOM, opts goDOM.CanonicalOptions) string {
+	t.Helper()
+	canonical, err := dom.Canonicalize(opts)
+	if err != nil {
+		t.Fatal("Unexpected canonicalize error:", err)
+	}
+	return canonical
+}
+
+func TestCanonicalize(t *testing.T) {
+	dom := createDOMFromString(`<div id="d" class='a'>
+  <!-- note -->
+  <p>Some   &amp; <b>bold</b>
+    text</p><input disabled="disabled" value=x nonce="1">
+</div>`)
+	tests := []struct {
+		opts     goDOM.CanonicalOptions
+		expected string
+	}{
+		{goDOM.CanonicalOptions{},
+			`<div class="a" id="d"><p>Some &amp; <b>bold</b> text</p><input disabled="" nonce="1" value="x"></div>`},
+		{goDOM.CanonicalOptions{IgnoreAttributes: []string{"NONCE", "id"}, KeepComments: true},
+			`<div class="a"><!-- note --><p>Some &amp; <b>bold</b> text</p><input disabled="" value="x"></div>`},
+		{goDOM.CanonicalOptions{IgnoreElements: "input, b"},
+			`<div class="a" id="d"><p>Some &amp; text</p></div>`},
+	}
+	for _, test := range tests {
+		if canonical := canonicalize(t, dom.GetElementById("d"), test.opts); canonical != test.expected {
+			t.Errorf("Expected\n%s\nbut got\n%s", test.expected, canonical)
+		}
+	}
+	if _, err := dom.Canonicalize(goDOM.CanonicalOptions{IgnoreElements: "div["}); err == nil {
+		t.Error("Expected an error for an invalid selector")
+	}
+}
+
+func TestHash(t *testing.T) {
+	a := createDOMFromString(`<html><head><meta name="csrf-token" content="abc"></head><body>
+  <div id="x" class="y"><p>Hello   world</p></div>
+</body></html>`)
+	b := createDOMFromString(`<html><head><meta content="def" name="csrf-token"></head><body><div class=y id=x><p>Hello
+world</p></div></body></html>`)
+	if a.Hash() == b.Hash() {
+		t.Error("Expected different hashes for different tokens")
+	}
+	opts := goDOM.CanonicalOptions{IgnoreElements: `meta[name="csrf-token"]`}
+	hashA, err := a.HashWithOptions(opts)
+	if err != nil {
+		t.Fatal("Unexpected hash error:", err)
+	}
+	hashB, _ := b.HashWithOptions(opts)
+	if hashA != hashB {
+		t.Error("Expected the same hash if only ignored elements, attribute order and whitespace differ")
+	}
+	if len(hashA) != 64 {
+		t.Errorf("Expected a hex-encoded SHA-256 hash but got %q", hashA)
+	}
+	if a.GetElementById("x").Hash() != b.GetElementById("x").Hash() {
+		t.Error("Expected the same hash for subtrees with the same content")
+	}
+	if a.GetElementById("x").Hash() == a.Hash() {
+		t.Error("Expected different hashes for the subtree and the document")
+	}
+	hidden := createDOMFromString(`<p hidden="hidden">x</p><p hidden="until-found">x</p><p hidden>x</p>`).GetElementsByTagName("p")
+	if hidden[0].Hash() != hidden[2].Hash() || hidden[0].Hash() == hidden[1].Hash() {
+		t.Error("Expected the same hash only for the same state of the hidden attribute")
+	}
+	b.GetElementsByTagName("p")[0].SetAttribute("class", "z")
+	if a.GetElementById("x").Hash() == b.GetElementById("x").Hash() {
+		t.Error("Expected a different hash after changing an attribute")
+	}
+}
@@ -157,3 +157,146 @@ var acceptedAttributes = []string{
 	"usemap",
 	"value",
 }
+
+var nonRenderedElements = []string{
+	"area",
+	"audio",
+	"base",
+	"basefont",
+	"datalist",
+	"head",
+	"iframe",
+	"link",
+	"meta",
+	"noembed",
+	"noframes",
+	"noscript",
+	"param",
+	"rp",
+	"script",
+	"style",
+	"template",
+	"title",
+}
+
+var preformattedElements = []string{
+	"listing",
+	"plaintext",
+	"pre",
+	"textarea",
+	"xmp",
+}
+
+var blockElements = []string{
+	"address",
+	"article",
+	"aside",
+	"blockquote",
+	"body",
+	"caption",
+	"center",
+	"dd",
+	"details",
+	"dialog",
+	"dir",
+	"div",
+	"dl",
+	"dt",
+	"fieldset",
+	"figcaption",
+	"figure",
+	"footer",
+	"form",
+	"h1",
+	"h2",
+	"h3",
+	"h4",
+	"h5",
+	"h6",
+	"header",
+	"hgroup",
+	"hr",
+	"html",
+	"legend",
+	"li",
+	"listing",
+	"main",
+	"menu",
+	"nav",
+	"ol",
+	"optgroup",
+	"option",
+	"p",
+	"plaintext",
+	"pre",
+	"section",
+	"summary",
+	"table",
+	"tbody",
+	"tfoot",
+	"thead",
+	"tr",
+	"ul",
+	"xmp",
+}
+
+var optionalEndTagElements = []string{
+	"body",
+	"caption",
+	"colgroup",
+	"dd",
+	"dt",
+	"head",
+	"html",
+	"li",
+	"optgroup",
+	"option",
+	"p",
+	"rb",
+	"rp",
+	"rt",
+	"rtc",
+	"tbody",
+	"td",
+	"tfoot",
+	"th",
+	"thead",
+	"tr",
+}
+
+var layoutElements = []string{
+	"col",
+	"colgroup",
+	"frame",
+	"frameset",
+	"head",
+	"td",
+	"th",
+}
+
+var metadataElements = []string{
+	"base",
+	"link",
+	"meta",
+	"noscript",
+	"script",
+	"style",
+	"template",
+	"title",
+}
+
+var escapableRawTextElements = []string{
+	"textarea",
+	"title",
+}
+
+var rawTextElements = []string{
+	"iframe",
+	"noembed",
+	"noframes",
+	"noscript",
+	"plaintext",
+	"script",
+	"style",
+	"xmp",
+}
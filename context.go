@@ -0,0 +1,64 @@
+package goDOM
+
+import (
+	"context"
+	"io"
+)
+
+// cancellationCheckInterval is the number of nodes processed between two checks for cancellation.
+const cancellationCheckInterval = 1024
+
+// NewContext is like NewWithOptions but stops reading the input and returns ctx.Err()
+// once the context is canceled or its deadline is exceeded.
+func NewContext(ctx context.Context, r io.Reader, opts ParseOptions) (*DOM, error) {
+	dom, err := NewWithOptions(&contextReader{ctx, r}, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return dom, nil
+}
+
+// QueryContext returns a slice of all elements for which match returns true.
+// It returns ctx.Err() if the context is done before all elements were checked.
+// This method is not part of the Javascript Document interface.
+func (d *DOM) QueryContext(ctx context.Context, match func(*DOM) bool) ([]*DOM, error) {
+	elements := make([]*DOM, 0)
+	nodes, err := d.getFlatElementListContext(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	for i, node := range nodes {
+		if err := checkContext(ctx, i); err != nil {
+			return nil, err
+		}
+		if match(node) {
+			elements = append(elements, node)
+		}
+	}
+	return elements, nil
+}
+
+// contextReader reads from r until ctx is done.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+// Read implements io.Reader.
+func (c *contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
+
+// checkContext returns ctx.Err() for every cancellationCheckInterval-th iteration i.
+func checkContext(ctx context.Context, i int) error {
+	if i%cancellationCheckInterval != 0 {
+		return nil
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,83 @@
+package goDOM_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/richi0/goDOM"
+)
+
+func TestNewContext(t *testing.T) {
+	indexHTML, err := os.Open("test_data/index.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer indexHTML.Close()
+	dom, err := goDOM.NewContext(context.Background(), indexHTML, goDOM.ParseOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dom.GetElementsByTagName("a")) != 1182 {
+		t.Error("Expected 1182 elements but found", len(dom.GetElementsByTagName("a")))
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = goDOM.NewContext(ctx, strings.NewReader("<p>text</p>"), goDOM.ParseOptions{})
+	if !errors.Is(err, context.Canceled) {
+		t.Error("Expected context.Canceled but got", err)
+	}
+}
+
+func TestContextVariants(t *testing.T) {
+	dom := createTestDOM()
+	ctx := context.Background()
+	links, err := dom.QueryContext(ctx, func(element *goDOM.DOM) bool { return element.TagName() == "a" })
+	if err != nil || len(links) != 1182 {
+		t.Error("Expected 1182 links but found", len(links), err)
+	}
+	text, err := dom.TextContext(ctx, true)
+	if err != nil || text != dom.Text(true) {
+		t.Error("Expected TextContext to match Text", err)
+	}
+	innerText, err := dom.InnerTextContext(ctx)
+	if err != nil || innerText != dom.InnerText() {
+		t.Error("Expected InnerTextContext to match InnerText", err)
+	}
+	elements, err := dom.GetElementsByTextMatchContext(ctx, "Enumerated types", goDOM.TextMatchOptions{})
+	if err != nil || len(elements) != 2 {
+		t.Error("Expected 2 elements but found", len(elements), err)
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	dom = createTestDOM()
+	if _, err := dom.QueryContext(canceled, func(*goDOM.DOM) bool { return true }); !errors.Is(err, context.Canceled) {
+		t.Error("Expected QueryContext to be canceled but got", err)
+	}
+	if _, err := dom.TextContext(canceled, true); !errors.Is(err, context.Canceled) {
+		t.Error("Expected TextContext to be canceled but got", err)
+	}
+	if _, err := dom.InnerTextContext(canceled); !errors.Is(err, context.Canceled) {
+		t.Error("Expected InnerTextContext to be canceled but got", err)
+	}
+	if _, err := dom.GetElementsByTextMatchContext(canceled, "x", goDOM.TextMatchOptions{}); !errors.Is(err, context.Canceled) {
+		t.Error("Expected GetElementsByTextMatchContext to be canceled but got", err)
+	}
+	if err := dom.RemoveStyleAttributesContext(canceled); !errors.Is(err, context.Canceled) {
+		t.Error("Expected RemoveStyleAttributesContext to be canceled but got", err)
+	}
+}
+
+func TestRemoveStyleAttributesContext(t *testing.T) {
+	dom := createDOMFromString(`<div class="a" title="t"><a href="/x" style="color: red">x</a><img src="y.png" id="i"></div>`)
+	if err := dom.RemoveStyleAttributesContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	html, _ := dom.GetElementsByTagName("div")[0].Render()
+	if html != `<div title="t"><a href="/x">x</a><img src="y.png"/></div>` {
+		t.Error("Unexpected attributes:", html)
+	}
+}
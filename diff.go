@@ -0,0 +1,835 @@
+package goDOM
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+// DiffOptions configures which differences Diff ignores. The zero value compares everything.
+type DiffOptions struct {
+	// IgnoreWhitespace ignores text nodes which only contain whitespace and differences in runs of whitespace.
+	IgnoreWhitespace bool
+	// IgnoreComments ignores comments.
+	IgnoreComments bool
+	// IgnoreAttributes are the names of the attributes which are not compared, like "nonce".
+	// Names are compared case-insensitively.
+	IgnoreAttributes []string
+}
+
+// EditOp is the kind of an Edit.
+type EditOp string
+
+// The kinds of edits of an EditScript.
+const (
+	EditInsert          EditOp = "insert"
+	EditDelete          EditOp = "delete"
+	EditMove            EditOp = "move"
+	EditUpdateText      EditOp = "update-text"
+	EditUpdateAttribute EditOp = "update-attribute"
+)
+
+// An Edit is a single change of an EditScript.
+//
+// Nodes are addressed by paths of child indices from the root. The paths refer to the tree as it is
+// when the edit is applied, after all previous edits of the script.
+type Edit struct {
+	Op EditOp `json:"op"`
+	// Path is the path of the node the edit applies to. For EditInsert and EditMove it is the
+	// path of the node after the edit.
+	Path []int `json:"path"`
+	// Location is a readable form of Path, like "/html[1]/body[1]/p[2]".
+	Location string `json:"location"`
+	// From and FromLocation are the path of the moved node before an EditMove.
+	From         []int  `json:"from,omitempty"`
+	FromLocation string `json:"fromLocation,omitempty"`
+	// Node is the inserted subtree of an EditInsert or the removed subtree of an EditDelete.
+	Node *JSONNode `json:"node,omitempty"`
+	// Hash is the checksum of the removed or moved subtree of an EditDelete or EditMove.
+	Hash string `json:"hash,omitempty"`
+	// Namespace and Name identify the attribute of an EditUpdateAttribute.
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name,omitempty"`
+	// Index is the position of an attribute added by an EditUpdateAttribute among the attributes of the element.
+	Index int `json:"index,omitempty"`
+	// Old and New are the text or the attribute value before and after an EditUpdateText or
+	// EditUpdateAttribute. A nil value means the attribute is absent.
+	Old *string `json:"old,omitempty"`
+	New *string `json:"new,omitempty"`
+}
+
+// An EditScript is the list of edits which turns one tree into another, see Diff.
+// The JSON encoding of an EditScript is the machine-readable report of the changes.
+type EditScript struct {
+	Edits []Edit `json:"edits"`
+}
+
+// Diff returns the edits which turn a into b.
+//
+// Nodes of both trees are matched by their id attribute, by identical content and by the similarity
+// of their descendants. The edit script is computed from the matching following Chawathe et al.,
+// "Change Detection in Hierarchically Structured Information". Matched nodes whose content changed
+// are updated, matched nodes at a different place are moved, and the other nodes are inserted or deleted.
+// Added attributes are inserted at their position, and attributes in a different order are removed and
+// added again at their new position.
+// An error is returned if the roots of a and b are of a different kind.
+// This function is not part of the Javascript Document interface.
+func Diff(a, b *DOM, opts DiffOptions) (*EditScript, error) {
+	d, err := diff(a.node, b.node, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &EditScript{Edits: d.edits}, nil
+}
+
+// diff computes the edits which turn the tree of a into the tree of b. The edits are applied
+// to the copy of a in the root of the returned differ.
+func diff(a, b *html.Node, opts DiffOptions) (*differ, error) {
+	if !sameLabel(a, b) {
+		return nil, errors.New("goDOM: cannot diff nodes of a different kind")
+	}
+	d := &differ{
+		opts:    opts,
+		partner: make(map[*html.Node]*html.Node),
+		hashes:  make(map[*html.Node]uint64),
+		sizes:   make(map[*html.Node]int),
+		inOrder: make(map[*html.Node]bool),
+		order:   make(map[*html.Node]int),
+	}
+	// The edits are applied to a copy of a, so that the paths refer to the tree after the previous edits.
+	d.root = cloneDeep(a)
+	d.measure(d.root)
+	d.measure(b)
+	for i, n := range preOrder(b) {
+		d.order[n] = i
+	}
+	d.match(d.root, b)
+	d.matchByID(b)
+	d.matchByHash(b)
+	d.matchBottomUp()
+	d.matchTopDown(b)
+	d.generate(b)
+	return d, nil
+}
+
+// differ holds the state of Diff. root is the copy of the old tree the edits are applied to.
+// order is the document order of the nodes of the new tree, which breaks ties between candidates.
+// If ordered is set, the order of the attributes is part of the content hash.
+type differ struct {
+	opts    DiffOptions
+	ordered bool
+	root    *html.Node
+	partner map[*html.Node]*html.Node
+	hashes  map[*html.Node]uint64
+	sizes   map[*html.Node]int
+	inOrder map[*html.Node]bool
+	order   map[*html.Node]int
+	edits   []Edit
+}
+
+// isIgnored returns a boolean value indicating whether the node is left out of the comparison.
+func (d *differ) isIgnored(node *html.Node) bool {
+	return (d.opts.IgnoreWhitespace && node.Type == html.TextNode && strings.TrimSpace(node.Data) == "") ||
+		(d.opts.IgnoreComments && node.Type == html.CommentNode)
+}
+
+// text returns the text of the node as it is compared.
+func (d *differ) text(node *html.Node) string {
+	if d.opts.IgnoreWhitespace && node.Type == html.TextNode {
+		return strings.TrimSpace(collapseWhitespace(node.Data))
+	}
+	return node.Data
+}
+
+// attributes returns the attributes of the node which are compared.
+func (d *differ) attributes(node *html.Node) []html.Attribute {
+	attrs := make([]html.Attribute, 0, len(node.Attr))
+	for _, attr := range node.Attr {
+		if !slices.ContainsFunc(d.opts.IgnoreAttributes, func(name string) bool { return strings.EqualFold(name, attributeName(attr)) }) {
+			attrs = append(attrs, attr)
+		}
+	}
+	return attrs
+}
+
+// children returns the children of the node which are compared.
+func (d *differ) children(node *html.Node) []*html.Node {
+	children := make([]*html.Node, 0)
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if !d.isIgnored(child) {
+			children = append(children, child)
+		}
+	}
+	return children
+}
+
+// measure computes the content hash and the number of compared nodes of every subtree of the tree.
+func (d *differ) measure(root *html.Node) {
+	for _, n := range postOrder(root) {
+		if d.isIgnored(n) {
+			continue
+		}
+		h := fnv.New64a()
+		writeLabel(h, n)
+		h.Write([]byte(d.text(n)))
+		h.Write([]byte{0})
+		attrs := d.attributes(n)
+		if !d.ordered {
+			sort.Slice(attrs, func(i, j int) bool { return attributeName(attrs[i]) < attributeName(attrs[j]) })
+		}
+		for _, attr := range attrs {
+			h.Write([]byte(attributeName(attr) + "=" + attr.Val + "\x00"))
+		}
+		size := 1
+		for _, child := range d.children(n) {
+			h.Write(binary.LittleEndian.AppendUint64(nil, d.hashes[child]))
+			size += d.sizes[child]
+		}
+		d.hashes[n] = h.Sum64()
+		d.sizes[n] = size
+	}
+}
+
+// writeLabel writes the type, namespace and tag name of the node.
+func writeLabel(w interface{ Write([]byte) (int, error) }, n *html.Node) {
+	w.Write([]byte{byte(n.Type)})
+	if n.Type == html.ElementNode {
+		w.Write([]byte(n.Namespace + ":" + n.Data + "\x00"))
+	}
+}
+
+// sameLabel returns a boolean value indicating whether the nodes are of the same type and, for
+// elements, have the same tag name. Only nodes with the same label are matched.
+func sameLabel(a, b *html.Node) bool {
+	return a.Type == b.Type && (a.Type != html.ElementNode || (a.Data == b.Data && a.Namespace == b.Namespace))
+}
+
+// match records that the old node x corresponds to the new node y.
+func (d *differ) match(x, y *html.Node) {
+	d.partner[x] = y
+	d.partner[y] = x
+}
+
+// matchSubtrees matches two subtrees with the same content hash node by node.
+func (d *differ) matchSubtrees(x, y *html.Node) {
+	stack := [][2]*html.Node{{x, y}}
+	for len(stack) > 0 {
+		pair := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if d.partner[pair[0]] != nil || d.partner[pair[1]] != nil {
+			continue
+		}
+		d.match(pair[0], pair[1])
+		xs, ys := d.children(pair[0]), d.children(pair[1])
+		for i := 0; i < len(xs) && i < len(ys); i++ {
+			stack = append(stack, [2]*html.Node{xs[i], ys[i]})
+		}
+	}
+}
+
+// matchByID matches elements with the same tag name and the same id, if the id is unique in both trees.
+func (d *differ) matchByID(newRoot *html.Node) {
+	oldIDs, newIDs := d.elementsByID(d.root), d.elementsByID(newRoot)
+	for _, x := range preOrder(d.root) {
+		id := nodeAttribute(x, "id")
+		y := newIDs[id]
+		if x.Type != html.ElementNode || oldIDs[id] != x || y == nil || !sameLabel(x, y) || d.partner[x] != nil || d.partner[y] != nil {
+			continue
+		}
+		if d.hashes[x] == d.hashes[y] {
+			d.matchSubtrees(x, y)
+		} else {
+			d.match(x, y)
+		}
+	}
+}
+
+// elementsByID returns the elements of the tree by their id. Ids which are not unique map to nil.
+func (d *differ) elementsByID(root *html.Node) map[string]*html.Node {
+	elements := make(map[string]*html.Node)
+	for _, n := range postOrder(root) {
+		if id := nodeAttribute(n, "id"); n.Type == html.ElementNode && id != "" {
+			if _, ok := elements[id]; ok {
+				elements[id] = nil
+			} else {
+				elements[id] = n
+			}
+		}
+	}
+	return elements
+}
+
+// matchByHash matches identical subtrees, starting with the largest. Single nodes are only matched
+// if their content is unique in both trees, so that common text like "\n" does not cause moves.
+func (d *differ) matchByHash(newRoot *html.Node) {
+	candidates := make(map[uint64][]*html.Node)
+	for _, n := range preOrder(d.root) {
+		if !d.isIgnored(n) {
+			candidates[d.hashes[n]] = append(candidates[d.hashes[n]], n)
+		}
+	}
+	counts := make(map[uint64]int)
+	nodes := make([]*html.Node, 0)
+	for _, n := range preOrder(newRoot) {
+		if !d.isIgnored(n) {
+			counts[d.hashes[n]]++
+			nodes = append(nodes, n)
+		}
+	}
+	sort.SliceStable(nodes, func(i, j int) bool { return d.sizes[nodes[i]] > d.sizes[nodes[j]] })
+	for _, y := range nodes {
+		hash := d.hashes[y]
+		if d.partner[y] != nil || (d.sizes[y] == 1 && (counts[hash] != 1 || len(candidates[hash]) != 1)) {
+			continue
+		}
+		var best *html.Node
+		for _, x := range candidates[hash] {
+			if d.partner[x] != nil || !sameLabel(x, y) {
+				continue
+			}
+			if best == nil {
+				best = x
+			}
+			// Prefer a candidate in the corresponding parent.
+			if x.Parent != nil && y.Parent != nil && d.partner[x.Parent] == y.Parent {
+				best = x
+				break
+			}
+		}
+		if best != nil {
+			d.matchSubtrees(best, y)
+		}
+	}
+}
+
+// matchBottomUp matches unmatched elements with the element of the same tag name which contains
+// most of the partners of their descendants, if the descendants are similar enough.
+func (d *differ) matchBottomUp() {
+	for _, x := range postOrder(d.root) {
+		if d.partner[x] != nil || d.isIgnored(x) || x.Type != html.ElementNode || d.sizes[x] < 2 {
+			continue
+		}
+		votes := make(map[*html.Node]int)
+		candidates := make([]*html.Node, 0)
+		for _, descendant := range preOrder(x)[1:] {
+			y := d.partner[descendant]
+			if y == nil {
+				continue
+			}
+			for ancestor := y.Parent; ancestor != nil; ancestor = ancestor.Parent {
+				if d.partner[ancestor] == nil && sameLabel(x, ancestor) {
+					if votes[ancestor] == 0 {
+						candidates = append(candidates, ancestor)
+					}
+					votes[ancestor]++
+				}
+			}
+		}
+		// Candidates with the same score are decided by document order.
+		sort.Slice(candidates, func(i, j int) bool { return d.order[candidates[i]] < d.order[candidates[j]] })
+		var best *html.Node
+		bestScore := 0.5
+		for _, y := range candidates {
+			common := votes[y]
+			score := 2 * float64(common) / float64(d.sizes[x]-1+d.sizes[y]-1)
+			if score > bestScore || (score == bestScore && best == nil) {
+				best, bestScore = y, score
+			}
+		}
+		if best != nil {
+			d.match(x, best)
+		}
+	}
+}
+
+// matchTopDown matches the unmatched children of matched nodes with the same label in the same order,
+// so that changed text and attributes become updates.
+func (d *differ) matchTopDown(newRoot *html.Node) {
+	for _, y := range preOrder(newRoot) {
+		x := d.partner[y]
+		if x == nil {
+			continue
+		}
+		xs, ys := d.unmatchedChildren(x), d.unmatchedChildren(y)
+		for _, pair := range commonSubsequence(xs, ys, sameLabel) {
+			d.match(pair[0], pair[1])
+		}
+	}
+}
+
+// unmatchedChildren returns the compared children of the node which are not matched.
+func (d *differ) unmatchedChildren(node *html.Node) []*html.Node {
+	children := make([]*html.Node, 0)
+	for _, child := range d.children(node) {
+		if d.partner[child] == nil {
+			children = append(children, child)
+		}
+	}
+	return children
+}
+
+// commonSubsequence returns the pairs of a longest common subsequence of xs and ys.
+// Long lists are paired greedily to bound the quadratic cost.
+func commonSubsequence(xs, ys []*html.Node, equal func(x, y *html.Node) bool) [][2]*html.Node {
+	pairs := make([][2]*html.Node, 0)
+	if len(xs)*len(ys) > 250000 {
+		j := 0
+		for _, x := range xs {
+			for k := j; k < len(ys); k++ {
+				if equal(x, ys[k]) {
+					pairs = append(pairs, [2]*html.Node{x, ys[k]})
+					j = k + 1
+					break
+				}
+			}
+		}
+		return pairs
+	}
+	lengths := make([][]int, len(xs)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(ys)+1)
+	}
+	for i := len(xs) - 1; i >= 0; i-- {
+		for j := len(ys) - 1; j >= 0; j-- {
+			if equal(xs[i], ys[j]) {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+	for i, j := 0, 0; i < len(xs) && j < len(ys); {
+		if equal(xs[i], ys[j]) {
+			pairs = append(pairs, [2]*html.Node{xs[i], ys[j]})
+			i++
+			j++
+		} else if lengths[i+1][j] >= lengths[i][j+1] {
+			i++
+		} else {
+			j++
+		}
+	}
+	return pairs
+}
+
+// generate computes the edit script from the matching. The new tree is visited in breadth-first
+// order, its nodes are inserted, updated and moved in the copy of the old tree, and finally the
+// unmatched nodes of the old tree are deleted.
+func (d *differ) generate(newRoot *html.Node) {
+	d.alignChildren(d.root, newRoot)
+	queue := d.children(newRoot)
+	for len(queue) > 0 {
+		y := queue[0]
+		queue = queue[1:]
+		parent := d.partner[y.Parent]
+		x := d.partner[y]
+		if x == nil {
+			x = d.insert(y, parent)
+		} else {
+			d.update(x, y)
+			if x.Parent != parent {
+				d.move(x, y, parent)
+			}
+		}
+		d.alignChildren(x, y)
+		queue = append(queue, d.children(y)...)
+	}
+	d.deleteUnmatched()
+}
+
+// insert inserts a copy of the new node y into parent. If none of the descendants of y is matched,
+// the whole subtree is inserted, otherwise the descendants are inserted or moved later.
+func (d *differ) insert(y, parent *html.Node) *html.Node {
+	whole := true
+	for _, descendant := range preOrder(y)[1:] {
+		if d.partner[descendant] != nil {
+			whole = false
+			break
+		}
+	}
+	var x *html.Node
+	if whole {
+		x = cloneDeep(y)
+		d.measure(x)
+		d.matchSubtrees(x, y)
+		for _, n := range preOrder(y) {
+			d.inOrder[n] = true
+			if d.partner[n] != nil {
+				d.inOrder[d.partner[n]] = true
+			}
+		}
+	} else {
+		x = cloneShallow(y)
+		d.match(x, y)
+	}
+	index := d.findPosition(y)
+	parent.InsertBefore(x, childAt(parent, index))
+	d.inOrder[x], d.inOrder[y] = true, true
+	d.edits = append(d.edits, Edit{Op: EditInsert, Path: nodePath(x), Location: nodeLocation(x), Node: toJSONNode(x, nil)})
+	return x
+}
+
+// update changes the text and the attributes of the old node x to the ones of the new node y.
+func (d *differ) update(x, y *html.Node) {
+	if x.Type != html.ElementNode && d.text(x) != d.text(y) {
+		old, updated := x.Data, y.Data
+		x.Data = y.Data
+		d.edits = append(d.edits, Edit{Op: EditUpdateText, Path: nodePath(x), Location: nodeLocation(x), Old: &old, New: &updated})
+	}
+	oldAttrs, newAttrs := d.attributes(x), d.attributes(y)
+	for j, attr := range newAttrs {
+		i := slices.IndexFunc(oldAttrs, func(old html.Attribute) bool { return old.Namespace == attr.Namespace && old.Key == attr.Key })
+		if i >= 0 && oldAttrs[i].Val == attr.Val {
+			continue
+		}
+		updated := attr.Val
+		edit := Edit{Op: EditUpdateAttribute, Path: nodePath(x), Location: nodeLocation(x), Namespace: attr.Namespace, Name: attr.Key, New: &updated}
+		if i >= 0 {
+			old := oldAttrs[i].Val
+			edit.Old = &old
+			setAttribute(x, attr)
+		} else {
+			// An added attribute follows the attribute before it in y, which is already an attribute of x.
+			if j > 0 {
+				previous := newAttrs[j-1]
+				edit.Index = slices.IndexFunc(x.Attr, func(a html.Attribute) bool { return a.Namespace == previous.Namespace && a.Key == previous.Key }) + 1
+			}
+			insertAttribute(x, attr, edit.Index)
+		}
+		d.edits = append(d.edits, edit)
+	}
+	for _, attr := range oldAttrs {
+		if !slices.ContainsFunc(newAttrs, func(updated html.Attribute) bool {
+			return updated.Namespace == attr.Namespace && updated.Key == attr.Key
+		}) {
+			old := attr.Val
+			d.edits = append(d.edits, Edit{Op: EditUpdateAttribute, Path: nodePath(x), Location: nodeLocation(x), Namespace: attr.Namespace, Name: attr.Key, Old: &old})
+			removeAttribute(x, attr.Namespace, attr.Key)
+		}
+	}
+	d.alignAttributes(x, newAttrs)
+}
+
+// alignAttributes moves the attributes of x which are in a different order than in newAttrs. An attribute
+// is moved by removing it and adding it after the attribute before it in newAttrs. The attributes
+// which keep their place are a longest increasing subsequence, like in alignChildren.
+func (d *differ) alignAttributes(x *html.Node, newAttrs []html.Attribute) {
+	sameName := func(a html.Attribute) func(html.Attribute) bool {
+		return func(b html.Attribute) bool { return a.Namespace == b.Namespace && a.Key == b.Key }
+	}
+	oldAttrs := d.attributes(x)
+	sequence := make([]int, len(oldAttrs))
+	for i, attr := range oldAttrs {
+		sequence[i] = slices.IndexFunc(newAttrs, sameName(attr))
+	}
+	inOrder := make(map[int]bool)
+	for _, i := range longestIncreasingSubsequence(sequence) {
+		inOrder[sequence[i]] = true
+	}
+	for j, attr := range newAttrs {
+		if inOrder[j] {
+			continue
+		}
+		old, updated := attr.Val, attr.Val
+		d.edits = append(d.edits, Edit{Op: EditUpdateAttribute, Path: nodePath(x), Location: nodeLocation(x), Namespace: attr.Namespace, Name: attr.Key, Old: &old})
+		removeAttribute(x, attr.Namespace, attr.Key)
+		edit := Edit{Op: EditUpdateAttribute, Path: nodePath(x), Location: nodeLocation(x), Namespace: attr.Namespace, Name: attr.Key, New: &updated}
+		if j > 0 {
+			edit.Index = slices.IndexFunc(x.Attr, sameName(newAttrs[j-1])) + 1
+		}
+		insertAttribute(x, attr, edit.Index)
+		d.edits = append(d.edits, edit)
+	}
+}
+
+// setAttribute sets the value of the attribute, adding it if necessary.
+func setAttribute(node *html.Node, attr html.Attribute) {
+	for i := range node.Attr {
+		if node.Attr[i].Namespace == attr.Namespace && node.Attr[i].Key == attr.Key {
+			node.Attr[i].Val = attr.Val
+			return
+		}
+	}
+	node.Attr = append(node.Attr, attr)
+}
+
+// insertAttribute inserts the attribute at the index, or appends it if the index is out of range.
+func insertAttribute(node *html.Node, attr html.Attribute, index int) {
+	if index < 0 || index > len(node.Attr) {
+		index = len(node.Attr)
+	}
+	node.Attr = slices.Insert(node.Attr, index, attr)
+}
+
+// removeAttribute removes the attribute from the node.
+func removeAttribute(node *html.Node, namespace, key string) {
+	node.Attr = slices.DeleteFunc(node.Attr, func(attr html.Attribute) bool { return attr.Namespace == namespace && attr.Key == key })
+}
+
+// move moves the old node x into parent at the position which corresponds to the position of y.
+func (d *differ) move(x, y, parent *html.Node) {
+	from, fromLocation := nodePath(x), nodeLocation(x)
+	hash := strictHash(x)
+	x.Parent.RemoveChild(x)
+	index := d.findPosition(y)
+	parent.InsertBefore(x, childAt(parent, index))
+	d.inOrder[x], d.inOrder[y] = true, true
+	d.edits = append(d.edits, Edit{Op: EditMove, Path: nodePath(x), Location: nodeLocation(x), From: from, FromLocation: fromLocation, Hash: hash})
+}
+
+// alignChildren moves the matched children of x which are in a different order than their partners among
+// the children of y. The children which keep their place are a longest increasing subsequence.
+func (d *differ) alignChildren(x, y *html.Node) {
+	xs := make([]*html.Node, 0)
+	for _, child := range d.children(x) {
+		if partner := d.partner[child]; partner != nil && partner.Parent == y {
+			xs = append(xs, child)
+		}
+	}
+	positions := make(map[*html.Node]int)
+	ys := make([]*html.Node, 0)
+	for _, child := range d.children(y) {
+		if partner := d.partner[child]; partner != nil && partner.Parent == x {
+			positions[child] = len(ys)
+			ys = append(ys, child)
+		}
+	}
+	sequence := make([]int, len(xs))
+	for i, child := range xs {
+		sequence[i] = positions[d.partner[child]]
+	}
+	for _, i := range longestIncreasingSubsequence(sequence) {
+		d.inOrder[xs[i]], d.inOrder[d.partner[xs[i]]] = true, true
+	}
+	for _, child := range ys {
+		if !d.inOrder[child] {
+			d.move(d.partner[child], child, x)
+		}
+	}
+}
+
+// longestIncreasingSubsequence returns the indices of a longest increasing subsequence of values.
+func longestIncreasingSubsequence(values []int) []int {
+	tails := make([]int, 0)
+	previous := make([]int, len(values))
+	for i, v := range values {
+		k := sort.Search(len(tails), func(k int) bool { return values[tails[k]] >= v })
+		if k > 0 {
+			previous[i] = tails[k-1]
+		} else {
+			previous[i] = -1
+		}
+		if k == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[k] = i
+		}
+	}
+	indices := make([]int, len(tails))
+	for k, i := len(tails)-1, -1; k >= 0; k-- {
+		if i == -1 {
+			i = tails[len(tails)-1]
+		} else {
+			i = previous[i]
+		}
+		indices[k] = i
+	}
+	return indices
+}
+
+// findPosition returns the index in the old tree at which the partner of the new node y belongs:
+// after the partner of the nearest sibling to the left of y which is in order.
+func (d *differ) findPosition(y *html.Node) int {
+	for sibling := y.PrevSibling; sibling != nil; sibling = sibling.PrevSibling {
+		if d.inOrder[sibling] && d.partner[sibling] != nil {
+			return nodeIndex(d.partner[sibling]) + 1
+		}
+	}
+	return 0
+}
+
+// deleteUnmatched deletes the subtrees of the old tree which are not matched.
+// Matched descendants have already been moved out of them.
+func (d *differ) deleteUnmatched() {
+	stack := []*html.Node{d.root}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if n != d.root && d.partner[n] == nil && !d.isIgnored(n) {
+			d.edits = append(d.edits, Edit{Op: EditDelete, Path: nodePath(n), Location: nodeLocation(n), Node: toJSONNode(n, nil), Hash: strictHash(n)})
+			n.Parent.RemoveChild(n)
+			continue
+		}
+		for child := n.LastChild; child != nil; child = child.PrevSibling {
+			stack = append(stack, child)
+		}
+	}
+}
+
+// strictHash returns the hex-encoded checksum of the complete subtree, used to detect conflicts when
+// an edit script is applied.
+func strictHash(root *html.Node) string {
+	d := &differ{ordered: true, hashes: make(map[*html.Node]uint64), sizes: make(map[*html.Node]int)}
+	d.measure(root)
+	return strconv.FormatUint(d.hashes[root], 16)
+}
+
+// nodePath returns the child indices from the root of the tree to the node.
+func nodePath(node *html.Node) []int {
+	path := make([]int, 0)
+	for ; node.Parent != nil; node = node.Parent {
+		path = append(path, nodeIndex(node))
+	}
+	slices.Reverse(path)
+	return path
+}
+
+// nodeLocation returns a readable path to the node, like "/html[1]/body[1]/text()[2]".
+// Steps count the siblings of the same kind, starting at 1.
+func nodeLocation(node *html.Node) string {
+	steps := make([]string, 0)
+	for ; node.Parent != nil; node = node.Parent {
+		position := 1
+		for sibling := node.PrevSibling; sibling != nil; sibling = sibling.PrevSibling {
+			if sameLabel(sibling, node) {
+				position++
+			}
+		}
+		steps = append(steps, locationStep(node)+"["+strconv.Itoa(position)+"]")
+	}
+	slices.Reverse(steps)
+	return "/" + strings.Join(steps, "/")
+}
+
+// locationStep returns the name of the node in a location.
+func locationStep(node *html.Node) string {
+	switch node.Type {
+	case html.ElementNode:
+		return node.Data
+	case html.TextNode:
+		return "text()"
+	case html.CommentNode:
+		return "comment()"
+	case html.DoctypeNode:
+		return "doctype()"
+	}
+	return "node()"
+}
+
+// String returns the human-readable report of the changes, one edit per line.
+func (s *EditScript) String() string {
+	var sb strings.Builder
+	for _, edit := range s.Edits {
+		sb.WriteString(edit.String() + "\n")
+	}
+	return sb.String()
+}
+
+// String returns a human-readable description of the edit.
+func (e Edit) String() string {
+	name := e.Name
+	if e.Namespace != "" {
+		name = e.Namespace + ":" + e.Name
+	}
+	switch e.Op {
+	case EditInsert:
+		return fmt.Sprintf("insert %s: %s", e.Location, snippet(e.Node))
+	case EditDelete:
+		return fmt.Sprintf("delete %s: %s", e.Location, snippet(e.Node))
+	case EditMove:
+		return fmt.Sprintf("move %s to %s", e.FromLocation, e.Location)
+	case EditUpdateText:
+		return fmt.Sprintf("update %s: %q -> %q", e.Location, deref(e.Old), deref(e.New))
+	case EditUpdateAttribute:
+		if e.Old == nil {
+			return fmt.Sprintf("add attribute %s to %s: %q", name, e.Location, deref(e.New))
+		}
+		if e.New == nil {
+			return fmt.Sprintf("remove attribute %s from %s: %q", name, e.Location, deref(e.Old))
+		}
+		return fmt.Sprintf("update attribute %s of %s: %q -> %q", name, e.Location, deref(e.Old), deref(e.New))
+	}
+	return string(e.Op) + " " + e.Location
+}
+
+// deref returns the string or "" for nil.
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// snippet returns the beginning of the HTML of the node for reports.
+func snippet(j *JSONNode) string {
+	if j == nil {
+		return ""
+	}
+	node, _, err := fromJSONNode(j)
+	if err != nil {
+		return ""
+	}
+	var sb strings.Builder
+	html.Render(&sb, node)
+	s := strings.Join(strings.Fields(sb.String()), " ")
+	if utf8.RuneCountInString(s) > 60 {
+		s = string([]rune(s)[:60]) + "…"
+	}
+	return s
+}
+
+// cloneDeep returns a copy of the node and its descendants.
+func cloneDeep(root *html.Node) *html.Node {
+	clone := cloneShallow(root)
+	stack := [][2]*html.Node{{root, clone}}
+	for len(stack) > 0 {
+		pair := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for child := pair[0].FirstChild; child != nil; child = child.NextSibling {
+			copied := cloneShallow(child)
+			pair[1].AppendChild(copied)
+			stack = append(stack, [2]*html.Node{child, copied})
+		}
+	}
+	return clone
+}
+
+// preOrder returns the node and its descendants in document order.
+func preOrder(root *html.Node) []*html.Node {
+	nodes := make([]*html.Node, 0)
+	stack := []*html.Node{root}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		nodes = append(nodes, n)
+		for child := n.LastChild; child != nil; child = child.PrevSibling {
+			stack = append(stack, child)
+		}
+	}
+	return nodes
+}
+
+// postOrder returns the node and its descendants with every node after its descendants.
+func postOrder(root *html.Node) []*html.Node {
+	// The reversed pre-order with children visited from last to first is a post-order.
+	reversed := make([]*html.Node, 0)
+	stack := []*html.Node{root}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		reversed = append(reversed, n)
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			stack = append(stack, child)
+		}
+	}
+	slices.Reverse(reversed)
+	return reversed
+}
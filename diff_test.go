@@ -0,0 +1,152 @@
+package goDOM_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/richi0/goDOM"
+)
+
+func diffStrings(t *testing.T, a, b string, opts goDOM.DiffOptions) string {
+	t.Helper()
+	script, err := goDOM.Diff(createDOMFromString(a), createDOMFromString(b), opts)
+	if err != nil {
+		t.Fatal("Unexpected diff error:", err)
+	}
+	return script.String()
+}
+
+func TestDiffIdentical(t *testing.T) {
+	script, err := goDOM.Diff(createTestDOM(), createTestDOM(), goDOM.DiffOptions{})
+	if err != nil {
+		t.Fatal("Unexpected diff error:", err)
+	}
+	if len(script.Edits) != 0 {
+		t.Errorf("Expected no edits but got\n%s", script)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name, a, b, expected string
+	}{
+		{
+			"update text",
+			`<p>Hello</p>`,
+			`<p>World</p>`,
+			`update /html[1]/body[1]/p[1]/text()[1]: "Hello" -> "World"`,
+		},
+		{
+			"update attributes",
+			`<a href="/a" title="t">x</a>`,
+			`<a href="/b" rel="next">x</a>`,
+			`update attribute href of /html[1]/body[1]/a[1]: "/a" -> "/b"
+add attribute rel to /html[1]/body[1]/a[1]: "next"
+remove attribute title from /html[1]/body[1]/a[1]: "t"`,
+		},
+		{
+			"insert",
+			`<ul><li>a</li><li>c</li></ul>`,
+			`<ul><li>a</li><li>b</li><li>c</li></ul>`,
+			`insert /html[1]/body[1]/ul[1]/li[2]: <li>b</li>`,
+		},
+		{
+			"delete",
+			`<ul><li>a</li><li>b</li><li>c</li></ul>`,
+			`<ul><li>a</li><li>c</li></ul>`,
+			`delete /html[1]/body[1]/ul[1]/li[2]: <li>b</li>`,
+		},
+		{
+			"move",
+			`<ul><li>a</li><li>b</li><li>c</li></ul>`,
+			`<ul><li>c</li><li>a</li><li>b</li></ul>`,
+			`move /html[1]/body[1]/ul[1]/li[3] to /html[1]/body[1]/ul[1]/li[1]`,
+		},
+		{
+			"move to another parent",
+			`<div id="x"><p>one</p><p>two</p></div><div id="y"></div>`,
+			`<div id="x"><p>one</p></div><div id="y"><p>two</p></div>`,
+			`move /html[1]/body[1]/div[1]/p[2] to /html[1]/body[1]/div[2]/p[1]`,
+		},
+		{
+			"match by id",
+			`<section id="s"><h2>Title</h2><p>Text</p></section><p>End</p>`,
+			`<p>End</p><section id="s" class="new"><h2>Title</h2><p>Text</p></section>`,
+			`move /html[1]/body[1]/section[1] to /html[1]/body[1]/section[1]
+add attribute class to /html[1]/body[1]/section[1]: "new"`,
+		},
+		{
+			"reorder attributes",
+			`<p id="x" class="a" title="t">x</p>`,
+			`<p id="x" title="t" class="a">x</p>`,
+			`remove attribute class from /html[1]/body[1]/p[1]: "a"
+add attribute class to /html[1]/body[1]/p[1]: "a"`,
+		},
+		{
+			"wrap",
+			`<p>a</p><p>b</p>`,
+			`<div><p>a</p><p>b</p></div>`,
+			`insert /html[1]/body[1]/div[1]: <div></div>
+move /html[1]/body[1]/p[1] to /html[1]/body[1]/div[1]/p[1]
+move /html[1]/body[1]/p[1] to /html[1]/body[1]/div[1]/p[2]`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := diffStrings(t, test.a, test.b, goDOM.DiffOptions{}); strings.TrimSpace(got) != test.expected {
+				t.Errorf("Expected\n%s\nbut got\n%s", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestDiffOptions(t *testing.T) {
+	a := `<div nonce="1">
+  <p>Hello   world</p><!-- a -->
+</div>`
+	b := `<div nonce="2"><p>Hello world</p><!-- b --></div>`
+	if got := diffStrings(t, a, b, goDOM.DiffOptions{}); got == "" {
+		t.Error("Expected differences without options")
+	}
+	opts := goDOM.DiffOptions{IgnoreWhitespace: true, IgnoreComments: true, IgnoreAttributes: []string{"NONCE"}}
+	if got := diffStrings(t, a, b, opts); got != "" {
+		t.Errorf("Expected no differences but got\n%s", got)
+	}
+}
+
+func TestDiffDeterministic(t *testing.T) {
+	a := `<body><section><i>alpha</i><i>beta</i></section></body>`
+	b := `<body><section><i>alpha</i><i>gamma</i></section><section><i>beta</i><i>delta</i></section></body>`
+	expected := diffStrings(t, a, b, goDOM.DiffOptions{})
+	for i := 0; i < 50; i++ {
+		if got := diffStrings(t, a, b, goDOM.DiffOptions{}); got != expected {
+			t.Fatalf("Expected the same edits on every run\n%s\nbut got\n%s", expected, got)
+		}
+	}
+}
+
+func TestDiffJSON(t *testing.T) {
+	script, err := goDOM.Diff(createDOMFromString(`<p class="a">x</p>`), createDOMFromString(`<p>x</p><hr>`), goDOM.DiffOptions{})
+	if err != nil {
+		t.Fatal("Unexpected diff error:", err)
+	}
+	data, err := json.Marshal(script)
+	if err != nil {
+		t.Fatal("Unexpected marshal error:", err)
+	}
+	expected := `{"edits":[` +
+		`{"op":"update-attribute","path":[0,1,0],"location":"/html[1]/body[1]/p[1]","name":"class","old":"a"},` +
+		`{"op":"insert","path":[0,1,1],"location":"/html[1]/body[1]/hr[1]","node":{"type":"element","tag":"hr"}}` +
+		`]}`
+	if string(data) != expected {
+		t.Errorf("Expected\n%s\nbut got\n%s", expected, data)
+	}
+}
+
+func TestDiffErrors(t *testing.T) {
+	dom := createDOMFromString(`<p>x</p>`)
+	if _, err := goDOM.Diff(dom, dom.GetElementsByTagName("p")[0], goDOM.DiffOptions{}); err == nil {
+		t.Error("Expected an error for roots of a different kind")
+	}
+}
@@ -0,0 +1,52 @@
+package goDOM
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// contentDocument is a document parsed from the srcdoc attribute of an iframe.
+type contentDocument struct {
+	srcdoc string
+	dom    *DOM
+}
+
+// ContentDocument returns the document embedded in the srcdoc attribute of an <iframe> element,
+// or nil if the element is not an iframe or has no srcdoc attribute.
+//
+// The document is parsed on the first call with the Limits, IncludeTemplateContent and DisableScripting
+// options of the parent document; an error is returned if it exceeds the Limits. Later calls return the
+// same DOM until the srcdoc attribute changes.
+// Documents loaded from the src attribute are not fetched.
+//
+// See Javascript equivalent: https://developer.mozilla.org/en-US/docs/Web/API/HTMLIFrameElement/contentDocument
+func (d *DOM) ContentDocument() (*DOM, error) {
+	xml := d.isXML()
+	if !d.isElementNode() || nodeLocalName(d.node, xml) != "iframe" || nodeNamespaceURI(d.node, xml) != NamespaceHTML {
+		return nil, nil
+	}
+	if !d.HasAttribute("srcdoc") {
+		return nil, nil
+	}
+	srcdoc := d.getAttribute("srcdoc")
+	opts := ParseOptions{Encoding: "utf-8"}
+	if d.doc == nil {
+		return NewWithOptions(strings.NewReader(srcdoc), opts)
+	}
+	if cached, ok := d.doc.contentDocuments[d.node]; ok && cached.srcdoc == srcdoc {
+		return cached.dom, nil
+	}
+	opts.Limits = d.doc.options.Limits
+	opts.IncludeTemplateContent = d.doc.options.IncludeTemplateContent
+	opts.DisableScripting = d.doc.options.DisableScripting
+	dom, err := NewWithOptions(strings.NewReader(srcdoc), opts)
+	if err != nil {
+		return nil, err
+	}
+	if d.doc.contentDocuments == nil {
+		d.doc.contentDocuments = make(map[*html.Node]contentDocument)
+	}
+	d.doc.contentDocuments[d.node] = contentDocument{srcdoc, dom}
+	return dom, nil
+}
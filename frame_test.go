@@ -0,0 +1,86 @@
+package goDOM_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/richi0/goDOM"
+)
+
+func contentDocument(t *testing.T, frame *goDOM.DOM) *goDOM.DOM {
+	t.Helper()
+	content, err := frame.ContentDocument()
+	if err != nil {
+		t.Fatal("Unexpected content document error:", err)
+	}
+	return content
+}
+
+func TestContentDocument(t *testing.T) {
+	dom := createDOMFromString(`<iframe id="f" srcdoc="<p id=&quot;inner&quot;>Hello &amp;amp; bye</p><iframe srcdoc='<b>deep</b>'></iframe>"></iframe>` +
+		`<iframe id="src" src="/page"></iframe><div id="d" srcdoc="<p>x</p>"></div>`)
+	frame := dom.GetElementById("f")
+	content := contentDocument(t, frame)
+	if content == nil {
+		t.Fatal("Expected a content document")
+	}
+	if content.TagName() != "document" || content.GetElementById("inner").Text(false) != "Hello & bye" {
+		t.Error("Expected the srcdoc to be parsed as a document")
+	}
+	if dom.GetElementById("inner") != nil {
+		t.Error("Expected the content document not to be part of the parent document")
+	}
+	if contentDocument(t, frame) != content {
+		t.Error("Expected the same content document on every call")
+	}
+	deep := contentDocument(t, content.GetElementsByTagName("iframe")[0])
+	if deep == nil || deep.GetElementsByTagName("b")[0].Text(false) != "deep" {
+		t.Error("Expected nested content documents")
+	}
+	frame.SetAttribute("srcdoc", "<i>changed</i>")
+	if changed := contentDocument(t, frame); changed == content || len(changed.GetElementsByTagName("i")) != 1 {
+		t.Error("Expected a new content document after srcdoc changed")
+	}
+	if contentDocument(t, dom.GetElementById("src")) != nil || contentDocument(t, dom.GetElementById("d")) != nil {
+		t.Error("Expected no content document without srcdoc or for other elements")
+	}
+}
+
+func TestContentDocumentLimits(t *testing.T) {
+	dom, err := goDOM.NewWithOptions(strings.NewReader(`<iframe srcdoc="<div><div><div><div><div></div></div></div></div></div>"></iframe>`),
+		goDOM.ParseOptions{Limits: goDOM.Limits{MaxDepth: 6}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := dom.GetElementsByTagName("iframe")[0].ContentDocument()
+	if content != nil || !errors.Is(err, goDOM.ErrTooDeep) {
+		t.Error("Expected the content document to be limited but got", err)
+	}
+}
+
+func TestDisableScripting(t *testing.T) {
+	input := `<head><noscript><link rel="stylesheet" href="a.css"></noscript></head><body><noscript><img src="a.png"><p>Enable JavaScript</p></noscript>` +
+		`<iframe srcdoc="<noscript><b>inner</b></noscript>"></iframe></body>`
+	dom := createDOMFromString(input)
+	if len(dom.GetElementsByTagName("img")) != 0 || len(dom.GetElementsByTagName("link")) != 0 {
+		t.Error("Expected noscript content to be text with scripting enabled")
+	}
+	for _, opts := range []goDOM.ParseOptions{{DisableScripting: true}, {DisableScripting: true, TrackPositions: true}} {
+		dom, err := goDOM.NewWithOptions(strings.NewReader(input), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(dom.GetElementsByTagName("img")) != 1 || len(dom.GetElementsByTagName("link")) != 1 || len(dom.GetElementsByTagName("p")) != 1 {
+			t.Error("Expected noscript content to be elements with scripting disabled")
+		}
+		content := contentDocument(t, dom.GetElementsByTagName("iframe")[0])
+		if len(content.GetElementsByTagName("b")) != 1 {
+			t.Error("Expected content documents to inherit the scripting option")
+		}
+	}
+	_, err := goDOM.NewWithOptions(strings.NewReader(input), goDOM.ParseOptions{DisableScripting: true, Limits: goDOM.Limits{MaxNodes: 5}})
+	if !errors.Is(err, goDOM.ErrTooManyNodes) {
+		t.Error("Expected limits to apply with scripting disabled")
+	}
+}
@@ -0,0 +1,994 @@
+package goDOM
+
+import (
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// FromMarkdown returns the HTML for the Markdown from the given Reader as a fragment.
+//
+// The Markdown is parsed according to the CommonMark specification with the table and strikethrough
+// extensions of GitHub Flavored Markdown. Raw HTML in the Markdown is kept. Like in the reference
+// implementation of CommonMark, the content of links and images is not checked for unsafe URLs.
+// This function is not part of the Javascript Document interface.
+//
+// See https://spec.commonmark.org and https://github.github.com/gfm/
+func FromMarkdown(r io.Reader) (*DOM, error) {
+	source, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	p := newMarkdownParser()
+	lines := lineEndingPattern.Split(strings.ReplaceAll(string(source), "\x00", "\uFFFD"), -1)
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	for _, line := range lines {
+		p.incorporateLine(line)
+	}
+	for p.tip != nil {
+		p.finalize(p.tip)
+	}
+	p.processInlines()
+	return fragmentFromHTML(p.render())
+}
+
+// fragmentFromHTML parses HTML in the context of a <body> element and returns the nodes as a fragment.
+func fragmentFromHTML(source string) (*DOM, error) {
+	context := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(strings.NewReader(source), context)
+	if err != nil {
+		return nil, err
+	}
+	fragment := &html.Node{Type: html.DocumentNode}
+	for _, node := range nodes {
+		fragment.AppendChild(node)
+	}
+	return &DOM{node: fragment, doc: &document{encoding: "utf-8"}, fragment: true}, nil
+}
+
+// mdKind is the kind of a node of a Markdown document.
+type mdKind int
+
+const (
+	mdDocument mdKind = iota
+	mdBlockQuote
+	mdList
+	mdItem
+	mdParagraph
+	mdHeading
+	mdThematicBreak
+	mdCodeBlock
+	mdHTMLBlock
+	mdTable
+	mdText
+	mdSoftBreak
+	mdLineBreak
+	mdCode
+	mdHTMLInline
+	mdEmph
+	mdStrong
+	mdDel
+	mdLink
+	mdImage
+)
+
+// mdNode is a block or inline node of a Markdown document.
+type mdNode struct {
+	kind                                      mdKind
+	parent, firstChild, lastChild, prev, next *mdNode
+	open, lastLineBlank, lastLineChecked      bool
+	startLine                                 int
+	// content is the text of leaf blocks and the literal of code, HTML and text nodes.
+	content string
+	// level is the level of headings.
+	level int
+	// fenced, fenceChar, fenceLength, fenceOffset and info describe code blocks.
+	fenced                   bool
+	fenceChar                byte
+	fenceLength, fenceOffset int
+	info                     string
+	// htmlType is the kind of the start condition of HTML blocks, from 1 to 7.
+	htmlType int
+	// list describes lists and list items.
+	list mdListData
+	// destination and title are set for links and images.
+	destination, title string
+	// aligns and rows hold the alignments of the columns and the cells of tables. The first row is the header.
+	aligns []string
+	rows   [][]string
+}
+
+// mdListData describes a list or a list item.
+type mdListData struct {
+	ordered               bool
+	bulletChar, delimiter byte
+	start                 int
+	padding, markerOffset int
+	tight                 bool
+}
+
+// appendChild appends child to the children of n.
+func (n *mdNode) appendChild(child *mdNode) {
+	child.unlink()
+	child.parent = n
+	if n.lastChild != nil {
+		n.lastChild.next = child
+		child.prev = n.lastChild
+	} else {
+		n.firstChild = child
+	}
+	n.lastChild = child
+}
+
+// insertAfter inserts sibling after n.
+func (n *mdNode) insertAfter(sibling *mdNode) {
+	sibling.unlink()
+	sibling.next = n.next
+	if sibling.next != nil {
+		sibling.next.prev = sibling
+	}
+	sibling.prev = n
+	n.next = sibling
+	sibling.parent = n.parent
+	if sibling.next == nil && sibling.parent != nil {
+		sibling.parent.lastChild = sibling
+	}
+}
+
+// unlink removes n from its parent.
+func (n *mdNode) unlink() {
+	if n.prev != nil {
+		n.prev.next = n.next
+	} else if n.parent != nil {
+		n.parent.firstChild = n.next
+	}
+	if n.next != nil {
+		n.next.prev = n.prev
+	} else if n.parent != nil {
+		n.parent.lastChild = n.prev
+	}
+	n.parent, n.next, n.prev = nil, nil, nil
+}
+
+// canContain returns a boolean value indicating whether a block of the kind can contain a child of the kind.
+func (k mdKind) canContain(child mdKind) bool {
+	switch k {
+	case mdDocument, mdBlockQuote, mdItem:
+		return child != mdItem
+	case mdList:
+		return child == mdItem
+	}
+	return false
+}
+
+// acceptsLines returns a boolean value indicating whether a block of the kind takes the remaining lines.
+func (k mdKind) acceptsLines() bool {
+	return k == mdParagraph || k == mdCodeBlock || k == mdHTMLBlock || k == mdTable
+}
+
+// codeIndent is the indentation of indented code blocks.
+const codeIndent = 4
+
+var (
+	lineEndingPattern     = regexp.MustCompile(`\r\n|\n|\r`)
+	thematicBreakPattern  = regexp.MustCompile(`^(?:\*[ \t]*){3,}$|^(?:_[ \t]*){3,}$|^(?:-[ \t]*){3,}$`)
+	maybeSpecialPattern   = regexp.MustCompile("^[#`~*+_=<>0-9|:-]")
+	orderedMarkerPattern  = regexp.MustCompile(`^(\d{1,9})([.)])`)
+	atxHeadingPattern     = regexp.MustCompile(`^#{1,6}(?:[ \t]+|$)`)
+	setextHeadingPattern  = regexp.MustCompile(`^(?:=+|-+)[ \t]*$`)
+	atxClosingPattern     = regexp.MustCompile(`(?:^[ \t]*#+[ \t]*$)|(?:[ \t]+#+[ \t]*$)`)
+	tableDelimiterPattern = regexp.MustCompile(`^:?-+:?$`)
+	htmlBlockOpenPatterns = []*regexp.Regexp{
+		nil,
+		regexp.MustCompile(`(?i)^<(?:pre|script|style|textarea)(?:\s|>|$)`),
+		regexp.MustCompile(`^<!--`),
+		regexp.MustCompile(`^<[?]`),
+		regexp.MustCompile(`^<![A-Za-z]`),
+		regexp.MustCompile(`^<!\[CDATA\[`),
+		regexp.MustCompile(`(?i)^</?(?:address|article|aside|base|basefont|blockquote|body|caption|center|col|colgroup|dd|details|dialog|dir|div|dl|dt|fieldset|figcaption|figure|footer|form|frame|frameset|h[123456]|head|header|hr|html|iframe|legend|li|link|main|menu|menuitem|nav|noframes|ol|optgroup|option|p|param|search|section|summary|table|tbody|td|tfoot|th|thead|title|tr|track|ul)(?:\s|/?>|$)`),
+		regexp.MustCompile(`(?i)^(?:` + openTagPattern + `|` + closeTagPattern + `)\s*$`),
+	}
+	htmlBlockClosePatterns = []*regexp.Regexp{
+		nil,
+		regexp.MustCompile(`(?i)</(?:pre|script|style|textarea)>`),
+		regexp.MustCompile(`-->`),
+		regexp.MustCompile(`\?>`),
+		regexp.MustCompile(`>`),
+		regexp.MustCompile(`\]\]>`),
+	}
+)
+
+// markdownParser holds the state of the block parser of FromMarkdown.
+// It follows the parsing strategy of the CommonMark specification, see https://spec.commonmark.org/0.31.2/#appendix-a-parsing-strategy.
+type markdownParser struct {
+	doc, tip, oldTip, lastMatchedContainer *mdNode
+	inline                                 *mdInlineParser
+	line                                   string
+	lineNumber                             int
+	offset, column                         int
+	nextNonspace, nextNonspaceColumn       int
+	indent                                 int
+	indented, blank                        bool
+	partiallyConsumedTab, allClosed        bool
+}
+
+// newMarkdownParser returns a parser for a new document.
+func newMarkdownParser() *markdownParser {
+	doc := &mdNode{kind: mdDocument, open: true}
+	return &markdownParser{doc: doc, tip: doc, oldTip: doc, inline: &mdInlineParser{references: make(map[string]mdReference)}}
+}
+
+// peek returns the byte at pos of the line or -1 at the end of the line.
+func peek(s string, pos int) int {
+	if pos < len(s) {
+		return int(s[pos])
+	}
+	return -1
+}
+
+// isSpaceOrTab returns a boolean value indicating whether c is a space or a tab.
+func isSpaceOrTab(c int) bool {
+	return c == ' ' || c == '\t'
+}
+
+// advanceOffset advances the offset by count bytes, or by count columns if columns is set.
+func (p *markdownParser) advanceOffset(count int, columns bool) {
+	for count > 0 && p.offset < len(p.line) {
+		if p.line[p.offset] == '\t' {
+			charsToTab := 4 - p.column%4
+			if columns {
+				p.partiallyConsumedTab = charsToTab > count
+				advance := min(charsToTab, count)
+				p.column += advance
+				if !p.partiallyConsumedTab {
+					p.offset++
+				}
+				count -= advance
+			} else {
+				p.partiallyConsumedTab = false
+				p.column += charsToTab
+				p.offset++
+				count--
+			}
+		} else {
+			p.partiallyConsumedTab = false
+			p.offset++
+			p.column++
+			count--
+		}
+	}
+}
+
+// advanceNextNonspace advances the offset to the next character which is not a space or a tab.
+func (p *markdownParser) advanceNextNonspace() {
+	p.offset = p.nextNonspace
+	p.column = p.nextNonspaceColumn
+	p.partiallyConsumedTab = false
+}
+
+// findNextNonspace finds the next character which is not a space or a tab and computes the indentation.
+func (p *markdownParser) findNextNonspace() {
+	i, columns := p.offset, p.column
+	for i < len(p.line) {
+		if p.line[i] == ' ' {
+			i++
+			columns++
+		} else if p.line[i] == '\t' {
+			i++
+			columns += 4 - columns%4
+		} else {
+			break
+		}
+	}
+	p.blank = i == len(p.line)
+	p.nextNonspace = i
+	p.nextNonspaceColumn = columns
+	p.indent = columns - p.column
+	p.indented = p.indent >= codeIndent
+}
+
+// addLine adds the rest of the line to the open leaf block.
+func (p *markdownParser) addLine() {
+	if p.partiallyConsumedTab {
+		p.offset++
+		p.tip.content += strings.Repeat(" ", 4-p.column%4)
+	}
+	if p.tip.kind == mdTable {
+		// The delimiter row which started the table is not a row of the table.
+		if p.tip.startLine == p.lineNumber {
+			return
+		}
+		p.tip.rows = append(p.tip.rows, splitTableRow(p.line[p.offset:]))
+		return
+	}
+	p.tip.content += p.line[p.offset:] + "\n"
+}
+
+// addChild adds a block of the kind to the tip, closing blocks which cannot contain it.
+func (p *markdownParser) addChild(kind mdKind) *mdNode {
+	for !p.tip.kind.canContain(kind) {
+		p.finalize(p.tip)
+	}
+	block := &mdNode{kind: kind, open: true, startLine: p.lineNumber}
+	p.tip.appendChild(block)
+	p.tip = block
+	return block
+}
+
+// closeUnmatchedBlocks finalizes the blocks which were not matched by the current line.
+func (p *markdownParser) closeUnmatchedBlocks() {
+	if p.allClosed {
+		return
+	}
+	for p.oldTip != p.lastMatchedContainer {
+		parent := p.oldTip.parent
+		p.finalize(p.oldTip)
+		p.oldTip = parent
+	}
+	p.allClosed = true
+}
+
+// continueBlock tries to match the start of the line for an open block. It returns 0 if the block
+// matched, 1 if it did not match and 2 if the line closed a fenced code block.
+func (p *markdownParser) continueBlock(container *mdNode) int {
+	switch container.kind {
+	case mdBlockQuote:
+		if p.indented || peek(p.line, p.nextNonspace) != '>' {
+			return 1
+		}
+		p.advanceNextNonspace()
+		p.advanceOffset(1, false)
+		if isSpaceOrTab(peek(p.line, p.offset)) {
+			p.advanceOffset(1, true)
+		}
+	case mdItem:
+		if p.blank {
+			if container.firstChild == nil {
+				return 1
+			}
+			p.advanceNextNonspace()
+		} else if p.indent >= container.list.markerOffset+container.list.padding {
+			p.advanceOffset(container.list.markerOffset+container.list.padding, true)
+		} else {
+			return 1
+		}
+	case mdHeading, mdThematicBreak:
+		return 1
+	case mdCodeBlock:
+		if container.fenced {
+			if p.indent <= 3 && peek(p.line, p.nextNonspace) == int(container.fenceChar) {
+				if n := closingFence(p.line[p.nextNonspace:]); n >= container.fenceLength {
+					p.finalize(container)
+					return 2
+				}
+			}
+			for i := container.fenceOffset; i > 0 && isSpaceOrTab(peek(p.line, p.offset)); i-- {
+				p.advanceOffset(1, true)
+			}
+		} else if p.indent >= codeIndent {
+			p.advanceOffset(codeIndent, true)
+		} else if p.blank {
+			p.advanceNextNonspace()
+		} else {
+			return 1
+		}
+	case mdHTMLBlock:
+		if p.blank && (container.htmlType == 6 || container.htmlType == 7) {
+			return 1
+		}
+	case mdParagraph:
+		if p.blank {
+			return 1
+		}
+	case mdTable:
+		// A table ends at a blank line or at the start of another block.
+		rest := p.line[p.nextNonspace:]
+		if p.blank || (!p.indented && (strings.HasPrefix(rest, ">") || atxHeadingPattern.MatchString(rest) ||
+			openingFence(rest) > 0 || thematicBreakPattern.MatchString(rest))) {
+			return 1
+		}
+	}
+	return 0
+}
+
+// finalize closes the block and makes its parent the tip.
+func (p *markdownParser) finalize(block *mdNode) {
+	above := block.parent
+	block.open = false
+	switch block.kind {
+	case mdList:
+		block.list.tight = true
+		for item := block.firstChild; item != nil && block.list.tight; item = item.next {
+			if endsWithBlankLine(item) && item.next != nil {
+				block.list.tight = false
+				break
+			}
+			for child := item.firstChild; child != nil; child = child.next {
+				if endsWithBlankLine(child) && (item.next != nil || child.next != nil) {
+					block.list.tight = false
+					break
+				}
+			}
+		}
+	case mdCodeBlock:
+		if block.fenced {
+			info, rest, _ := strings.Cut(block.content, "\n")
+			block.info = unescapeMarkdown(strings.TrimSpace(info))
+			block.content = rest
+		} else {
+			lines := strings.Split(block.content, "\n")
+			for len(lines) > 0 && strings.Trim(lines[len(lines)-1], " \t") == "" {
+				lines = lines[:len(lines)-1]
+			}
+			block.content = strings.Join(lines, "\n") + "\n"
+		}
+	case mdHTMLBlock:
+		block.content = strings.TrimSuffix(block.content, "\n")
+	case mdParagraph:
+		hasReferences := false
+		for strings.HasPrefix(block.content, "[") {
+			n := p.inline.parseReference(block.content)
+			if n == 0 {
+				break
+			}
+			block.content = block.content[n:]
+			hasReferences = true
+		}
+		if hasReferences && strings.TrimSpace(block.content) == "" {
+			block.unlink()
+		}
+	}
+	p.tip = above
+}
+
+// endsWithBlankLine returns a boolean value indicating whether the block ends with a blank line,
+// descending into the last items of lists.
+func endsWithBlankLine(block *mdNode) bool {
+	for block != nil {
+		if block.lastLineBlank {
+			return true
+		}
+		if !block.lastLineChecked && (block.kind == mdList || block.kind == mdItem) {
+			block.lastLineChecked = true
+			block = block.lastChild
+		} else {
+			block.lastLineChecked = true
+			break
+		}
+	}
+	return false
+}
+
+// incorporateLine adds a line of the source to the document.
+func (p *markdownParser) incorporateLine(line string) {
+	container := p.doc
+	p.oldTip = p.tip
+	p.offset, p.column, p.blank, p.partiallyConsumedTab = 0, 0, false, false
+	p.lineNumber++
+	p.line = line
+	allMatched := true
+	for container.lastChild != nil && container.lastChild.open {
+		container = container.lastChild
+		p.findNextNonspace()
+		switch p.continueBlock(container) {
+		case 1:
+			allMatched = false
+		case 2:
+			return
+		}
+		if !allMatched {
+			container = container.parent
+			break
+		}
+	}
+	p.allClosed = container == p.oldTip
+	p.lastMatchedContainer = container
+	matchedLeaf := container.kind != mdParagraph && container.kind.acceptsLines()
+	for !matchedLeaf {
+		p.findNextNonspace()
+		if !p.indented && !maybeSpecialPattern.MatchString(line[p.nextNonspace:]) {
+			p.advanceNextNonspace()
+			break
+		}
+		result := p.startBlock(container)
+		if result == 0 {
+			p.advanceNextNonspace()
+			break
+		}
+		container = p.tip
+		matchedLeaf = result == 2
+	}
+	if !p.allClosed && !p.blank && p.tip.kind == mdParagraph {
+		// The line is a lazy continuation of the paragraph.
+		p.addLine()
+		return
+	}
+	p.closeUnmatchedBlocks()
+	if p.blank && container.lastChild != nil {
+		container.lastChild.lastLineBlank = true
+	}
+	// Blank lines in block quotes and fenced code and after the start of an empty list item do not make lists loose.
+	lastLineBlank := p.blank && !(container.kind == mdBlockQuote || (container.kind == mdCodeBlock && container.fenced) ||
+		(container.kind == mdItem && container.firstChild == nil && container.startLine == p.lineNumber))
+	for block := container; block != nil; block = block.parent {
+		block.lastLineBlank = lastLineBlank
+	}
+	if container.kind.acceptsLines() {
+		p.addLine()
+		if container.kind == mdHTMLBlock && container.htmlType >= 1 && container.htmlType <= 5 &&
+			htmlBlockClosePatterns[container.htmlType].MatchString(line[p.offset:]) {
+			p.finalize(container)
+		}
+	} else if p.offset < len(line) && !p.blank {
+		p.addChild(mdParagraph)
+		p.advanceNextNonspace()
+		p.addLine()
+	}
+}
+
+// startBlock tries to start a new block at the offset. It returns 0 if no block starts, 1 if a container
+// block starts and 2 if a leaf block starts.
+func (p *markdownParser) startBlock(container *mdNode) int {
+	rest := p.line[p.nextNonspace:]
+	if !p.indented {
+		switch {
+		case peek(p.line, p.nextNonspace) == '>':
+			p.advanceNextNonspace()
+			p.advanceOffset(1, false)
+			if isSpaceOrTab(peek(p.line, p.offset)) {
+				p.advanceOffset(1, true)
+			}
+			p.closeUnmatchedBlocks()
+			p.addChild(mdBlockQuote)
+			return 1
+		case atxHeadingPattern.MatchString(rest):
+			marker := atxHeadingPattern.FindString(rest)
+			p.advanceNextNonspace()
+			p.advanceOffset(len(marker), false)
+			p.closeUnmatchedBlocks()
+			heading := p.addChild(mdHeading)
+			heading.level = len(strings.TrimRight(marker, " \t"))
+			heading.content = atxClosingPattern.ReplaceAllString(p.line[p.offset:], "")
+			p.advanceOffset(len(p.line)-p.offset, false)
+			return 2
+		case openingFence(rest) > 0:
+			length := openingFence(rest)
+			p.closeUnmatchedBlocks()
+			code := p.addChild(mdCodeBlock)
+			code.fenced, code.fenceLength, code.fenceChar, code.fenceOffset = true, length, rest[0], p.indent
+			p.advanceNextNonspace()
+			p.advanceOffset(length, false)
+			return 2
+		}
+		if strings.HasPrefix(rest, "<") {
+			for htmlType := 1; htmlType <= 7; htmlType++ {
+				if htmlBlockOpenPatterns[htmlType].MatchString(rest) &&
+					(htmlType < 7 || (container.kind != mdParagraph && !(!p.allClosed && !p.blank && p.tip.kind == mdParagraph))) {
+					p.closeUnmatchedBlocks()
+					block := p.addChild(mdHTMLBlock)
+					block.htmlType = htmlType
+					return 2
+				}
+			}
+		}
+		if container.kind == mdParagraph {
+			if p.startTable(container) {
+				return 2
+			}
+			if setextHeadingPattern.MatchString(rest) && p.startSetextHeading(container, rest[0]) {
+				return 2
+			}
+		}
+		if thematicBreakPattern.MatchString(rest) {
+			p.closeUnmatchedBlocks()
+			p.addChild(mdThematicBreak)
+			p.advanceOffset(len(p.line)-p.offset, false)
+			return 2
+		}
+	}
+	if !p.indented || container.kind == mdList {
+		if data, ok := p.parseListMarker(container); ok {
+			p.closeUnmatchedBlocks()
+			if p.tip.kind != mdList || !listsMatch(container.list, data) {
+				list := p.addChild(mdList)
+				list.list = data
+			}
+			item := p.addChild(mdItem)
+			item.list = data
+			return 1
+		}
+	}
+	if p.indented && p.tip.kind != mdParagraph && !p.blank {
+		p.advanceOffset(codeIndent, true)
+		p.closeUnmatchedBlocks()
+		p.addChild(mdCodeBlock)
+		return 2
+	}
+	return 0
+}
+
+// startSetextHeading turns the paragraph into a heading underlined by the current line.
+func (p *markdownParser) startSetextHeading(paragraph *mdNode, underline byte) bool {
+	p.closeUnmatchedBlocks()
+	for strings.HasPrefix(paragraph.content, "[") {
+		n := p.inline.parseReference(paragraph.content)
+		if n == 0 {
+			break
+		}
+		paragraph.content = paragraph.content[n:]
+	}
+	if paragraph.content == "" {
+		return false
+	}
+	heading := &mdNode{kind: mdHeading, open: true, startLine: paragraph.startLine, level: 2, content: paragraph.content}
+	if underline == '=' {
+		heading.level = 1
+	}
+	paragraph.insertAfter(heading)
+	paragraph.unlink()
+	p.tip = heading
+	p.advanceOffset(len(p.line)-p.offset, false)
+	return true
+}
+
+// startTable turns the last line of the paragraph into the header row of a table if the current line
+// is a matching delimiter row.
+//
+// See https://github.github.com/gfm/#tables-extension-
+func (p *markdownParser) startTable(paragraph *mdNode) bool {
+	rest := p.line[p.nextNonspace:]
+	if !strings.Contains(rest, "|") {
+		return false
+	}
+	delimiters := splitTableRow(rest)
+	aligns := make([]string, len(delimiters))
+	for i, delimiter := range delimiters {
+		if !tableDelimiterPattern.MatchString(delimiter) {
+			return false
+		}
+		switch {
+		case strings.HasPrefix(delimiter, ":") && strings.HasSuffix(delimiter, ":"):
+			aligns[i] = "center"
+		case strings.HasPrefix(delimiter, ":"):
+			aligns[i] = "left"
+		case strings.HasSuffix(delimiter, ":"):
+			aligns[i] = "right"
+		}
+	}
+	lines := strings.Split(strings.TrimSuffix(paragraph.content, "\n"), "\n")
+	header := splitTableRow(lines[len(lines)-1])
+	if len(header) != len(aligns) {
+		return false
+	}
+	p.closeUnmatchedBlocks()
+	table := &mdNode{kind: mdTable, open: true, startLine: p.lineNumber, aligns: aligns, rows: [][]string{header}}
+	paragraph.insertAfter(table)
+	if len(lines) > 1 {
+		paragraph.content = strings.Join(lines[:len(lines)-1], "\n") + "\n"
+		p.finalize(paragraph)
+	} else {
+		paragraph.unlink()
+	}
+	p.tip = table
+	p.advanceOffset(len(p.line)-p.offset, false)
+	return true
+}
+
+// splitTableRow returns the trimmed cells of a table row. Escaped pipes are replaced by pipes.
+func splitTableRow(row string) []string {
+	row = strings.TrimSpace(row)
+	row = strings.TrimPrefix(row, "|")
+	if strings.HasSuffix(row, "|") && !strings.HasSuffix(row, `\|`) {
+		row = row[:len(row)-1]
+	}
+	cells := make([]string, 0)
+	start := 0
+	for i := 0; i <= len(row); i++ {
+		if i < len(row) && row[i] == '\\' {
+			i++
+			continue
+		}
+		if i == len(row) || row[i] == '|' {
+			cells = append(cells, strings.ReplaceAll(strings.TrimSpace(row[start:min(i, len(row))]), `\|`, "|"))
+			start = i + 1
+		}
+	}
+	return cells
+}
+
+// openingFence returns the length of the code fence at the start of s, or 0 if s does not start a fenced code block.
+func openingFence(s string) int {
+	if s == "" || (s[0] != '`' && s[0] != '~') {
+		return 0
+	}
+	n := 0
+	for n < len(s) && s[n] == s[0] {
+		n++
+	}
+	if n < 3 || (s[0] == '`' && strings.Contains(s[n:], "`")) {
+		return 0
+	}
+	return n
+}
+
+// closingFence returns the length of the code fence if s only consists of a code fence followed by spaces.
+func closingFence(s string) int {
+	n := 0
+	for n < len(s) && s[n] == s[0] {
+		n++
+	}
+	if n < 3 || strings.Trim(s[n:], " \t") != "" {
+		return 0
+	}
+	return n
+}
+
+// parseListMarker parses a list marker at the offset and advances the offset to the content of the item.
+func (p *markdownParser) parseListMarker(container *mdNode) (mdListData, bool) {
+	rest := p.line[p.nextNonspace:]
+	data := mdListData{tight: true, markerOffset: p.indent}
+	if p.indent >= codeIndent {
+		return data, false
+	}
+	var marker string
+	if rest != "" && (rest[0] == '*' || rest[0] == '+' || rest[0] == '-') {
+		marker, data.bulletChar = rest[:1], rest[0]
+	} else if match := orderedMarkerPattern.FindStringSubmatch(rest); match != nil && (container.kind != mdParagraph || match[1] == "1") {
+		marker, data.ordered, data.delimiter = match[0], true, match[2][0]
+		data.start, _ = strconv.Atoi(match[1])
+	} else {
+		return data, false
+	}
+	if next := peek(p.line, p.nextNonspace+len(marker)); next != -1 && !isSpaceOrTab(next) {
+		return data, false
+	}
+	// An empty list item cannot interrupt a paragraph.
+	if container.kind == mdParagraph && strings.Trim(p.line[p.nextNonspace+len(marker):], " \t") == "" {
+		return data, false
+	}
+	p.advanceNextNonspace()
+	p.advanceOffset(len(marker), true)
+	spacesStartColumn, spacesStartOffset := p.column, p.offset
+	for {
+		p.advanceOffset(1, true)
+		if p.column-spacesStartColumn >= 5 || !isSpaceOrTab(peek(p.line, p.offset)) {
+			break
+		}
+	}
+	blankItem := peek(p.line, p.offset) == -1
+	spacesAfterMarker := p.column - spacesStartColumn
+	if spacesAfterMarker >= 5 || spacesAfterMarker < 1 || blankItem {
+		data.padding = len(marker) + 1
+		p.column, p.offset = spacesStartColumn, spacesStartOffset
+		if isSpaceOrTab(peek(p.line, p.offset)) {
+			p.advanceOffset(1, true)
+		}
+	} else {
+		data.padding = len(marker) + spacesAfterMarker
+	}
+	return data, true
+}
+
+// listsMatch returns a boolean value indicating whether an item belongs to the list.
+func listsMatch(list, item mdListData) bool {
+	return list.ordered == item.ordered && list.delimiter == item.delimiter && list.bulletChar == item.bulletChar
+}
+
+// processInlines parses the content of paragraphs and headings into inline nodes.
+func (p *markdownParser) processInlines() {
+	stack := []*mdNode{p.doc}
+	for len(stack) > 0 {
+		block := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if block.kind == mdParagraph || block.kind == mdHeading {
+			p.inline.parse(block)
+			continue
+		}
+		for child := block.firstChild; child != nil; child = child.next {
+			stack = append(stack, child)
+		}
+	}
+}
+
+// render returns the HTML of the document.
+func (p *markdownParser) render() string {
+	var sb strings.Builder
+	type entry struct {
+		node *mdNode
+		exit bool
+	}
+	stack := make([]entry, 0)
+	for child := p.doc.lastChild; child != nil; child = child.prev {
+		stack = append(stack, entry{node: child})
+	}
+	for len(stack) > 0 {
+		e := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		n := e.node
+		if e.exit {
+			sb.WriteString(closingTag(n))
+			continue
+		}
+		switch n.kind {
+		case mdText:
+			sb.WriteString(html.EscapeString(n.content))
+			continue
+		case mdSoftBreak:
+			sb.WriteString("\n")
+			continue
+		case mdLineBreak:
+			sb.WriteString("<br>\n")
+			continue
+		case mdCode:
+			sb.WriteString("<code>" + html.EscapeString(n.content) + "</code>")
+			continue
+		case mdHTMLInline, mdHTMLBlock:
+			sb.WriteString(n.content)
+			continue
+		case mdThematicBreak:
+			sb.WriteString("<hr>")
+			continue
+		case mdCodeBlock:
+			sb.WriteString("<pre><code")
+			if language, _, _ := strings.Cut(n.info, " "); language != "" {
+				sb.WriteString(` class="language-` + html.EscapeString(language) + `"`)
+			}
+			sb.WriteString(">" + html.EscapeString(n.content) + "</code></pre>")
+			continue
+		case mdImage:
+			sb.WriteString(`<img src="` + html.EscapeString(n.destination) + `" alt="` + html.EscapeString(plainText(n)) + `"`)
+			if n.title != "" {
+				sb.WriteString(` title="` + html.EscapeString(n.title) + `"`)
+			}
+			sb.WriteString(">")
+			continue
+		case mdTable:
+			p.renderTable(&sb, n)
+			continue
+		case mdParagraph:
+			if item := n.parent; item != nil && item.kind == mdItem && item.parent != nil && item.parent.list.tight {
+				for child := n.lastChild; child != nil; child = child.prev {
+					stack = append(stack, entry{node: child})
+				}
+				continue
+			}
+		}
+		sb.WriteString(openingTag(n))
+		stack = append(stack, entry{node: n, exit: true})
+		for child := n.lastChild; child != nil; child = child.prev {
+			stack = append(stack, entry{node: child})
+		}
+	}
+	return sb.String()
+}
+
+// renderTable writes a table. The inline content of the cells is parsed first.
+func (p *markdownParser) renderTable(sb *strings.Builder, table *mdNode) {
+	sb.WriteString("<table>")
+	for i, row := range table.rows {
+		if i == 0 {
+			sb.WriteString("<thead>")
+		} else if i == 1 {
+			sb.WriteString("<tbody>")
+		}
+		sb.WriteString("<tr>")
+		tag := "td"
+		if i == 0 {
+			tag = "th"
+		}
+		for j, align := range table.aligns {
+			sb.WriteString("<" + tag)
+			if align != "" {
+				sb.WriteString(` align="` + align + `"`)
+			}
+			sb.WriteString(">")
+			if j < len(row) {
+				cell := &mdNode{kind: mdParagraph, content: row[j]}
+				p.inline.parse(cell)
+				cellParser := &markdownParser{doc: cell}
+				sb.WriteString(cellParser.render())
+			}
+			sb.WriteString("</" + tag + ">")
+		}
+		sb.WriteString("</tr>")
+		if i == 0 {
+			sb.WriteString("</thead>")
+		}
+	}
+	if len(table.rows) > 1 {
+		sb.WriteString("</tbody>")
+	}
+	sb.WriteString("</table>")
+}
+
+// openingTag returns the start tag of a block or inline container.
+func openingTag(n *mdNode) string {
+	switch n.kind {
+	case mdBlockQuote:
+		return "<blockquote>"
+	case mdList:
+		if !n.list.ordered {
+			return "<ul>"
+		}
+		if n.list.start != 1 {
+			return `<ol start="` + strconv.Itoa(n.list.start) + `">`
+		}
+		return "<ol>"
+	case mdItem:
+		return "<li>"
+	case mdParagraph:
+		return "<p>"
+	case mdHeading:
+		return "<h" + strconv.Itoa(n.level) + ">"
+	case mdEmph:
+		return "<em>"
+	case mdStrong:
+		return "<strong>"
+	case mdDel:
+		return "<del>"
+	case mdLink:
+		tag := `<a href="` + html.EscapeString(n.destination) + `"`
+		if n.title != "" {
+			tag += ` title="` + html.EscapeString(n.title) + `"`
+		}
+		return tag + ">"
+	}
+	return ""
+}
+
+// closingTag returns the end tag of a block or inline container.
+func closingTag(n *mdNode) string {
+	switch n.kind {
+	case mdBlockQuote:
+		return "</blockquote>"
+	case mdList:
+		if n.list.ordered {
+			return "</ol>"
+		}
+		return "</ul>"
+	case mdItem:
+		return "</li>"
+	case mdParagraph:
+		return "</p>"
+	case mdHeading:
+		return "</h" + strconv.Itoa(n.level) + ">"
+	case mdEmph:
+		return "</em>"
+	case mdStrong:
+		return "</strong>"
+	case mdDel:
+		return "</del>"
+	case mdLink:
+		return "</a>"
+	}
+	return ""
+}
+
+// plainText returns the text of the inline content of the node, used as alternative text of images.
+func plainText(n *mdNode) string {
+	var sb strings.Builder
+	stack := make([]*mdNode, 0)
+	for child := n.lastChild; child != nil; child = child.prev {
+		stack = append(stack, child)
+	}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		switch node.kind {
+		case mdText, mdCode:
+			sb.WriteString(node.content)
+		case mdSoftBreak, mdLineBreak:
+			sb.WriteString("\n")
+		}
+		for child := node.lastChild; child != nil; child = child.prev {
+			stack = append(stack, child)
+		}
+	}
+	return sb.String()
+}
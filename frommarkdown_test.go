@@ -0,0 +1,104 @@
+package goDOM_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/richi0/goDOM"
+)
+
+func fromMarkdown(t *testing.T, markdown string) string {
+	t.Helper()
+	dom, err := goDOM.FromMarkdown(strings.NewReader(markdown))
+	if err != nil {
+		t.Fatal("Unexpected markdown error:", err)
+	}
+	rendered, err := dom.Render()
+	if err != nil {
+		t.Fatal("Unexpected render error:", err)
+	}
+	return rendered
+}
+
+func TestFromMarkdown(t *testing.T) {
+	tests := []struct {
+		markdown string
+		expected string
+	}{
+		{"# Title\n\nSetext\n---", "<h1>Title</h1><h2>Setext</h2>"},
+		{"*foo**bar**baz* _a_b_ ~~del~~", "<p><em>foo<strong>bar</strong>baz</em> <em>a_b</em> <del>del</del></p>"},
+		{"**foo* `` a ` b `` \\*x\\*", "<p>*<em>foo</em> <code>a ` b</code> *x*</p>"},
+		{"[link](</my uri> \"title\") ![foo *bar*](a.png) <https://a.b>", `<p><a href="/my%20uri" title="title">link</a> <img src="a.png" alt="foo bar"/> <a href="https://a.b">https://a.b</a></p>`},
+		{"[Foo bar]:\n<my url>\n'title'\n\n[foo BAR] [missing]", `<p><a href="my%20url" title="title">foo BAR</a> [missing]</p>`},
+		{"- a\n- b\n\n- c", "<ul><li><p>a</p></li><li><p>b</p></li><li><p>c</p></li></ul>"},
+		{"10) foo\n    - bar\n\n\tcode", `<ol start="10"><li><p>foo</p><ul><li>bar</li></ul><p>code</p></li></ol>`},
+		{"\tcode\n\n    more", "<pre><code>code\n\nmore\n</code></pre>"},
+		{"> foo\nbar\n\n```go\n<x>\n```", "<blockquote><p>foo\nbar</p></blockquote><pre><code class=\"language-go\">&lt;x&gt;\n</code></pre>"},
+		{"foo  \nbar\\\nbaz\n***", "<p>foo<br/>\nbar<br/>\nbaz</p><hr/>"},
+		{"<div>\n*raw*\n</div>\n\n<span>*b*</span> &copy;", "<div>\n*raw*\n</div><p><span><em>b</em></span> ©</p>"},
+		{"text\n| a | b |\n|:--|--:|\n| 1 | 2 \\| x |\n\nafter", `<p>text</p><table><thead><tr><th align="left">a</th><th align="right">b</th></tr></thead><tbody><tr><td align="left">1</td><td align="right">2 | x</td></tr></tbody></table><p>after</p>`},
+	}
+	for _, test := range tests {
+		if rendered := fromMarkdown(t, test.markdown); rendered != test.expected {
+			t.Errorf("%q: expected\n%s\nbut got\n%s", test.markdown, test.expected, rendered)
+		}
+	}
+}
+
+func TestFromMarkdownFragment(t *testing.T) {
+	dom, err := goDOM.FromMarkdown(strings.NewReader("a\n\nb"))
+	if err != nil {
+		t.Fatal("Unexpected markdown error:", err)
+	}
+	if paragraphs := dom.GetElementsByTagName("p"); len(paragraphs) != 2 {
+		t.Errorf("Expected two paragraphs but got %d", len(paragraphs))
+	}
+	if len(dom.GetElementsByTagName("body")) != 0 {
+		t.Error("Expected a fragment without a body")
+	}
+	if dom.TagName() != "fragment" {
+		t.Errorf("Expected a fragment but got %q", dom.TagName())
+	}
+	var snapshot bytes.Buffer
+	if err := dom.WriteSnapshot(&snapshot); err != nil {
+		t.Fatal("Unexpected snapshot error:", err)
+	}
+	if read, err := goDOM.ReadSnapshot(&snapshot); err != nil || read.TagName() != "fragment" {
+		t.Error("Expected the snapshot to keep the fragment but got", err)
+	}
+	if data, _ := json.Marshal(dom); !bytes.Contains(data, []byte(`"type":"fragment"`)) {
+		t.Error("Expected a fragment in the JSON representation but got", string(data))
+	}
+}
+
+func TestFromMarkdownRoundTrip(t *testing.T) {
+	files, err := filepath.Glob("test_data/markdown/*.md")
+	if err != nil || len(files) == 0 {
+		t.Fatal("Expected golden files in test_data/markdown")
+	}
+	for _, file := range files {
+		source, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dom, err := goDOM.FromMarkdown(strings.NewReader(string(source)))
+		if err != nil {
+			t.Fatal("Unexpected markdown error:", err)
+		}
+		markdown, err := dom.Markdown(goDOM.MarkdownOptions{})
+		if err != nil {
+			t.Fatal("Unexpected markdown error:", err)
+		}
+		dom, err = goDOM.FromMarkdown(strings.NewReader(markdown))
+		if err != nil {
+			t.Fatal("Unexpected markdown error:", err)
+		}
+		if again, _ := dom.Markdown(goDOM.MarkdownOptions{}); again != markdown {
+			t.Errorf("%s: expected converting back and forth to be stable but got\n%s\nand\n%s", file, markdown, again)
+		}
+	}
+}
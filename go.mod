@@ -2,4 +2,7 @@ module github.com/richi0/goDOM
 
 go 1.21.1
 
-require golang.org/x/net v0.24.0
+require (
+	golang.org/x/net v0.24.0
+	golang.org/x/text v0.14.0
+)
@@ -6,12 +6,14 @@ package goDOM
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"slices"
 	"strings"
 
 	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
 )
 
 // New returns the parsed tree for the HTML from the given Reader as a DOM object.
@@ -20,11 +22,11 @@ func New(r io.Reader) (*DOM, error) {
 	if err != nil {
 		return nil, err
 	}
-	return newDOM(node), nil
+	return newDOM(node, &document{encoding: "utf-8"}), nil
 }
 
-func newDOM(node *html.Node) *DOM {
-	return &DOM{node, nil, nil}
+func newDOM(node *html.Node, doc *document) *DOM {
+	return &DOM{node: node, doc: doc}
 }
 
 // A DOM represents a parsed HTML document.
@@ -33,6 +35,10 @@ type DOM struct {
 	node            *html.Node
 	flatElementList []*DOM
 	flatNodeList    []*DOM
+	cacheGeneration uint64
+	doc             *document
+	// fragment is set if the DOM represents the content of the template element node.
+	fragment bool
 }
 
 // TagName returns a string representation of the nodes tag.
@@ -41,6 +47,9 @@ func (d *DOM) TagName() string {
 	if nodeType == html.TextNode {
 		return "text"
 	}
+	if d.fragment {
+		return "fragment"
+	}
 	if nodeType == html.DocumentNode {
 		return "document"
 	}
@@ -57,16 +66,16 @@ func (d *DOM) TagName() string {
 // See Javascript equivalent: https://developer.mozilla.org/en-US/docs/Web/API/Document/firstElementChild
 func (d *DOM) FirstElementChild() *DOM {
 	if d.node == nil || d.node.FirstChild == nil {
-		return newDOM(nil)
+		return newDOM(nil, d.doc)
 	}
 	node := d.node.FirstChild
 	for node != nil {
 		if node.Type == html.ElementNode {
-			return newDOM(node)
+			return newDOM(node, d.doc)
 		}
 		node = node.NextSibling
 	}
-	return newDOM(nil)
+	return newDOM(nil, d.doc)
 }
 
 // LastElementChild returns the document's last child Element, or nil if there are no child elements.
@@ -76,16 +85,16 @@ func (d *DOM) FirstElementChild() *DOM {
 // See Javascript equivalent: https://developer.mozilla.org/en-US/docs/Web/API/Document/lastElementChild
 func (d *DOM) LastElementChild() *DOM {
 	if d.node == nil || d.node.LastChild == nil {
-		return newDOM(nil)
+		return newDOM(nil, d.doc)
 	}
 	node := d.node.LastChild
 	for node != nil {
 		if node.Type == html.ElementNode {
-			return newDOM(node)
+			return newDOM(node, d.doc)
 		}
 		node = node.PrevSibling
 	}
-	return newDOM(nil)
+	return newDOM(nil, d.doc)
 }
 
 // NextElementSibling returns the element immediately following the specified one in its parent's children list,
@@ -96,16 +105,16 @@ func (d *DOM) LastElementChild() *DOM {
 // See Javascript equivalent: https://developer.mozilla.org/en-US/docs/Web/API/Element/nextElementSibling
 func (d *DOM) NextElementSibling() *DOM {
 	if d.node == nil {
-		return newDOM(nil)
+		return newDOM(nil, d.doc)
 	}
 	node := d.node.NextSibling
 	for node != nil {
 		if node.Type == html.ElementNode {
-			return newDOM(node)
+			return newDOM(node, d.doc)
 		}
 		node = node.NextSibling
 	}
-	return newDOM(nil)
+	return newDOM(nil, d.doc)
 }
 
 // PreviousElementSibling returns the element immediately prior the specified one in its parent's children list,
@@ -116,16 +125,78 @@ func (d *DOM) NextElementSibling() *DOM {
 // See Javascript equivalent: https://developer.mozilla.org/en-US/docs/Web/API/Element/previousElementSibling
 func (d *DOM) PreviousElementSibling() *DOM {
 	if d.node == nil {
-		return newDOM(nil)
+		return newDOM(nil, d.doc)
 	}
 	node := d.node.PrevSibling
 	for node != nil {
 		if node.Type == html.ElementNode {
-			return newDOM(node)
+			return newDOM(node, d.doc)
 		}
 		node = node.PrevSibling
 	}
-	return newDOM(nil)
+	return newDOM(nil, d.doc)
+}
+
+// FirstChild returns the node's first child in the tree, or nil if the node has no children.
+//
+// Unlike FirstElementChild, FirstChild includes all node types like text or comment nodes.
+//
+// See Javascript equivalent: https://developer.mozilla.org/en-US/docs/Web/API/Node/firstChild
+func (d *DOM) FirstChild() *DOM {
+	if d.node == nil {
+		return newDOM(nil, d.doc)
+	}
+	return newDOM(d.node.FirstChild, d.doc)
+}
+
+// LastChild returns the node's last child in the tree, or nil if the node has no children.
+//
+// Unlike LastElementChild, LastChild includes all node types like text or comment nodes.
+//
+// See Javascript equivalent: https://developer.mozilla.org/en-US/docs/Web/API/Node/lastChild
+func (d *DOM) LastChild() *DOM {
+	if d.node == nil {
+		return newDOM(nil, d.doc)
+	}
+	return newDOM(d.node.LastChild, d.doc)
+}
+
+// NextSibling returns the node immediately following the specified one in its parent's child list,
+// or nil if the specified node is the last child.
+//
+// Unlike NextElementSibling, NextSibling includes all node types like text or comment nodes.
+//
+// See Javascript equivalent: https://developer.mozilla.org/en-US/docs/Web/API/Node/nextSibling
+func (d *DOM) NextSibling() *DOM {
+	if d.node == nil {
+		return newDOM(nil, d.doc)
+	}
+	return newDOM(d.node.NextSibling, d.doc)
+}
+
+// PreviousSibling returns the node immediately preceding the specified one in its parent's child list,
+// or nil if the specified node is the first child.
+//
+// Unlike PreviousElementSibling, PreviousSibling includes all node types like text or comment nodes.
+//
+// See Javascript equivalent: https://developer.mozilla.org/en-US/docs/Web/API/Node/previousSibling
+func (d *DOM) PreviousSibling() *DOM {
+	if d.node == nil {
+		return newDOM(nil, d.doc)
+	}
+	return newDOM(d.node.PrevSibling, d.doc)
+}
+
+// ChildNodes returns a slice which contains all child nodes of the element upon which it was called.
+//
+// Unlike Children, ChildNodes includes all node types like text or comment nodes.
+//
+// See Javascript equivalent: https://developer.mozilla.org/en-US/docs/Web/API/Node/childNodes
+func (d *DOM) ChildNodes() []*DOM {
+	if d.node == nil {
+		return make([]*DOM, 0)
+	}
+	return d.childNodes()
 }
 
 // Children returns a slice which contains all of the child elements of the element upon which it was called.
@@ -157,7 +228,7 @@ func (d *DOM) ChildElementCount() int {
 func (d *DOM) Attributes() map[string]string {
 	var attr = make(map[string]string)
 	for _, a := range d.node.Attr {
-		attr[a.Key] = a.Val
+		attr[attributeName(a)] = a.Val
 	}
 	return attr
 }
@@ -207,13 +278,40 @@ func (d *DOM) HasAttributes() bool {
 //
 // See Javascript equivalent: https://developer.mozilla.org/en-US/docs/Web/API/Element/setAttribute
 func (d *DOM) SetAttribute(key, value string) {
-	d.node.Attr = append(d.node.Attr, html.Attribute{Key: key, Val: value})
+	setNodeAttribute(d.node, key, value)
+}
+
+// RemoveAttribute removes the attribute with the specified name from the element.
+//
+// See Javascript equivalent: https://developer.mozilla.org/en-US/docs/Web/API/Element/removeAttribute
+func (d *DOM) RemoveAttribute(key string) {
+	removeNodeAttribute(d.node, key)
+}
+
+// CreateElement creates a new element with the given tag name.
+// The element is not part of the document until it is inserted.
+//
+// See Javascript equivalent: https://developer.mozilla.org/en-US/docs/Web/API/Document/createElement
+func (d *DOM) CreateElement(tag string) *DOM {
+	if d.doc != nil && d.doc.xml {
+		return newDOM(&html.Node{Type: html.ElementNode, Data: tag}, d.doc)
+	}
+	tag = strings.ToLower(tag)
+	return newDOM(&html.Node{Type: html.ElementNode, DataAtom: atom.Lookup([]byte(tag)), Data: tag}, d.doc)
 }
 
 // Render returns a string representation of the DOM.
 // This method is not part of the Javascript Document interface.
 func (d *DOM) Render() (string, error) {
 	var buffer bytes.Buffer
+	if d.fragment {
+		for child := d.node.FirstChild; child != nil; child = child.NextSibling {
+			if err := html.Render(&buffer, child); err != nil {
+				return "", err
+			}
+		}
+		return buffer.String(), nil
+	}
 	err := html.Render(&buffer, d.node)
 	if err != nil {
 		return "", err
@@ -271,70 +369,92 @@ func (d *DOM) GetElementsByClassName(class string) []*DOM {
 //
 // See Javascript equivalent: https://developer.mozilla.org/en-US/docs/Web/API/Node/textContent
 func (d *DOM) Text(full bool) string {
+	text, _ := d.TextContext(context.Background(), full)
+	return text
+}
+
+// TextContext is like Text but returns ctx.Err() if the context is done before the text was collected.
+// This method is not part of the Javascript Document interface.
+func (d *DOM) TextContext(ctx context.Context, full bool) (string, error) {
 	var sb strings.Builder
 	nodes := make([]*DOM, 0)
 	if !full {
 		nodes = d.getTextNodes()
 	} else {
-		for _, node := range d.getFlatNodeList(true) {
+		flatNodeList, err := d.getFlatNodeListContext(ctx, true)
+		if err != nil {
+			return "", err
+		}
+		for _, node := range flatNodeList {
 			if node.TagName() == "text" {
 				nodes = append(nodes, node)
 			}
 		}
 	}
-	for _, node := range nodes {
+	for i, node := range nodes {
+		if err := checkContext(ctx, i); err != nil {
+			return "", err
+		}
 		sb.WriteString(fmt.Sprintf("%s ", node.node.Data))
 	}
-	return strings.TrimSpace(sb.String())
+	return strings.TrimSpace(sb.String()), nil
 }
 
 // Parent returns the parent of the specified node in the DOM tree.
 //
 // See Javascript equivalent: https://developer.mozilla.org/en-US/docs/Web/API/Node/parentNode
 func (d *DOM) Parent() *DOM {
-	return newDOM(d.node.Parent)
+	return newDOM(d.node.Parent, d.doc)
 }
 
 const (
 	MatchTypeExact    = iota
 	MatchTypeContains = iota
+	MatchTypePrefix   = iota
+	MatchTypeSuffix   = iota
 )
 
 // GetElemenstByTextContent returns a slice of elements with the given text content.
-// The matchType parameter can be set to MatchTypeExact (0) or MatchTypeContains (1).
+// The matchType parameter can be set to MatchTypeExact (0), MatchTypeContains (1),
+// MatchTypePrefix (2) or MatchTypeSuffix (3).
 // If matchType is set to MatchTypeExact, the text content of the element must match the given text exactly.
 // If matchType is set to MatchTypeContains, the text content of the element must contain the given text.
+// If matchType is set to MatchTypePrefix or MatchTypeSuffix, the text content of the element must start
+// or end with the given text.
 // The default matchType is MatchTypeExact.
+// Use GetElementsByTextMatch for case-insensitive, normalized or regular expression matching.
 // This method is not part of the Javascript Document interface.
 func (d *DOM) GetElementsByTextContent(text string, matchType int) []*DOM {
-	elements := make([]*DOM, 0)
-	nodes := d.getFlatElementList(true)
-	for _, node := range nodes {
-		if matchType == MatchTypeContains {
-			if strings.Contains(node.Text(false), text) {
-				elements = append(elements, node)
-			}
-		} else if node.Text(false) == text {
-			elements = append(elements, node)
-		}
-	}
-	return elements
+	return d.GetElementsByTextMatch(text, TextMatchOptions{MatchType: matchType})
 }
 
 // RemoveStyleAttributes removes all attributes that can be used to style an element.
 // This method is not part of the Javascript Document interface.
 func (d *DOM) RemoveStyleAttributes() {
-	nodes := d.getFlatElementList(true)
-	cleanAttributes := make([]html.Attribute, 0)
-	for _, node := range nodes {
-		attributes := node.node.Attr
-		for _, key := range attributes {
+	d.RemoveStyleAttributesContext(context.Background())
+}
+
+// RemoveStyleAttributesContext is like RemoveStyleAttributes but returns ctx.Err() if the context is done
+// before all elements were cleaned. Elements which were already cleaned keep their reduced attributes.
+// This method is not part of the Javascript Document interface.
+func (d *DOM) RemoveStyleAttributesContext(ctx context.Context) error {
+	nodes, err := d.getFlatElementListContext(ctx, true)
+	if err != nil {
+		return err
+	}
+	for i, node := range nodes {
+		if err := checkContext(ctx, i); err != nil {
+			return err
+		}
+		cleanAttributes := make([]html.Attribute, 0)
+		for _, key := range node.node.Attr {
 			if slices.Contains(acceptedAttributes, key.Key) {
 				cleanAttributes = append(cleanAttributes, key)
 			}
 		}
 		node.node.Attr = cleanAttributes
 	}
+	return nil
 }
 
 // getTextNodes returns all text node children of the given node.
@@ -354,11 +474,21 @@ func (d *DOM) getTextNodes() []*DOM {
 
 // getFlatElementList returns all element nodes in the DOM.
 func (d *DOM) getFlatElementList(setCache bool) []*DOM {
+	elements, _ := d.getFlatElementListContext(context.Background(), setCache)
+	return elements
+}
+
+// getFlatElementListContext returns all element nodes in the DOM or ctx.Err() if the context is done.
+func (d *DOM) getFlatElementListContext(ctx context.Context, setCache bool) ([]*DOM, error) {
+	d.checkCache()
 	if d.flatElementList != nil {
-		return d.flatElementList
+		return d.flatElementList, nil
 	}
 	elements := make([]*DOM, 0)
-	nodes := d.getFlatNodeList(true)
+	nodes, err := d.getFlatNodeListContext(ctx, true)
+	if err != nil {
+		return nil, err
+	}
 	for _, node := range nodes {
 		if node.isElementNode() {
 			elements = append(elements, node)
@@ -367,26 +497,62 @@ func (d *DOM) getFlatElementList(setCache bool) []*DOM {
 	if setCache {
 		d.flatElementList = elements
 	}
-	return elements
+	return elements, nil
 }
 
 // getFlatNodeList returns all nodes in the DOM.
 func (d *DOM) getFlatNodeList(setCache bool) []*DOM {
+	nodes, _ := d.getFlatNodeListContext(context.Background(), setCache)
+	return nodes
+}
+
+// getFlatNodeListContext returns all nodes in the DOM or ctx.Err() if the context is done.
+func (d *DOM) getFlatNodeListContext(ctx context.Context, setCache bool) ([]*DOM, error) {
+	d.checkCache()
 	if d.flatNodeList != nil {
-		return d.flatNodeList
+		return d.flatNodeList, nil
 	}
 	flatNodeList := make([]*DOM, 0)
+	if d.node == nil {
+		return flatNodeList, nil
+	}
 	flatNodeList = append(flatNodeList, d)
-	if d.node.FirstChild != nil {
-		children := d.childNodes()
-		for _, child := range children {
-			flatNodeList = append(flatNodeList, child.getFlatNodeList(false)...)
+	// The tree is walked with an explicit stack, so that deeply nested documents cannot exhaust the call stack.
+	stack := make([]*html.Node, 0)
+	for child := d.node.LastChild; child != nil && !d.isInert(d.node); child = child.PrevSibling {
+		stack = append(stack, child)
+	}
+	for len(stack) > 0 {
+		if err := checkContext(ctx, len(flatNodeList)); err != nil {
+			return nil, err
+		}
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		flatNodeList = append(flatNodeList, newDOM(node, d.doc))
+		if d.isInert(node) {
+			continue
+		}
+		for child := node.LastChild; child != nil; child = child.PrevSibling {
+			stack = append(stack, child)
 		}
 	}
 	if setCache {
 		d.flatNodeList = flatNodeList
 	}
-	return flatNodeList
+	return flatNodeList, nil
+}
+
+// checkCache drops the cached flat lists if the tree was mutated since they were built.
+func (d *DOM) checkCache() {
+	var current uint64
+	if d.doc != nil {
+		current = d.doc.generation
+	}
+	if d.cacheGeneration != current {
+		d.flatElementList = nil
+		d.flatNodeList = nil
+		d.cacheGeneration = current
+	}
 }
 
 // childNodes returns all child nodes of a given node.
@@ -396,11 +562,11 @@ func (d *DOM) childNodes() []*DOM {
 	if child == nil {
 		return children
 	}
-	children = append(children, newDOM(child))
+	children = append(children, newDOM(child, d.doc))
 	for child.NextSibling != nil {
 		next := child.NextSibling
 		if next != nil {
-			children = append(children, newDOM(next))
+			children = append(children, newDOM(next, d.doc))
 		}
 		child = next
 	}
@@ -419,7 +585,7 @@ func (d *DOM) getAttribute(key string) string {
 
 // isElementNode returns a Boolean value indicating whether the specified node is an element node or not.
 func (d *DOM) isElementNode() bool {
-	if d.node == nil {
+	if d.node == nil || d.fragment {
 		return false
 	}
 	return d.node.Type == html.ElementNode
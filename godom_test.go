@@ -20,6 +20,14 @@ func createTestDOM() *goDOM.DOM {
 	return dom
 }
 
+func createDOMFromString(s string) *goDOM.DOM {
+	dom, err := goDOM.New(strings.NewReader(s))
+	if err != nil {
+		panic("Cannot create test dom object")
+	}
+	return dom
+}
+
 func TestAttributes(t *testing.T) {
 	dom := createTestDOM()
 	if len(dom.Attributes()) != 0 {
@@ -170,6 +178,20 @@ func TestHasAttributes(t *testing.T) {
 	}
 }
 
+func TestSetAttribute(t *testing.T) {
+	dom := createDOMFromString(`<p id="a" title="old">x</p>`)
+	p := dom.GetElementById("a")
+	p.SetAttribute("title", "new")
+	p.SetAttribute("lang", "en")
+	if len(p.Attributes()) != 3 || p.Attributes()["title"] != "new" || p.Attributes()["lang"] != "en" {
+		t.Error("Expected title to be updated and lang to be added", p.Attributes())
+	}
+	p.RemoveAttribute("title")
+	if p.HasAttribute("title") || len(p.Attributes()) != 2 {
+		t.Error("Expected title to be removed", p.Attributes())
+	}
+}
+
 func TestId(t *testing.T) {
 	dom := createTestDOM()
 	id := dom.Id()
@@ -0,0 +1,91 @@
+package goDOM
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// HighlightText wraps every match of pattern in the inner text of the node in a new element
+// with the given tag and attributes and returns the created elements in document order.
+//
+// Matches which span element boundaries are split, so that every text node part is wrapped separately
+// and the existing markup stays intact. Text which is not rendered, like the content of script and
+// style elements, is not searched, and text which cannot contain elements, like the content of textarea
+// and title elements, is not wrapped. Attribute values are never modified. If pattern is nil, nothing is
+// wrapped and nil is returned.
+// This method is not part of the Javascript Document interface.
+func (d *DOM) HighlightText(pattern *regexp.Regexp, wrapperTag string, attrs map[string]string) []*DOM {
+	if pattern == nil {
+		return nil
+	}
+	wrappers := make([]*DOM, 0)
+	parts := make([]textSegment, 0)
+	for _, match := range d.findTextMatches(pattern) {
+		nodes := make([]*html.Node, 0)
+		for _, segment := range match.segments {
+			if !slices.Contains(nodes, segment.node) {
+				nodes = append(nodes, segment.node)
+			}
+		}
+		for i, node := range nodes {
+			// The content of elements like textarea is text, a wrapper would change it.
+			if isTextContent(node) {
+				continue
+			}
+			from, to := 0, len(node.Data)
+			if i == 0 {
+				from = match.startOffset
+			}
+			if i == len(nodes)-1 {
+				to = match.endOffset
+			}
+			parts = append(parts, textSegment{node: node, offset: from, length: to - from})
+		}
+	}
+	keys := make([]string, 0, len(attrs))
+	for key := range attrs {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	// Parts are wrapped in reverse order, so that splitting a text node
+	// does not change the offsets of the parts before it.
+	for i := len(parts) - 1; i >= 0; i-- {
+		part := parts[i]
+		tag := strings.ToLower(wrapperTag)
+		wrapper := &html.Node{Type: html.ElementNode, DataAtom: atom.Lookup([]byte(tag)), Data: tag}
+		for _, key := range keys {
+			wrapper.Attr = append(wrapper.Attr, html.Attribute{Key: key, Val: attrs[key]})
+		}
+		wrapText(part.node, part.offset, part.offset+part.length, wrapper)
+		wrappers = append(wrappers, newDOM(wrapper, d.doc))
+	}
+	slices.Reverse(wrappers)
+	d.doc.invalidateCaches()
+	return wrappers
+}
+
+// wrapText moves the text between the offsets of the text node into wrapper and inserts wrapper in its place.
+func wrapText(text *html.Node, from, to int, wrapper *html.Node) {
+	if to < len(text.Data) {
+		splitAt(text, to)
+	}
+	if from > 0 {
+		_, text = splitAt(text, from)
+	}
+	parent := text.Parent
+	parent.InsertBefore(wrapper, text)
+	parent.RemoveChild(text)
+	wrapper.AppendChild(text)
+}
+
+// isTextContent returns a boolean value indicating whether the text node is the content of an element
+// whose content is parsed as text, like <textarea> or <script>.
+func isTextContent(node *html.Node) bool {
+	parent := node.Parent
+	return parent != nil && parent.Namespace == "" &&
+		(slices.Contains(rawTextElements, parent.Data) || slices.Contains(escapableRawTextElements, parent.Data))
+}
@@ -0,0 +1,68 @@
+package goDOM_test
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestHighlightText(t *testing.T) {
+	dom := createDOMFromString(`<div id="d" title="buy now">Buy <b>n</b>ow, please buy now!<script>var buy = "now";</script></div>`)
+	marks := dom.HighlightText(regexp.MustCompile(`(?i)buy now`), "mark", map[string]string{"class": "hit", "data-term": "buy now"})
+	if len(marks) != 4 {
+		t.Fatal("Expected 4 wrapper elements but found", len(marks))
+	}
+	html, _ := dom.GetElementById("d").Render()
+	expected := `<div id="d" title="buy now">` +
+		`<mark class="hit" data-term="buy now">Buy </mark><b><mark class="hit" data-term="buy now">n</mark></b><mark class="hit" data-term="buy now">ow</mark>` +
+		`, please <mark class="hit" data-term="buy now">buy now</mark>!<script>var buy = "now";</script></div>`
+	if html != expected {
+		t.Errorf("Expected highlighted html to be\n%s\nbut got\n%s", expected, html)
+	}
+	if marks[0].Text(false) != "Buy" || marks[3].Text(false) != "buy now" {
+		t.Error("Expected wrappers to be in document order")
+	}
+	if len(dom.GetElementsByTagName("mark")) != 4 {
+		t.Error("Expected 4 mark elements in the document")
+	}
+	if dom.HighlightText(nil, "mark", nil) != nil || len(dom.GetElementsByTagName("mark")) != 4 {
+		t.Error("Expected nothing to be wrapped without a pattern")
+	}
+}
+
+func TestHighlightTextContent(t *testing.T) {
+	dom := createDOMFromString(`<div id="d"><textarea>buy now</textarea><p>buy now</p><xmp>buy now</xmp></div>`)
+	marks := dom.HighlightText(regexp.MustCompile(`buy now`), "MARK", nil)
+	if len(marks) != 1 {
+		t.Fatal("Expected 1 wrapper element but found", len(marks))
+	}
+	html, _ := dom.GetElementById("d").Render()
+	expected := `<div id="d"><textarea>buy now</textarea><p><mark>buy now</mark></p><xmp>buy now</xmp></div>`
+	if html != expected {
+		t.Errorf("Expected highlighted html to be\n%s\nbut got\n%s", expected, html)
+	}
+	if found := dom.GetElementsByTagName("mark"); len(found) != 1 || marks[0].TagName() != "mark" {
+		t.Error("Expected the wrapper to be found by its lowercase tag name")
+	}
+}
+
+func TestHighlightTextInDocument(t *testing.T) {
+	dom := createTestDOM()
+	before := dom.GetElementsByTagName("body")[0].InnerText()
+	marks := dom.HighlightText(regexp.MustCompile(`Enumerated types`), "mark", nil)
+	if len(marks) == 0 {
+		t.Fatal("Expected wrapper elements but found none")
+	}
+	for _, mark := range marks {
+		if mark.Text(false) != "Enumerated types" {
+			t.Error("Unexpected wrapped text:", mark.Text(false))
+		}
+	}
+	after := dom.GetElementsByTagName("body")[0].InnerText()
+	if before != after {
+		t.Error("Expected highlighting to not change the inner text")
+	}
+	if strings.Count(after, "Enumerated types") != len(marks) {
+		t.Error("Expected every occurrence to be highlighted")
+	}
+}
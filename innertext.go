@@ -0,0 +1,236 @@
+package goDOM
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// InnerText returns the rendered text content of the node, approximating the HTML innerText algorithm.
+//
+// Unlike Text, InnerText skips elements which are not rendered (like script, style, template or elements
+// with a hidden attribute), inserts line breaks for block elements and <br>, separates table cells with tabs,
+// collapses whitespace and keeps the content of <pre> elements verbatim.
+//
+// See Javascript equivalent: https://developer.mozilla.org/en-US/docs/Web/API/HTMLElement/innerText
+func (d *DOM) InnerText() string {
+	return d.innerText(context.Background(), false).sb.String()
+}
+
+// InnerTextContext is like InnerText but returns ctx.Err() if the context is done before the text was collected.
+// This method is not part of the Javascript Document interface.
+func (d *DOM) InnerTextContext(ctx context.Context) (string, error) {
+	b := d.innerText(ctx, false)
+	if b.err != nil {
+		return "", b.err
+	}
+	return b.sb.String(), nil
+}
+
+// innerText builds the rendered text of the node.
+// If recordSegments is set to true, the builder maps the output back to the text nodes.
+func (d *DOM) innerText(ctx context.Context, recordSegments bool) *innerTextBuilder {
+	b := &innerTextBuilder{ctx: ctx, recordSegments: recordSegments}
+	if d.node == nil {
+		return b
+	}
+	for child := d.node.FirstChild; child != nil && b.err == nil; child = child.NextSibling {
+		b.walk(child, isPreformatted(d.node))
+	}
+	return b
+}
+
+// innerTextBuilder collects the rendered text of a subtree.
+type innerTextBuilder struct {
+	ctx            context.Context
+	err            error
+	visited        int
+	sb             strings.Builder
+	pendingBreaks  int
+	pendingSpace   bool
+	recordSegments bool
+	segments       []textSegment
+}
+
+// textSegment maps a part of the rendered text back to the text node it was copied from.
+type textSegment struct {
+	start  int
+	node   *html.Node
+	offset int
+	length int
+}
+
+// walk appends the rendered text of the given node and its descendants.
+// The tree is walked with an explicit stack, so that deeply nested documents cannot exhaust the call stack.
+func (b *innerTextBuilder) walk(root *html.Node, preformatted bool) {
+	type frame struct {
+		node         *html.Node
+		preformatted bool
+		exit         bool
+	}
+	stack := []frame{{root, preformatted, false}}
+	for len(stack) > 0 {
+		if b.err = checkContext(b.ctx, b.visited); b.err != nil {
+			return
+		}
+		b.visited++
+		f := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		node := f.node
+		if f.exit {
+			if node.Data == "td" || node.Data == "th" {
+				if hasNextSiblingElement(node, "td", "th") {
+					b.literal("\t")
+				}
+			}
+			b.lineBreaks(lineBreaksAround(node))
+			continue
+		}
+		switch node.Type {
+		case html.TextNode:
+			b.text(node, f.preformatted)
+			continue
+		case html.ElementNode, html.DocumentNode:
+		default:
+			continue
+		}
+		if node.Type == html.ElementNode && !isRendered(node) {
+			continue
+		}
+		preformatted := f.preformatted || isPreformatted(node)
+		b.lineBreaks(lineBreaksAround(node))
+		if node.Type == html.ElementNode && node.Data == "br" {
+			b.literal("\n")
+		}
+		stack = append(stack, frame{node, preformatted, true})
+		for child := node.LastChild; child != nil; child = child.PrevSibling {
+			stack = append(stack, frame{child, preformatted, false})
+		}
+	}
+}
+
+// text appends the content of a text node, collapsing whitespace unless preformatted is set.
+func (b *innerTextBuilder) text(node *html.Node, preformatted bool) {
+	s := node.Data
+	if preformatted {
+		if s != "" {
+			b.flush()
+			b.write(s, node, 0)
+		}
+		return
+	}
+	start := -1
+	for i := 0; i <= len(s); i++ {
+		if i < len(s) && !isSpace(s[i]) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			b.flush()
+			b.write(s[start:i], node, start)
+			start = -1
+		}
+		if i < len(s) {
+			b.pendingSpace = true
+		}
+	}
+}
+
+// write appends s, which starts at the given byte offset of the text node, to the output.
+func (b *innerTextBuilder) write(s string, node *html.Node, offset int) {
+	if b.recordSegments {
+		b.segments = append(b.segments, textSegment{b.sb.Len(), node, offset, len(s)})
+	}
+	b.sb.WriteString(s)
+}
+
+// literal appends s without collapsing and drops any pending space.
+func (b *innerTextBuilder) literal(s string) {
+	b.pendingSpace = false
+	b.flush()
+	b.sb.WriteString(s)
+}
+
+// lineBreaks requests at least n line breaks before the next content.
+func (b *innerTextBuilder) lineBreaks(n int) {
+	if n > b.pendingBreaks {
+		b.pendingBreaks = n
+	}
+}
+
+// flush writes pending line breaks and spaces. Line breaks at the start of the output are dropped.
+func (b *innerTextBuilder) flush() {
+	if b.pendingBreaks > 0 {
+		if b.sb.Len() > 0 {
+			b.sb.WriteString(strings.Repeat("\n", b.pendingBreaks))
+		}
+		b.pendingBreaks = 0
+		b.pendingSpace = false
+		return
+	}
+	if b.pendingSpace {
+		text := b.sb.String()
+		if text != "" && !strings.HasSuffix(text, "\n") && !strings.HasSuffix(text, "\t") {
+			b.sb.WriteString(" ")
+		}
+		b.pendingSpace = false
+	}
+}
+
+// isRendered returns a boolean value indicating whether the element is displayed by a browser.
+func isRendered(node *html.Node) bool {
+	if slices.Contains(nonRenderedElements, node.Data) {
+		return false
+	}
+	for _, attr := range node.Attr {
+		if attr.Key == "hidden" {
+			return false
+		}
+		if attr.Key == "style" {
+			style := strings.ReplaceAll(strings.ToLower(attr.Val), " ", "")
+			if strings.Contains(style, "display:none") {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// isPreformatted returns a boolean value indicating whether whitespace inside the element is preserved.
+func isPreformatted(node *html.Node) bool {
+	return node.Type == html.ElementNode && slices.Contains(preformattedElements, node.Data)
+}
+
+// lineBreaksAround returns the number of line breaks required before and after the element.
+func lineBreaksAround(node *html.Node) int {
+	if node.Type != html.ElementNode {
+		return 0
+	}
+	if node.Data == "p" {
+		return 2
+	}
+	if slices.Contains(blockElements, node.Data) {
+		return 1
+	}
+	return 0
+}
+
+// hasNextSiblingElement returns a boolean value indicating whether one of the following siblings
+// is an element with one of the given tags.
+func hasNextSiblingElement(node *html.Node, tags ...string) bool {
+	for sibling := node.NextSibling; sibling != nil; sibling = sibling.NextSibling {
+		if sibling.Type == html.ElementNode && slices.Contains(tags, sibling.Data) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSpace returns a boolean value indicating whether c is ASCII whitespace.
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
@@ -0,0 +1,37 @@
+package goDOM_test
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInnerText(t *testing.T) {
+	dom := createDOMFromString(`<html><head><title>Title</title><style>p { color: red; }</style></head>` +
+		`<body><div>Hello   <b>bold</b>
+		world</div><script>var a = 1;</script><p>First</p><p>Second<br>line</p>` +
+		`<span hidden>hidden</span><pre>  keep
+  this  </pre><table><tr><td>a</td><td>b</td></tr><tr><td>c</td><td>d</td></tr></table></body></html>`)
+	expected := "Hello bold world\n\nFirst\n\nSecond\nline\n\n  keep\n  this  \na\tb\nc\td"
+	text := dom.InnerText()
+	if text != expected {
+		t.Errorf("Expected inner text to be %q but got %q", expected, text)
+	}
+	text = dom.GetElementsByTagName("div")[0].InnerText()
+	if text != "Hello bold world" {
+		t.Errorf("Expected inner text to be %q but got %q", "Hello bold world", text)
+	}
+}
+
+func TestInnerTextSkipsScripts(t *testing.T) {
+	dom := createTestDOM()
+	text := dom.GetElementsByTagName("body")[0].InnerText()
+	if strings.Contains(text, "RLQ") {
+		t.Error("Expected inner text to not contain script content")
+	}
+	if !strings.Contains(text, "Go (programming language)") {
+		t.Error("Expected inner text to contain the page heading")
+	}
+	if !strings.Contains(text, "\n    return addr == 0xFFFFFFFF\n") {
+		t.Error("Expected inner text to keep preformatted content")
+	}
+}
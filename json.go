@@ -0,0 +1,192 @@
+package goDOM
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// JSONNode is the JSON representation of a node written by MarshalJSON and read by FromJSON.
+//
+// An element is written as
+//
+//	{"type": "element", "tag": "a", "attrs": [{"name": "href", "value": "/"}], "children": [...]}
+//
+// and a text node as {"type": "text", "text": "Hello"}.
+type JSONNode struct {
+	// Type is one of "document", "fragment", "element", "text", "comment", "doctype" and "raw".
+	Type string `json:"type"`
+	// Tag is the lowercase tag name of an element.
+	Tag string `json:"tag,omitempty"`
+	// Namespace is the namespace of a foreign element, "svg" or "math". It is empty for HTML elements.
+	Namespace string `json:"namespace,omitempty"`
+	// Attrs are the attributes of an element in source order. The attributes of a doctype
+	// are its public and system identifiers.
+	Attrs []JSONAttribute `json:"attrs,omitempty"`
+	// Text is the content of a text, comment or raw node and the name of a doctype.
+	Text string `json:"text,omitempty"`
+	// Children are the child nodes of a document, fragment or element.
+	Children []*JSONNode `json:"children,omitempty"`
+	// Position is the source position of the node if positions were tracked, see SourcePosition.
+	Position *SourcePosition `json:"position,omitempty"`
+}
+
+// JSONAttribute is the JSON representation of an attribute.
+type JSONAttribute struct {
+	// Namespace is the namespace of a foreign attribute, like "xlink". It is empty for most attributes.
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	Value     string `json:"value"`
+}
+
+// jsonNodeTypes maps the node types to their names in JSON.
+var jsonNodeTypes = map[html.NodeType]string{
+	html.DocumentNode: "document",
+	html.ElementNode:  "element",
+	html.TextNode:     "text",
+	html.CommentNode:  "comment",
+	html.DoctypeNode:  "doctype",
+	html.RawNode:      "raw",
+}
+
+// MarshalJSON returns the JSON representation of the DOM and its descendants, see JSONNode.
+// Source positions are included if the document was parsed with TrackPositions set.
+// This method is not part of the Javascript Document interface.
+func (d *DOM) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.jsonNode())
+}
+
+// jsonNode returns the JSON representation of the DOM.
+func (d *DOM) jsonNode() *JSONNode {
+	var positions map[*html.Node]*SourcePosition
+	if d.doc != nil {
+		positions = d.doc.positions
+	}
+	root := toJSONNode(d.node, positions)
+	if d.fragment {
+		root.Type, root.Tag, root.Namespace, root.Attrs, root.Position = "fragment", "", "", nil, nil
+	}
+	return root
+}
+
+// toJSONNode returns the JSON representation of the node and its descendants.
+func toJSONNode(node *html.Node, positions map[*html.Node]*SourcePosition) *JSONNode {
+	type entry struct {
+		node   *html.Node
+		parent *JSONNode
+	}
+	var root *JSONNode
+	stack := []entry{{node: node}}
+	for len(stack) > 0 {
+		e := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		n := e.node
+		j := &JSONNode{Type: jsonNodeTypes[n.Type]}
+		switch n.Type {
+		case html.ElementNode:
+			j.Tag, j.Namespace = n.Data, n.Namespace
+		case html.TextNode, html.CommentNode, html.DoctypeNode, html.RawNode:
+			j.Text = n.Data
+		}
+		for _, attr := range n.Attr {
+			j.Attrs = append(j.Attrs, JSONAttribute{Namespace: attr.Namespace, Name: attr.Key, Value: attr.Val})
+		}
+		if positions != nil {
+			j.Position = positions[n]
+		}
+		if e.parent == nil {
+			root = j
+		} else {
+			e.parent.Children = append(e.parent.Children, j)
+		}
+		for child := n.LastChild; child != nil; child = child.PrevSibling {
+			stack = append(stack, entry{node: child, parent: j})
+		}
+	}
+	return root
+}
+
+// FromJSON returns the DOM for the JSON representation from the given Reader, see JSONNode.
+// An error is returned if the JSON is invalid or does not describe a valid tree.
+// This function is not part of the Javascript Document interface.
+func FromJSON(r io.Reader) (*DOM, error) {
+	var root JSONNode
+	if err := json.NewDecoder(r).Decode(&root); err != nil {
+		return nil, err
+	}
+	node, positions, err := fromJSONNode(&root)
+	if err != nil {
+		return nil, err
+	}
+	return &DOM{node: node, doc: &document{encoding: "utf-8", positions: positions}, fragment: root.Type == "fragment"}, nil
+}
+
+// fromJSONNode returns the node for the JSON representation and the source positions found in it.
+func fromJSONNode(root *JSONNode) (*html.Node, map[*html.Node]*SourcePosition, error) {
+	var positions map[*html.Node]*SourcePosition
+	type entry struct {
+		json   *JSONNode
+		parent *html.Node
+	}
+	var node *html.Node
+	stack := []entry{{json: root}}
+	for len(stack) > 0 {
+		e := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		j := e.json
+		if j == nil {
+			return nil, nil, errors.New("goDOM: invalid JSON node null")
+		}
+		n := &html.Node{}
+		switch j.Type {
+		case "document", "fragment":
+			if e.parent != nil {
+				return nil, nil, fmt.Errorf("goDOM: JSON node of type %q must be the root", j.Type)
+			}
+			n.Type = html.DocumentNode
+		case "element":
+			if j.Tag == "" {
+				return nil, nil, errors.New("goDOM: JSON element without tag")
+			}
+			n.Type, n.Data, n.DataAtom, n.Namespace = html.ElementNode, j.Tag, atom.Lookup([]byte(j.Tag)), j.Namespace
+		case "text":
+			n.Type, n.Data = html.TextNode, j.Text
+		case "comment":
+			n.Type, n.Data = html.CommentNode, j.Text
+		case "doctype":
+			n.Type, n.Data = html.DoctypeNode, j.Text
+		case "raw":
+			n.Type, n.Data = html.RawNode, j.Text
+		default:
+			return nil, nil, fmt.Errorf("goDOM: invalid JSON node type %q", j.Type)
+		}
+		if len(j.Children) > 0 && n.Type != html.DocumentNode && n.Type != html.ElementNode {
+			return nil, nil, fmt.Errorf("goDOM: JSON node of type %q cannot have children", j.Type)
+		}
+		if len(j.Attrs) > 0 && n.Type != html.ElementNode && n.Type != html.DoctypeNode {
+			return nil, nil, fmt.Errorf("goDOM: JSON node of type %q cannot have attributes", j.Type)
+		}
+		for _, attr := range j.Attrs {
+			n.Attr = append(n.Attr, html.Attribute{Namespace: attr.Namespace, Key: attr.Name, Val: attr.Value})
+		}
+		if j.Position != nil {
+			if positions == nil {
+				positions = make(map[*html.Node]*SourcePosition)
+			}
+			positions[n] = j.Position
+		}
+		if e.parent == nil {
+			node = n
+		} else {
+			e.parent.AppendChild(n)
+		}
+		for i := len(j.Children) - 1; i >= 0; i-- {
+			stack = append(stack, entry{json: j.Children[i], parent: n})
+		}
+	}
+	return node, positions, nil
+}
@@ -0,0 +1,89 @@
+package goDOM_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/richi0/goDOM"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	dom := createTestDOM()
+	data, err := json.Marshal(dom)
+	if err != nil {
+		t.Fatal("Unexpected marshal error:", err)
+	}
+	parsed, err := goDOM.FromJSON(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal("Unexpected JSON error:", err)
+	}
+	expected, _ := dom.Render()
+	if rendered, _ := parsed.Render(); rendered != expected {
+		t.Error("Expected the rebuilt DOM to render like the original")
+	}
+	if again, _ := json.Marshal(parsed); !bytes.Equal(again, data) {
+		t.Error("Expected the rebuilt DOM to have the same JSON representation")
+	}
+	if len(parsed.GetElementsByTagName("div")) != len(dom.GetElementsByTagName("div")) {
+		t.Error("Expected the rebuilt DOM to have the same elements")
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	dom := createDOMFromString(`<!DOCTYPE html><p id="a" class="b">Hi<!--c--></p><template><i>x</i></template>`)
+	data, err := json.Marshal(dom.GetElementById("a"))
+	if err != nil {
+		t.Fatal("Unexpected marshal error:", err)
+	}
+	expected := `{"type":"element","tag":"p","attrs":[{"name":"id","value":"a"},{"name":"class","value":"b"}],"children":[{"type":"text","text":"Hi"},{"type":"comment","text":"c"}]}`
+	if string(data) != expected {
+		t.Errorf("Expected\n%s\nbut got\n%s", expected, data)
+	}
+	data, _ = json.Marshal(dom.GetElementsByTagName("template")[0].TemplateContent())
+	parsed, err := goDOM.FromJSON(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal("Unexpected JSON error:", err)
+	}
+	if parsed.TagName() != "fragment" {
+		t.Error("Expected a fragment but got", parsed.TagName())
+	}
+	if rendered, _ := parsed.Render(); rendered != "<i>x</i>" {
+		t.Error("Unexpected fragment:", rendered)
+	}
+}
+
+func TestJSONPositions(t *testing.T) {
+	dom, err := goDOM.NewWithOptions(strings.NewReader(`<div id="a">text</div>`), goDOM.ParseOptions{TrackPositions: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(dom)
+	parsed, err := goDOM.FromJSON(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal("Unexpected JSON error:", err)
+	}
+	position, expected := parsed.GetElementById("a").SourcePosition(), dom.GetElementById("a").SourcePosition()
+	if position == nil || position.StartTag != expected.StartTag || position.EndTag != expected.EndTag {
+		t.Error("Expected the source position to be kept but got", position)
+	}
+	if !strings.Contains(string(data), `"startTag":{"start":{"offset":0,"line":1,"column":1}`) {
+		t.Error("Expected positions in the JSON")
+	}
+}
+
+func TestFromJSONErrors(t *testing.T) {
+	for _, data := range []string{
+		`{"type":"element"}`,
+		`{"type":"unknown"}`,
+		`{"type":"text","children":[{"type":"text"}]}`,
+		`{"type":"element","tag":"p","children":[{"type":"document"}]}`,
+		`{"type":"element","tag":"p","children":[null]}`,
+		`[`,
+	} {
+		if _, err := goDOM.FromJSON(strings.NewReader(data)); err == nil {
+			t.Errorf("Expected an error for %s", data)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package goDOM
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/net/html"
+)
+
+// Errors returned by NewWithOptions if a document exceeds the Limits of the ParseOptions.
+var (
+	ErrInputTooLarge     = errors.New("goDOM: input too large")
+	ErrTooManyNodes      = errors.New("goDOM: too many nodes")
+	ErrTooDeep           = errors.New("goDOM: document nested too deeply")
+	ErrTooManyAttributes = errors.New("goDOM: too many attributes")
+	ErrAttributeTooLong  = errors.New("goDOM: attribute too long")
+)
+
+// Limits restricts the resources a document may use. They protect services which parse untrusted input.
+// A zero value means no limit.
+//
+// The limits are checked on the tokens while the input is parsed, so that the parser stops before it
+// builds an oversized tree, and again on the parsed tree, which contains the elements the parser implies.
+// The limits apply to parsing only: nodes added to the DOM afterwards, like by ApplyPatch or HighlightText,
+// are not counted, and walks of the tree, like GetElementsByTagName, are not limited.
+type Limits struct {
+	// MaxInputBytes is the maximum number of bytes read from the input.
+	MaxInputBytes int64
+	// MaxNodes is the maximum number of nodes in the parsed tree, including the document node,
+	// and of start tags, text and comments in the input.
+	MaxNodes int
+	// MaxDepth is the maximum nesting depth of the parsed tree. The document node has depth 0.
+	// While the input is tokenized, the open elements are counted like the parser closes them.
+	MaxDepth int
+	// MaxAttributes is the maximum number of attributes of an element.
+	MaxAttributes int
+	// MaxAttributeLength is the maximum length of an attribute value in bytes.
+	MaxAttributeLength int
+}
+
+// limitedReader reads from r and fails with ErrInputTooLarge after n bytes.
+type limitedReader struct {
+	r io.Reader
+	n int64
+}
+
+// Read implements io.Reader.
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.n <= 0 {
+		// Probe for more input to tell apart an input of exactly n bytes.
+		var probe [1]byte
+		if n, _ := l.r.Read(probe[:]); n > 0 {
+			return 0, ErrInputTooLarge
+		}
+		return 0, io.EOF
+	}
+	if int64(len(p)) > l.n {
+		p = p[:l.n]
+	}
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	return n, err
+}
+
+// reader wraps r to enforce MaxInputBytes.
+func (l Limits) reader(r io.Reader) io.Reader {
+	if l.MaxInputBytes <= 0 {
+		return r
+	}
+	return &limitedReader{r, l.MaxInputBytes}
+}
+
+// tokens wraps r to enforce the limits while the input is tokenized.
+func (l Limits) tokens(r io.Reader) io.Reader {
+	if l.MaxNodes <= 0 && l.MaxDepth <= 0 && l.MaxAttributes <= 0 && l.MaxAttributeLength <= 0 {
+		return r
+	}
+	return &tokenLimiter{limits: l, z: html.NewTokenizer(r)}
+}
+
+// tokenLimiter tokenizes the input and passes the source of every token on until a token exceeds the limits.
+type tokenLimiter struct {
+	limits Limits
+	z      *html.Tokenizer
+	buf    []byte
+	err    error
+	nodes  int
+	// stack holds the open elements if the depth is limited.
+	stack []checkedElement
+}
+
+// Read implements io.Reader.
+func (t *tokenLimiter) Read(p []byte) (int, error) {
+	for len(t.buf) == 0 {
+		if t.err != nil {
+			return 0, t.err
+		}
+		t.next()
+	}
+	n := copy(p, t.buf)
+	t.buf = t.buf[n:]
+	return n, nil
+}
+
+// next reads the next token into the buffer or sets the error.
+func (t *tokenLimiter) next() {
+	tokenType := t.z.Next()
+	// The tokenizer lowercases and unescapes in place, so the raw bytes are copied first.
+	t.buf = append(t.buf[:0], t.z.Raw()...)
+	if tokenType == html.ErrorToken {
+		t.err = t.z.Err()
+		return
+	}
+	if tokenType == html.EndTagToken {
+		if t.stack != nil {
+			name, _ := t.z.TagName()
+			if index := endTagCloses(t.stack, string(name)); index >= 0 {
+				t.stack = t.stack[:index]
+			}
+		}
+		return
+	}
+	t.nodes++
+	if t.limits.MaxNodes > 0 && t.nodes > t.limits.MaxNodes {
+		t.buf, t.err = nil, fmt.Errorf("%w: more than %d nodes", ErrTooManyNodes, t.limits.MaxNodes)
+		return
+	}
+	if tokenType != html.StartTagToken && tokenType != html.SelfClosingTagToken {
+		return
+	}
+	token := t.z.Token()
+	if err := t.limits.checkAttributes(token.Data, token.Attr); err != nil {
+		t.buf, t.err = nil, err
+		return
+	}
+	if t.limits.MaxDepth <= 0 {
+		return
+	}
+	t.stack = t.stack[:startTagCloses(t.stack, token.Data)]
+	var parent *html.Node
+	if len(t.stack) > 0 {
+		parent = t.stack[len(t.stack)-1].node
+	}
+	node := &html.Node{Type: html.ElementNode, Data: token.Data, Namespace: tokenNamespace(parent, token.Data)}
+	// Like the parser, only foreign elements are closed by the self-closing flag.
+	if isVoidElement(token.Data) || (tokenType == html.SelfClosingTagToken && node.Namespace != "") {
+		return
+	}
+	t.stack = append(t.stack, checkedElement{node, 0})
+	if len(t.stack) > t.limits.MaxDepth {
+		t.buf, t.err = nil, fmt.Errorf("%w: depth of <%s> exceeds %d", ErrTooDeep, token.Data, t.limits.MaxDepth)
+	}
+}
+
+// checkAttributes returns an error if the attributes of an element exceed the limits.
+func (l Limits) checkAttributes(tag string, attrs []html.Attribute) error {
+	if l.MaxAttributes > 0 && len(attrs) > l.MaxAttributes {
+		return fmt.Errorf("%w: <%s> has %d attributes, the limit is %d", ErrTooManyAttributes, tag, len(attrs), l.MaxAttributes)
+	}
+	for _, attr := range attrs {
+		if l.MaxAttributeLength > 0 && len(attr.Val) > l.MaxAttributeLength {
+			return fmt.Errorf("%w: attribute %q of <%s> exceeds %d bytes", ErrAttributeTooLong, attr.Key, tag, l.MaxAttributeLength)
+		}
+	}
+	return nil
+}
+
+// check returns an error if the tree exceeds the limits. The tree is walked iteratively,
+// so that deeply nested documents cannot exhaust the stack.
+func (l Limits) check(root *html.Node) error {
+	if l.MaxNodes <= 0 && l.MaxDepth <= 0 && l.MaxAttributes <= 0 && l.MaxAttributeLength <= 0 {
+		return nil
+	}
+	type entry struct {
+		node  *html.Node
+		depth int
+	}
+	nodes := 0
+	stack := []entry{{root, 0}}
+	for len(stack) > 0 {
+		e := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		nodes++
+		if l.MaxNodes > 0 && nodes > l.MaxNodes {
+			return fmt.Errorf("%w: more than %d nodes", ErrTooManyNodes, l.MaxNodes)
+		}
+		if l.MaxDepth > 0 && e.depth > l.MaxDepth {
+			return fmt.Errorf("%w: depth of <%s> exceeds %d", ErrTooDeep, e.node.Data, l.MaxDepth)
+		}
+		if err := l.checkAttributes(e.node.Data, e.node.Attr); err != nil {
+			return err
+		}
+		for child := e.node.LastChild; child != nil; child = child.PrevSibling {
+			stack = append(stack, entry{child, e.depth + 1})
+		}
+	}
+	return nil
+}
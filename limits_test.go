@@ -0,0 +1,95 @@
+package goDOM_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/richi0/goDOM"
+)
+
+func TestLimits(t *testing.T) {
+	source := `<div id="a" class="b"><p>one</p><p>two</p></div>`
+	cases := []struct {
+		limits   goDOM.Limits
+		expected error
+	}{
+		{goDOM.Limits{}, nil},
+		{goDOM.Limits{MaxInputBytes: int64(len(source))}, nil},
+		{goDOM.Limits{MaxInputBytes: int64(len(source) - 1)}, goDOM.ErrInputTooLarge},
+		{goDOM.Limits{MaxNodes: 9}, nil},
+		{goDOM.Limits{MaxNodes: 8}, goDOM.ErrTooManyNodes},
+		{goDOM.Limits{MaxDepth: 5}, nil},
+		{goDOM.Limits{MaxDepth: 4}, goDOM.ErrTooDeep},
+		{goDOM.Limits{MaxAttributes: 2}, nil},
+		{goDOM.Limits{MaxAttributes: 1}, goDOM.ErrTooManyAttributes},
+		{goDOM.Limits{MaxAttributeLength: 1}, nil},
+		{goDOM.Limits{MaxAttributeLength: 0}, nil},
+	}
+	for _, c := range cases {
+		_, err := goDOM.NewWithOptions(strings.NewReader(source), goDOM.ParseOptions{Limits: c.limits})
+		if !errors.Is(err, c.expected) {
+			t.Errorf("Expected error %v for %+v but got %v", c.expected, c.limits, err)
+		}
+	}
+	_, err := goDOM.NewWithOptions(strings.NewReader(`<a href="https://example.com">x</a>`), goDOM.ParseOptions{Limits: goDOM.Limits{MaxAttributeLength: 10}})
+	if !errors.Is(err, goDOM.ErrAttributeTooLong) {
+		t.Error("Expected ErrAttributeTooLong but got", err)
+	}
+}
+
+func TestDeeplyNestedDocument(t *testing.T) {
+	depth := 100000
+	source := strings.Repeat("<span>", depth) + "deep" + strings.Repeat("</span>", depth)
+	_, err := goDOM.NewWithOptions(strings.NewReader(source), goDOM.ParseOptions{Limits: goDOM.Limits{MaxDepth: 1000}})
+	if !errors.Is(err, goDOM.ErrTooDeep) {
+		t.Fatal("Expected ErrTooDeep but got", err)
+	}
+	dom, err := goDOM.New(strings.NewReader(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dom.GetElementsByTagName("span")) != depth {
+		t.Error("Expected", depth, "elements")
+	}
+	if dom.InnerText() != "deep" {
+		t.Error("Expected inner text to be deep")
+	}
+}
+
+// endlessReader repeats its content forever.
+type endlessReader struct {
+	content string
+	offset  int
+}
+
+func (r *endlessReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = r.content[r.offset]
+		r.offset = (r.offset + 1) % len(r.content)
+	}
+	return len(p), nil
+}
+
+func TestLimitsWhileParsing(t *testing.T) {
+	cases := []struct {
+		input    io.Reader
+		limits   goDOM.Limits
+		expected error
+	}{
+		{&endlessReader{content: "<b>x</b><!--c-->"}, goDOM.Limits{MaxNodes: 1000}, goDOM.ErrTooManyNodes},
+		{&endlessReader{content: "<div><span>x"}, goDOM.Limits{MaxDepth: 1000}, goDOM.ErrTooDeep},
+		{&endlessReader{content: `<p>x</p><p class="a" id="b">`}, goDOM.Limits{MaxAttributes: 1}, goDOM.ErrTooManyAttributes},
+	}
+	for _, c := range cases {
+		_, err := goDOM.NewWithOptions(c.input, goDOM.ParseOptions{Limits: c.limits})
+		if !errors.Is(err, c.expected) {
+			t.Errorf("Expected error %v for %+v but got %v", c.expected, c.limits, err)
+		}
+		_, err = goDOM.NewWithOptions(c.input, goDOM.ParseOptions{Limits: c.limits, ReportErrors: true})
+		if !errors.Is(err, c.expected) {
+			t.Errorf("Expected error %v for %+v with errors reported but got %v", c.expected, c.limits, err)
+		}
+	}
+}
@@ -0,0 +1,599 @@
+package goDOM
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+// LinkStyle specifies how Markdown writes the destinations of links and images.
+type LinkStyle int
+
+const (
+	// LinkInline writes the destination after the text, like [text](https://example.com).
+	LinkInline LinkStyle = iota
+	// LinkReference writes a numbered reference after the text, like [text][1],
+	// and the link reference definitions at the end of the document.
+	LinkReference
+)
+
+// MarkdownOptions configures Markdown.
+type MarkdownOptions struct {
+	// Links is the style of links and images.
+	Links LinkStyle
+	// Bullet is the marker of the items of unordered lists, one of "-", "*" or "+". The default is "-".
+	Bullet string
+}
+
+// Markdown returns a Markdown representation of the DOM in the CommonMark syntax.
+//
+// Headings, paragraphs, emphasis, links, images, nested lists, blockquotes, code and horizontal rules are
+// converted. Code blocks get the language of a "language-" or "lang-" class as info string. Tables, task
+// list items and strikethrough are written in the syntax of GitHub Flavored Markdown. Characters of text
+// which would otherwise be read as Markdown are escaped. Elements which are not rendered, like <script>,
+// and SVG and MathML elements are skipped. An error is returned if the options are invalid.
+// This method is not part of the Javascript Document interface.
+func (d *DOM) Markdown(opts MarkdownOptions) (string, error) {
+	if opts.Bullet == "" {
+		opts.Bullet = "-"
+	}
+	if opts.Bullet != "-" && opts.Bullet != "*" && opts.Bullet != "+" {
+		return "", fmt.Errorf("goDOM: invalid list marker %q", opts.Bullet)
+	}
+	c := &markdownConverter{opts: opts, references: make(map[string]int)}
+	root := &markdownBuilder{}
+	stack := make([]markdownFrame, 0)
+	if d.fragment || d.node.Type == html.DocumentNode {
+		for child := d.node.LastChild; child != nil; child = child.PrevSibling {
+			stack = append(stack, markdownFrame{node: child, out: root})
+		}
+	} else {
+		stack = append(stack, markdownFrame{node: d.node, out: root})
+	}
+	for len(stack) > 0 {
+		f := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if f.exit {
+			c.exit(f.node, f.out, f.own)
+			continue
+		}
+		node := f.node
+		if node.Type == html.TextNode {
+			f.out.text(escapeMarkdown(node.Data))
+			continue
+		}
+		if node.Type != html.ElementNode || node.Namespace != "" || !isRendered(node) {
+			continue
+		}
+		own := c.enter(node, f.out)
+		if own == nil {
+			continue
+		}
+		stack = append(stack, markdownFrame{node: node, exit: true, out: f.out, own: own})
+		for child := node.LastChild; child != nil; child = child.PrevSibling {
+			stack = append(stack, markdownFrame{node: child, out: own})
+		}
+	}
+	if len(c.definitions) > 0 {
+		root.block(strings.Join(c.definitions, "\n"))
+	}
+	markdown := root.content("\n\n")
+	if markdown == "" {
+		return "", nil
+	}
+	return markdown + "\n", nil
+}
+
+// markdownFrame is an entry of the stack of Markdown.
+type markdownFrame struct {
+	node *html.Node
+	// exit is set for the entry converting the element after its content.
+	exit bool
+	// out is the builder the node is written to, own is the builder of the content of the element.
+	out, own *markdownBuilder
+}
+
+// markdownConverter holds the state of Markdown.
+type markdownConverter struct {
+	opts MarkdownOptions
+	// references maps the destinations of LinkReference to their number, definitions holds their definitions.
+	references  map[string]int
+	definitions []string
+}
+
+// enter converts an element before its content and returns the builder for its content,
+// or nil if the content has been converted already.
+func (c *markdownConverter) enter(node *html.Node, out *markdownBuilder) *markdownBuilder {
+	switch node.Data {
+	case "br":
+		out.write("\\\n")
+		out.space = false
+		return nil
+	case "hr":
+		out.block("---")
+		return nil
+	case "img":
+		out.write("![" + escapeMarkdownLabel(nodeAttribute(node, "alt")) + "]" +
+			c.destination(nodeAttribute(node, "src"), nodeAttribute(node, "title")))
+		return nil
+	case "input":
+		// Checkboxes of list items are written as task list items.
+		if strings.EqualFold(nodeAttribute(node, "type"), "checkbox") && node.Parent != nil && node.Parent.Data == "li" {
+			if hasNodeAttribute(node, "checked") {
+				out.write("[x]")
+			} else {
+				out.write("[ ]")
+			}
+			out.whitespace()
+		}
+		return nil
+	case "pre", "listing", "xmp", "plaintext":
+		out.block(codeBlock(node))
+		return nil
+	case "code", "kbd", "samp", "tt":
+		if code := nodeText(node); code != "" {
+			out.write(codeSpan(code))
+		}
+		return nil
+	case "ul", "ol", "menu":
+		out.flush()
+		return &markdownBuilder{collectItems: true}
+	case "blockquote", "table":
+		out.flush()
+		return &markdownBuilder{}
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		out.flush()
+		return &markdownBuilder{inlineOnly: true}
+	case "li", "tr":
+		return &markdownBuilder{}
+	case "td", "th", "a", "strong", "b", "em", "i", "del", "s", "strike":
+		return &markdownBuilder{inlineOnly: true}
+	}
+	if slices.Contains(blockElements, node.Data) {
+		out.flush()
+	}
+	return out
+}
+
+// exit converts an element after its content has been written to own.
+func (c *markdownConverter) exit(node *html.Node, out, own *markdownBuilder) {
+	switch node.Data {
+	case "ul", "ol", "menu":
+		items := make([]string, 0, len(own.items))
+		start := 1
+		if node.Data == "ol" {
+			if n, err := strconv.Atoi(nodeAttribute(node, "start")); err == nil && n >= 0 {
+				start = n
+			}
+		}
+		for i, item := range own.items {
+			marker := c.opts.Bullet
+			if node.Data == "ol" {
+				marker = strconv.Itoa(start+i) + "."
+			}
+			items = append(items, listItem(marker, item))
+		}
+		separator := "\n"
+		if own.loose {
+			separator = "\n\n"
+		}
+		if len(items) == 0 {
+			return
+		}
+		kind := c.opts.Bullet
+		if node.Data == "ol" {
+			kind = "."
+		}
+		out.flush()
+		if out.list == kind && out.listEnd == len(out.blocks) {
+			// Adjacent lists of the same kind would be read as one list.
+			out.blocks = append(out.blocks, "<!-- -->")
+		}
+		out.block(strings.Join(items, separator))
+		out.list, out.listEnd = kind, len(out.blocks)
+	case "li":
+		separator := "\n"
+		if hasChildElement(node, "p") {
+			separator = "\n\n"
+			out.loose = true
+		}
+		content := own.content(separator)
+		if out.collectItems {
+			out.items = append(out.items, content)
+		} else {
+			out.block(listItem(c.opts.Bullet, content))
+		}
+	case "blockquote":
+		content := own.content("\n\n")
+		if content == "" {
+			return
+		}
+		lines := strings.Split(content, "\n")
+		for i, line := range lines {
+			if line == "" {
+				lines[i] = ">"
+			} else {
+				lines[i] = "> " + line
+			}
+		}
+		out.block(strings.Join(lines, "\n"))
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		text, _, _ := own.inlineContent()
+		text = strings.TrimSpace(strings.ReplaceAll(strings.ReplaceAll(text, "\\\n", " "), "\n", " "))
+		if text != "" {
+			out.block(strings.Repeat("#", int(node.Data[1]-'0')) + " " + text)
+		}
+	case "a":
+		text, leading, trailing := own.inlineContent()
+		href := nodeAttribute(node, "href")
+		if !hasNodeAttribute(node, "href") {
+			out.element(text, leading, trailing, "", "")
+			return
+		}
+		if text == "" {
+			text = escapeMarkdownLabel(href)
+		}
+		out.element(text, leading, trailing, "[", "]"+c.destination(href, nodeAttribute(node, "title")))
+	case "strong", "b":
+		text, leading, trailing := own.inlineContent()
+		out.element(text, leading, trailing, "**", "**")
+	case "em", "i":
+		text, leading, trailing := own.inlineContent()
+		out.element(text, leading, trailing, "*", "*")
+	case "del", "s", "strike":
+		text, leading, trailing := own.inlineContent()
+		out.element(text, leading, trailing, "~~", "~~")
+	case "td", "th":
+		text, _, _ := own.inlineContent()
+		text = strings.ReplaceAll(strings.ReplaceAll(text, "\\\n", " "), "\n", " ")
+		out.cells = append(out.cells, strings.ReplaceAll(strings.TrimSpace(text), "|", "\\|"))
+		out.aligns = append(out.aligns, cellAlignment(node))
+	case "tr":
+		if len(out.rows) == 0 {
+			out.aligns = own.aligns
+		}
+		out.rows = append(out.rows, own.cells)
+	case "table":
+		if caption := own.content("\n\n"); caption != "" {
+			out.block(caption)
+		}
+		if len(own.rows) > 0 {
+			out.block(markdownTable(own.rows, own.aligns))
+		}
+	default:
+		if slices.Contains(blockElements, node.Data) {
+			out.flush()
+		}
+	}
+}
+
+// destination returns the destination of a link or image, like (https://example.com "Title"),
+// or a reference to it for LinkReference.
+func (c *markdownConverter) destination(url, title string) string {
+	if strings.ContainsAny(url, " \t\n<>()") || url == "" {
+		url = "<" + strings.NewReplacer("<", "%3C", ">", "%3E", "\n", "%0A").Replace(url) + ">"
+	}
+	if title != "" {
+		url += ` "` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(title) + `"`
+	}
+	if c.opts.Links != LinkReference {
+		return "(" + url + ")"
+	}
+	n, ok := c.references[url]
+	if !ok {
+		n = len(c.references) + 1
+		c.references[url] = n
+		c.definitions = append(c.definitions, "["+strconv.Itoa(n)+"]: "+url)
+	}
+	return "[" + strconv.Itoa(n) + "]"
+}
+
+// markdownBuilder collects the Markdown of a sequence of blocks. The inline content of the current
+// paragraph is collected in inline until a block starts.
+type markdownBuilder struct {
+	blocks []string
+	inline strings.Builder
+	// space is set if whitespace precedes the next inline content, leading if the content starts with whitespace.
+	space, leading bool
+	// items are the items of a list, loose is set if they are separated by blank lines.
+	// Only the builders of lists with collectItems set collect items.
+	items        []string
+	loose        bool
+	collectItems bool
+	// list is the marker of the last list and listEnd the number of blocks after it.
+	list    string
+	listEnd int
+	// cells and aligns are the cells of a table row, rows the rows of a table.
+	cells, aligns []string
+	rows          [][]string
+	// inlineOnly is set for the content of inline elements, headings and table cells, which is written on one line.
+	inlineOnly bool
+}
+
+// text appends text, collapsing whitespace.
+func (b *markdownBuilder) text(s string) {
+	start := -1
+	for i := 0; i <= len(s); i++ {
+		if i < len(s) && !isSpace(s[i]) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			b.write(s[start:i])
+			start = -1
+		}
+		if i < len(s) {
+			b.whitespace()
+		}
+	}
+}
+
+// whitespace requests a space before the next inline content.
+func (b *markdownBuilder) whitespace() {
+	if b.inline.Len() == 0 {
+		b.leading = b.leading || len(b.blocks) == 0
+		return
+	}
+	b.space = true
+}
+
+// write appends inline content.
+func (b *markdownBuilder) write(s string) {
+	if b.space && !strings.HasSuffix(b.inline.String(), "\n") {
+		b.inline.WriteByte(' ')
+	}
+	b.space = false
+	b.inline.WriteString(s)
+}
+
+// element appends the content of an inline element enclosed in prefix and suffix.
+// Whitespace at the edges of the content is moved outside of the element.
+func (b *markdownBuilder) element(text string, leading, trailing bool, prefix, suffix string) {
+	if leading {
+		b.whitespace()
+	}
+	if text != "" {
+		b.write(prefix + text + suffix)
+	}
+	if trailing {
+		b.whitespace()
+	}
+}
+
+// flush ends the current paragraph.
+func (b *markdownBuilder) flush() {
+	text := b.inline.String()
+	for strings.HasSuffix(text, "\\\n") {
+		text = strings.TrimSuffix(text, "\\\n")
+	}
+	if text != "" && !b.inlineOnly {
+		text = escapeLineStarts(text)
+	}
+	if text != "" {
+		b.blocks = append(b.blocks, text)
+	}
+	b.inline.Reset()
+	b.space = false
+}
+
+// block appends a block.
+func (b *markdownBuilder) block(s string) {
+	b.flush()
+	b.blocks = append(b.blocks, s)
+}
+
+// content returns the blocks separated by separator.
+func (b *markdownBuilder) content(separator string) string {
+	b.flush()
+	return strings.Join(b.blocks, separator)
+}
+
+// inlineContent returns the content for use inside an inline element and whether
+// it starts or ends with whitespace. Blocks are separated by spaces.
+func (b *markdownBuilder) inlineContent() (string, bool, bool) {
+	trailing := b.space
+	if len(b.blocks) == 0 {
+		return b.inline.String(), b.leading, trailing
+	}
+	return strings.ReplaceAll(b.content(" "), "\n", " "), b.leading, trailing
+}
+
+// listItem returns a list item with the marker. Lines after the first are indented to the content.
+func listItem(marker, content string) string {
+	lines := strings.Split(content, "\n")
+	indent := strings.Repeat(" ", len(marker)+1)
+	for i, line := range lines {
+		switch {
+		case i == 0 && line == "":
+			lines[i] = marker
+		case i == 0:
+			lines[i] = marker + " " + line
+		case line != "":
+			lines[i] = indent + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// markdownTable returns a table in the syntax of GitHub Flavored Markdown. The first row is the header row.
+func markdownTable(rows [][]string, aligns []string) string {
+	widths := make([]int, 0)
+	for _, row := range rows {
+		for i, cell := range row {
+			if i == len(widths) {
+				widths = append(widths, 3)
+			}
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+		}
+	}
+	var sb strings.Builder
+	line := func(cells []string) {
+		sb.WriteString("|")
+		for i, width := range widths {
+			cell := ""
+			if i < len(cells) {
+				cell = cells[i]
+			}
+			sb.WriteString(" " + cell + strings.Repeat(" ", width-utf8.RuneCountInString(cell)) + " |")
+		}
+		sb.WriteString("\n")
+	}
+	line(rows[0])
+	delimiter := make([]string, len(widths))
+	for i, width := range widths {
+		align := ""
+		if i < len(aligns) {
+			align = aligns[i]
+		}
+		switch align {
+		case "left":
+			delimiter[i] = ":" + strings.Repeat("-", width-1)
+		case "center":
+			delimiter[i] = ":" + strings.Repeat("-", width-2) + ":"
+		case "right":
+			delimiter[i] = strings.Repeat("-", width-1) + ":"
+		default:
+			delimiter[i] = strings.Repeat("-", width)
+		}
+	}
+	line(delimiter)
+	for _, row := range rows[1:] {
+		line(row)
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// cellAlignment returns the alignment of a table cell from its align attribute or its text-align style.
+func cellAlignment(node *html.Node) string {
+	align := strings.ToLower(nodeAttribute(node, "align"))
+	style := strings.ReplaceAll(strings.ToLower(nodeAttribute(node, "style")), " ", "")
+	for _, value := range []string{"left", "center", "right"} {
+		if strings.Contains(style, "text-align:"+value) {
+			align = value
+		}
+	}
+	return align
+}
+
+// codeBlock returns a fenced code block with the text of the element.
+// The language is taken from a "language-" or "lang-" class of the element or a <code> child.
+func codeBlock(node *html.Node) string {
+	code := strings.TrimSuffix(nodeText(node), "\n")
+	language := codeLanguage(node)
+	for child := node.FirstChild; child != nil && language == ""; child = child.NextSibling {
+		if child.Type == html.ElementNode && child.Data == "code" {
+			language = codeLanguage(child)
+		}
+	}
+	fence := strings.Repeat("`", max(3, longestRun(code, '`')+1))
+	return fence + language + "\n" + code + "\n" + fence
+}
+
+// codeLanguage returns the language of a "language-" or "lang-" class of the element.
+func codeLanguage(node *html.Node) string {
+	for _, class := range strings.Fields(nodeAttribute(node, "class")) {
+		if language, ok := strings.CutPrefix(class, "language-"); ok {
+			return language
+		}
+		if language, ok := strings.CutPrefix(class, "lang-"); ok {
+			return language
+		}
+	}
+	return ""
+}
+
+// codeSpan returns a code span with the text. The backtick string is longer than any run of backticks in the text.
+func codeSpan(code string) string {
+	code = strings.ReplaceAll(code, "\n", " ")
+	fence := strings.Repeat("`", longestRun(code, '`')+1)
+	if strings.HasPrefix(code, "`") || strings.HasSuffix(code, "`") ||
+		(strings.HasPrefix(code, " ") && strings.HasSuffix(code, " ") && strings.Trim(code, " ") != "") {
+		code = " " + code + " "
+	}
+	return fence + code + fence
+}
+
+// longestRun returns the length of the longest run of c in s.
+func longestRun(s string, c byte) int {
+	longest, run := 0, 0
+	for i := 0; i < len(s); i++ {
+		if s[i] == c {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	return longest
+}
+
+// nodeText returns the content of the text nodes of the subtree.
+func nodeText(node *html.Node) string {
+	var sb strings.Builder
+	stack := []*html.Node{node}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if n.Type == html.TextNode {
+			sb.WriteString(n.Data)
+		}
+		if n.Type == html.ElementNode && n.Data == "br" {
+			sb.WriteString("\n")
+		}
+		for child := n.LastChild; child != nil; child = child.PrevSibling {
+			stack = append(stack, child)
+		}
+	}
+	return sb.String()
+}
+
+// hasNodeAttribute returns a boolean value indicating whether the element has the attribute.
+func hasNodeAttribute(node *html.Node, key string) bool {
+	return slices.ContainsFunc(node.Attr, func(attr html.Attribute) bool { return attr.Namespace == "" && attr.Key == key })
+}
+
+// hasChildElement returns a boolean value indicating whether the element has a child element with the tag.
+func hasChildElement(node *html.Node, tag string) bool {
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode && child.Data == tag {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	// markdownEscaper escapes the characters which start inline Markdown syntax.
+	markdownEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`, "<", `\<`, "~", `\~`)
+	// entityPattern matches text which would be read as a character reference.
+	entityPattern = regexp.MustCompile(`&(#?[0-9A-Za-z]+;)`)
+	// lineStartPattern matches the start of a line which would be read as a block, like a heading or a list item.
+	lineStartPattern = regexp.MustCompile(`(?m)^([#>+=-]|\d+[.)])`)
+)
+
+// escapeMarkdown escapes the characters of text which would otherwise be read as inline Markdown syntax.
+func escapeMarkdown(s string) string {
+	return entityPattern.ReplaceAllString(markdownEscaper.Replace(s), `\&$1`)
+}
+
+// escapeMarkdownLabel escapes text written inside the brackets of a link or image, like an alt text.
+func escapeMarkdownLabel(s string) string {
+	return escapeMarkdown(strings.Join(strings.Fields(s), " "))
+}
+
+// escapeLineStarts escapes the lines of a paragraph which would otherwise start a block.
+func escapeLineStarts(s string) string {
+	return lineStartPattern.ReplaceAllStringFunc(s, func(start string) string {
+		if last := start[len(start)-1]; last == '.' || last == ')' {
+			return start[:len(start)-1] + `\` + string(last)
+		}
+		return `\` + start
+	})
+}
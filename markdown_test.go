@@ -0,0 +1,66 @@
+package goDOM_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/richi0/goDOM"
+)
+
+func TestMarkdownGolden(t *testing.T) {
+	files, err := filepath.Glob("test_data/markdown/*.html")
+	if err != nil || len(files) == 0 {
+		t.Fatal("Expected golden files in test_data/markdown")
+	}
+	for _, file := range files {
+		source, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected, err := os.ReadFile(strings.TrimSuffix(file, ".html") + ".md")
+		if err != nil {
+			t.Fatal(err)
+		}
+		opts := goDOM.MarkdownOptions{}
+		if strings.HasSuffix(file, "_reference.html") {
+			opts.Links = goDOM.LinkReference
+		}
+		markdown, err := createDOMFromString(string(source)).Markdown(opts)
+		if err != nil {
+			t.Fatal("Unexpected markdown error:", err)
+		}
+		if markdown != string(expected) {
+			t.Errorf("%s: expected\n%s\nbut got\n%s", file, expected, markdown)
+		}
+	}
+}
+
+func TestMarkdownOptions(t *testing.T) {
+	dom := createDOMFromString(`<ul><li>a</li><li>b</li></ul>`)
+	markdown, err := dom.Markdown(goDOM.MarkdownOptions{Bullet: "*"})
+	if err != nil {
+		t.Fatal("Unexpected markdown error:", err)
+	}
+	if markdown != "* a\n* b\n" {
+		t.Errorf("Expected the bullet to be used but got %q", markdown)
+	}
+	if _, err := dom.Markdown(goDOM.MarkdownOptions{Bullet: "x"}); err == nil {
+		t.Error("Expected an error for an invalid bullet")
+	}
+	if markdown, _ := dom.GetElementsByTagName("li")[1].Markdown(goDOM.MarkdownOptions{}); markdown != "- b\n" {
+		t.Errorf("Expected a single list item but got %q", markdown)
+	}
+}
+
+func TestMarkdownAdjacentLists(t *testing.T) {
+	markdown, err := createDOMFromString(`<ul><li>a</li></ul><ul><li>b</li></ul><ol><li>c</li></ol>`).Markdown(goDOM.MarkdownOptions{})
+	if err != nil {
+		t.Fatal("Unexpected markdown error:", err)
+	}
+	expected := "- a\n\n<!-- -->\n\n- b\n\n1. c\n"
+	if markdown != expected {
+		t.Errorf("Expected %q but got %q", expected, markdown)
+	}
+}
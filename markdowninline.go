@@ -0,0 +1,668 @@
+package goDOM
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	tagNamePattern   = `[A-Za-z][A-Za-z0-9-]*`
+	attributePattern = `(?:\s+[a-zA-Z_:][a-zA-Z0-9:._-]*(?:\s*=\s*(?:[^"'=<>` + "`" + `\x00-\x20]+|'[^']*'|"[^"]*"))?)`
+	openTagPattern   = `<` + tagNamePattern + attributePattern + `*\s*/?>`
+	closeTagPattern  = `</` + tagNamePattern + `\s*>`
+	// escapableChars are the characters which can be escaped with a backslash.
+	escapableChars = "!\"#$%&'()*+,./:;<=>?@[\\]^_`{|}~-"
+)
+
+var (
+	htmlTagPattern = regexp.MustCompile(`^(?:` + openTagPattern + `|` + closeTagPattern +
+		`|<!-->|<!--->|<!--[\s\S]*?-->|<[?][\s\S]*?[?]>|<![A-Za-z]+[^>]*>|<!\[CDATA\[[\s\S]*?\]\]>)`)
+	entityHerePattern      = regexp.MustCompile(`^&(?i:#x[a-f0-9]{1,6}|#[0-9]{1,7}|[a-z][a-z0-9]{1,31});`)
+	escapedOrEntityPattern = regexp.MustCompile(`\\[!"#$%&'()*+,./:;<=>?@\[\\\]^_` + "`" + `{|}~-]|&(?i:#x[a-f0-9]{1,6}|#[0-9]{1,7}|[a-z][a-z0-9]{1,31});`)
+	linkTitlePattern       = regexp.MustCompile(`^(?:"(?:\\[\s\S]|[^\\"\x00])*"|'(?:\\[\s\S]|[^\\'\x00])*'|\((?:\\[\s\S]|[^\\()\x00])*\))`)
+	linkDestinationPattern = regexp.MustCompile(`^<(?:[^<>\n\\\x00]|\\.)*>`)
+	linkLabelPattern       = regexp.MustCompile(`(?s)^\[(?:[^\\\[\]]|\\.){0,1000}\]`)
+	emailAutolinkPattern   = regexp.MustCompile(`^<([a-zA-Z0-9.!#$%&'*+/=?^_` + "`" + `{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*)>`)
+	autolinkPattern        = regexp.MustCompile(`^<[A-Za-z][A-Za-z0-9.+-]{1,31}:[^<>\x00-\x20]*>`)
+	textRunPattern         = regexp.MustCompile("^[^\n`\\[\\]\\\\!<&*_~]+")
+	whitespaceRunPattern   = regexp.MustCompile(`[ \t\r\n]+`)
+)
+
+// mdReference is a link reference definition.
+type mdReference struct {
+	destination, title string
+}
+
+// mdDelimiter is an entry of the delimiter stack for emphasis and strikethrough.
+type mdDelimiter struct {
+	char                 byte
+	count, originalCount int
+	node                 *mdNode
+	previous, next       *mdDelimiter
+	canOpen, canClose    bool
+}
+
+// mdBracket is an entry of the stack of opening brackets of links and images.
+type mdBracket struct {
+	node                        *mdNode
+	previous                    *mdBracket
+	previousDelimiter           *mdDelimiter
+	index                       int
+	image, active, bracketAfter bool
+}
+
+// mdInlineParser parses the inline content of paragraphs, headings and table cells.
+//
+// See https://spec.commonmark.org/0.31.2/#phase-2-inline-structure
+type mdInlineParser struct {
+	subject    string
+	pos        int
+	delimiters *mdDelimiter
+	brackets   *mdBracket
+	references map[string]mdReference
+}
+
+// parse replaces the content of the block by inline nodes.
+func (p *mdInlineParser) parse(block *mdNode) {
+	p.subject, p.pos = strings.TrimSpace(block.content), 0
+	p.delimiters, p.brackets = nil, nil
+	for p.parseInline(block) {
+	}
+	block.content = ""
+	p.processEmphasis(nil)
+}
+
+// peek returns the byte at the position or -1 at the end of the subject.
+func (p *mdInlineParser) peek() int {
+	return peek(p.subject, p.pos)
+}
+
+// match returns the match of the pattern at the position and advances past it.
+func (p *mdInlineParser) match(pattern *regexp.Regexp) string {
+	m := pattern.FindString(p.subject[p.pos:])
+	p.pos += len(m)
+	return m
+}
+
+// spnl skips spaces and at most one newline.
+func (p *mdInlineParser) spnl() {
+	for p.peek() == ' ' {
+		p.pos++
+	}
+	if p.peek() == '\n' {
+		p.pos++
+		for p.peek() == ' ' {
+			p.pos++
+		}
+	}
+}
+
+// text returns a text node.
+func text(s string) *mdNode {
+	return &mdNode{kind: mdText, content: s}
+}
+
+// parseInline parses the next inline at the position. It returns false at the end of the subject.
+func (p *mdInlineParser) parseInline(block *mdNode) bool {
+	c := p.peek()
+	if c == -1 {
+		return false
+	}
+	handled := true
+	switch c {
+	case '\n':
+		p.parseNewline(block)
+	case '\\':
+		p.parseBackslash(block)
+	case '`':
+		p.parseBackticks(block)
+	case '*', '_', '~':
+		handled = p.handleDelimiter(byte(c), block)
+	case '[':
+		p.pos++
+		node := text("[")
+		block.appendChild(node)
+		p.addBracket(node, p.pos-1, false)
+	case '!':
+		p.pos++
+		if p.peek() == '[' {
+			p.pos++
+			node := text("![")
+			block.appendChild(node)
+			p.addBracket(node, p.pos-1, true)
+		} else {
+			block.appendChild(text("!"))
+		}
+	case ']':
+		p.parseCloseBracket(block)
+	case '<':
+		handled = p.parseAutolink(block) || p.parseHTMLTag(block)
+	case '&':
+		handled = p.parseEntity(block)
+	default:
+		if m := p.match(textRunPattern); m != "" {
+			block.appendChild(text(m))
+		} else {
+			handled = false
+		}
+	}
+	if !handled {
+		_, size := utf8.DecodeRuneInString(p.subject[p.pos:])
+		block.appendChild(text(p.subject[p.pos : p.pos+size]))
+		p.pos += size
+	}
+	return true
+}
+
+// parseNewline parses a line ending, which is a hard break if it is preceded by two spaces.
+func (p *mdInlineParser) parseNewline(block *mdNode) {
+	p.pos++
+	last := block.lastChild
+	if last != nil && last.kind == mdText && strings.HasSuffix(last.content, " ") {
+		hard := strings.HasSuffix(last.content, "  ")
+		last.content = strings.TrimRight(last.content, " ")
+		if hard {
+			block.appendChild(&mdNode{kind: mdLineBreak})
+		} else {
+			block.appendChild(&mdNode{kind: mdSoftBreak})
+		}
+	} else {
+		block.appendChild(&mdNode{kind: mdSoftBreak})
+	}
+	for p.peek() == ' ' {
+		p.pos++
+	}
+}
+
+// parseBackslash parses a backslash escape or a hard line break.
+func (p *mdInlineParser) parseBackslash(block *mdNode) {
+	p.pos++
+	c := p.peek()
+	if c == '\n' {
+		p.pos++
+		block.appendChild(&mdNode{kind: mdLineBreak})
+	} else if c != -1 && strings.IndexByte(escapableChars, byte(c)) >= 0 {
+		block.appendChild(text(string(rune(c))))
+		p.pos++
+	} else {
+		block.appendChild(text(`\`))
+	}
+}
+
+// parseBackticks parses a code span or a literal run of backticks.
+func (p *mdInlineParser) parseBackticks(block *mdNode) {
+	ticks := backtickRun(p.subject, p.pos)
+	p.pos += ticks
+	afterOpen := p.pos
+	for p.pos < len(p.subject) {
+		i := strings.IndexByte(p.subject[p.pos:], '`')
+		if i < 0 {
+			break
+		}
+		start := p.pos + i
+		n := backtickRun(p.subject, start)
+		p.pos = start + n
+		if n == ticks {
+			contents := strings.ReplaceAll(p.subject[afterOpen:start], "\n", " ")
+			if len(contents) > 2 && contents[0] == ' ' && contents[len(contents)-1] == ' ' && strings.Trim(contents, " ") != "" {
+				contents = contents[1 : len(contents)-1]
+			}
+			block.appendChild(&mdNode{kind: mdCode, content: contents})
+			return
+		}
+	}
+	p.pos = afterOpen
+	block.appendChild(text(strings.Repeat("`", ticks)))
+}
+
+// backtickRun returns the length of the run of backticks at pos.
+func backtickRun(s string, pos int) int {
+	n := 0
+	for pos+n < len(s) && s[pos+n] == '`' {
+		n++
+	}
+	return n
+}
+
+// parseAutolink parses an URI or email autolink like <https://example.com>.
+func (p *mdInlineParser) parseAutolink(block *mdNode) bool {
+	if m := p.match(emailAutolinkPattern); m != "" {
+		address := m[1 : len(m)-1]
+		link := &mdNode{kind: mdLink, destination: normalizeURL("mailto:" + address)}
+		link.appendChild(text(address))
+		block.appendChild(link)
+		return true
+	}
+	if m := p.match(autolinkPattern); m != "" {
+		uri := m[1 : len(m)-1]
+		link := &mdNode{kind: mdLink, destination: normalizeURL(uri)}
+		link.appendChild(text(uri))
+		block.appendChild(link)
+		return true
+	}
+	return false
+}
+
+// parseHTMLTag parses raw inline HTML.
+func (p *mdInlineParser) parseHTMLTag(block *mdNode) bool {
+	m := p.match(htmlTagPattern)
+	if m == "" {
+		return false
+	}
+	block.appendChild(&mdNode{kind: mdHTMLInline, content: m})
+	return true
+}
+
+// parseEntity parses an entity or numeric character reference.
+func (p *mdInlineParser) parseEntity(block *mdNode) bool {
+	m := p.match(entityHerePattern)
+	if m == "" {
+		return false
+	}
+	block.appendChild(text(html.UnescapeString(m)))
+	return true
+}
+
+// scanDelimiters returns the length of the delimiter run at the position and whether it can open or close emphasis.
+//
+// See https://spec.commonmark.org/0.31.2/#left-flanking-delimiter-run
+func (p *mdInlineParser) scanDelimiters(c byte) (count int, canOpen, canClose bool) {
+	for p.pos+count < len(p.subject) && p.subject[p.pos+count] == c {
+		count++
+	}
+	before, after := '\n', '\n'
+	if p.pos > 0 {
+		before, _ = utf8.DecodeLastRuneInString(p.subject[:p.pos])
+	}
+	if p.pos+count < len(p.subject) {
+		after, _ = utf8.DecodeRuneInString(p.subject[p.pos+count:])
+	}
+	afterIsSpace, afterIsPunct := unicode.IsSpace(after), isMarkdownPunct(after)
+	beforeIsSpace, beforeIsPunct := unicode.IsSpace(before), isMarkdownPunct(before)
+	leftFlanking := !afterIsSpace && (!afterIsPunct || beforeIsSpace || beforeIsPunct)
+	rightFlanking := !beforeIsSpace && (!beforeIsPunct || afterIsSpace || afterIsPunct)
+	if c == '_' {
+		return count, leftFlanking && (!rightFlanking || beforeIsPunct), rightFlanking && (!leftFlanking || afterIsPunct)
+	}
+	return count, leftFlanking, rightFlanking
+}
+
+// isMarkdownPunct returns a boolean value indicating whether r is an Unicode punctuation character.
+func isMarkdownPunct(r rune) bool {
+	return unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
+
+// handleDelimiter adds a run of *, _ or ~ as text and pushes it on the delimiter stack.
+func (p *mdInlineParser) handleDelimiter(c byte, block *mdNode) bool {
+	count, canOpen, canClose := p.scanDelimiters(c)
+	node := text(p.subject[p.pos : p.pos+count])
+	block.appendChild(node)
+	p.pos += count
+	// Strikethrough uses runs of one or two tildes.
+	if c == '~' && count > 2 {
+		return true
+	}
+	if canOpen || canClose {
+		p.delimiters = &mdDelimiter{char: c, count: count, originalCount: count, node: node, previous: p.delimiters, canOpen: canOpen, canClose: canClose}
+		if p.delimiters.previous != nil {
+			p.delimiters.previous.next = p.delimiters
+		}
+	}
+	return true
+}
+
+// removeDelimiter removes the delimiter from the stack.
+func (p *mdInlineParser) removeDelimiter(d *mdDelimiter) {
+	if d.previous != nil {
+		d.previous.next = d.next
+	}
+	if d.next != nil {
+		d.next.previous = d.previous
+	} else {
+		p.delimiters = d.previous
+	}
+}
+
+// processEmphasis matches the delimiters above bottom and wraps the nodes between them in emphasis,
+// strong emphasis or strikethrough.
+//
+// See https://spec.commonmark.org/0.31.2/#process-emphasis
+func (p *mdInlineParser) processEmphasis(bottom *mdDelimiter) {
+	var openersBottom [13]*mdDelimiter
+	for i := range openersBottom {
+		openersBottom[i] = bottom
+	}
+	closer := p.delimiters
+	for closer != nil && closer.previous != bottom {
+		closer = closer.previous
+	}
+	for closer != nil {
+		if !closer.canClose {
+			closer = closer.next
+			continue
+		}
+		index := 12
+		if closer.char != '~' {
+			index = closer.originalCount % 3
+			if closer.canOpen {
+				index += 3
+			}
+			if closer.char == '*' {
+				index += 6
+			}
+		}
+		opener := closer.previous
+		found := false
+		for opener != nil && opener != bottom && opener != openersBottom[index] {
+			if opener.char == closer.char && opener.canOpen {
+				if closer.char == '~' {
+					found = opener.count == closer.count
+				} else {
+					oddMatch := (closer.canOpen || opener.canClose) && closer.originalCount%3 != 0 &&
+						(opener.originalCount+closer.originalCount)%3 == 0
+					found = !oddMatch
+				}
+				if found {
+					break
+				}
+			}
+			opener = opener.previous
+		}
+		oldCloser := closer
+		if found {
+			used := 1
+			kind := mdEmph
+			if closer.char == '~' {
+				used, kind = closer.count, mdDel
+			} else if closer.count >= 2 && opener.count >= 2 {
+				used, kind = 2, mdStrong
+			}
+			openerNode, closerNode := opener.node, closer.node
+			opener.count -= used
+			closer.count -= used
+			openerNode.content = openerNode.content[:len(openerNode.content)-used]
+			closerNode.content = closerNode.content[:len(closerNode.content)-used]
+			wrapper := &mdNode{kind: kind}
+			for node := openerNode.next; node != nil && node != closerNode; {
+				next := node.next
+				wrapper.appendChild(node)
+				node = next
+			}
+			openerNode.insertAfter(wrapper)
+			if opener.next != closer {
+				opener.next = closer
+				closer.previous = opener
+			}
+			if opener.count == 0 {
+				openerNode.unlink()
+				p.removeDelimiter(opener)
+			}
+			if closer.count == 0 {
+				closerNode.unlink()
+				next := closer.next
+				p.removeDelimiter(closer)
+				closer = next
+			}
+		} else {
+			closer = closer.next
+			openersBottom[index] = oldCloser.previous
+			if !oldCloser.canOpen {
+				p.removeDelimiter(oldCloser)
+			}
+		}
+	}
+	for p.delimiters != nil && p.delimiters != bottom {
+		p.removeDelimiter(p.delimiters)
+	}
+}
+
+// addBracket pushes an opening bracket of a link or image.
+func (p *mdInlineParser) addBracket(node *mdNode, index int, image bool) {
+	if p.brackets != nil {
+		p.brackets.bracketAfter = true
+	}
+	p.brackets = &mdBracket{node: node, previous: p.brackets, previousDelimiter: p.delimiters, index: index, image: image, active: true}
+}
+
+// parseCloseBracket parses a closing bracket, which ends a link or image if it matches an opening bracket.
+func (p *mdInlineParser) parseCloseBracket(block *mdNode) {
+	p.pos++
+	start := p.pos
+	opener := p.brackets
+	if opener == nil {
+		block.appendChild(text("]"))
+		return
+	}
+	if !opener.active {
+		block.appendChild(text("]"))
+		p.brackets = opener.previous
+		return
+	}
+	var destination, title string
+	matched := false
+	if p.peek() == '(' {
+		p.pos++
+		p.spnl()
+		if d, ok := p.parseLinkDestination(); ok {
+			destination = d
+			p.spnl()
+			if p.pos > 0 && unicode.IsSpace(rune(p.subject[p.pos-1])) {
+				title, _ = p.parseLinkTitle()
+			}
+			p.spnl()
+			if p.peek() == ')' {
+				p.pos++
+				matched = true
+			}
+		}
+		if !matched {
+			p.pos = start
+		}
+	}
+	if !matched {
+		var label string
+		beforeLabel := p.pos
+		n := p.parseLinkLabel()
+		if n > 2 {
+			label = p.subject[beforeLabel : beforeLabel+n]
+		} else if !opener.bracketAfter {
+			label = p.subject[opener.index:start]
+		}
+		if n == 0 {
+			p.pos = start
+		}
+		if label != "" {
+			if ref, ok := p.references[normalizeLabel(label)]; ok {
+				destination, title, matched = ref.destination, ref.title, true
+			}
+		}
+	}
+	if !matched {
+		p.brackets = opener.previous
+		p.pos = start
+		block.appendChild(text("]"))
+		return
+	}
+	kind := mdLink
+	if opener.image {
+		kind = mdImage
+	}
+	link := &mdNode{kind: kind, destination: destination, title: title}
+	for node := opener.node.next; node != nil; {
+		next := node.next
+		link.appendChild(node)
+		node = next
+	}
+	block.appendChild(link)
+	p.processEmphasis(opener.previousDelimiter)
+	p.brackets = opener.previous
+	opener.node.unlink()
+	// Links cannot contain other links.
+	if !opener.image {
+		for b := p.brackets; b != nil; b = b.previous {
+			if !b.image {
+				b.active = false
+			}
+		}
+	}
+}
+
+// parseLinkDestination parses a link destination in angle brackets or a destination with balanced parentheses.
+func (p *mdInlineParser) parseLinkDestination() (string, bool) {
+	if m := p.match(linkDestinationPattern); m != "" {
+		return normalizeURL(unescapeMarkdown(m[1 : len(m)-1])), true
+	}
+	if p.peek() == '<' {
+		return "", false
+	}
+	start, parens := p.pos, 0
+	for p.pos < len(p.subject) {
+		c := p.subject[p.pos]
+		if c == '\\' && p.pos+1 < len(p.subject) && strings.IndexByte(escapableChars, p.subject[p.pos+1]) >= 0 {
+			p.pos += 2
+		} else if c == '(' {
+			p.pos++
+			parens++
+		} else if c == ')' {
+			if parens < 1 {
+				break
+			}
+			p.pos++
+			parens--
+		} else if c <= ' ' || c == 0x7f {
+			break
+		} else {
+			p.pos++
+		}
+	}
+	if (p.pos == start && p.peek() != ')') || parens != 0 {
+		return "", false
+	}
+	return normalizeURL(unescapeMarkdown(p.subject[start:p.pos])), true
+}
+
+// parseLinkTitle parses a link title in double quotes, single quotes or parentheses.
+func (p *mdInlineParser) parseLinkTitle() (string, bool) {
+	m := p.match(linkTitlePattern)
+	if m == "" {
+		return "", false
+	}
+	return unescapeMarkdown(m[1 : len(m)-1]), true
+}
+
+// parseLinkLabel returns the length of the link label at the position, or 0 if there is none.
+func (p *mdInlineParser) parseLinkLabel() int {
+	m := p.match(linkLabelPattern)
+	if len(m) > 1001 {
+		p.pos -= len(m)
+		return 0
+	}
+	return len(m)
+}
+
+// parseReference parses a link reference definition at the start of s and returns its length,
+// or 0 if s does not start with a definition.
+//
+// See https://spec.commonmark.org/0.31.2/#link-reference-definitions
+func (p *mdInlineParser) parseReference(s string) int {
+	p.subject, p.pos = s, 0
+	n := p.parseLinkLabel()
+	if n == 0 || p.peek() != ':' {
+		return 0
+	}
+	rawLabel := s[:n]
+	p.pos++
+	p.spnl()
+	destination, ok := p.parseLinkDestination()
+	if !ok {
+		return 0
+	}
+	beforeTitle := p.pos
+	p.spnl()
+	title := ""
+	hasTitle := false
+	if p.pos != beforeTitle {
+		title, hasTitle = p.parseLinkTitle()
+	}
+	if !hasTitle {
+		p.pos = beforeTitle
+	}
+	if !p.atLineEnd() {
+		if !hasTitle {
+			return 0
+		}
+		// The title is not followed by the end of the line, but the definition is valid without it.
+		title, p.pos = "", beforeTitle
+		if !p.atLineEnd() {
+			return 0
+		}
+	}
+	label := normalizeLabel(rawLabel)
+	if label == "" {
+		return 0
+	}
+	if _, ok := p.references[label]; !ok {
+		p.references[label] = mdReference{destination: destination, title: title}
+	}
+	return p.pos
+}
+
+// atLineEnd skips spaces and a line ending and returns a boolean value indicating whether they end the line.
+func (p *mdInlineParser) atLineEnd() bool {
+	pos := p.pos
+	for pos < len(p.subject) && p.subject[pos] == ' ' {
+		pos++
+	}
+	if pos < len(p.subject) && p.subject[pos] != '\n' {
+		return false
+	}
+	if pos < len(p.subject) {
+		pos++
+	}
+	p.pos = pos
+	return true
+}
+
+// normalizeLabel returns the normalized form of a link label used to match references.
+func normalizeLabel(label string) string {
+	label = strings.TrimSpace(label[1 : len(label)-1])
+	return strings.ToUpper(strings.ToLower(whitespaceRunPattern.ReplaceAllString(label, " ")))
+}
+
+// unescapeMarkdown replaces backslash escapes and character references.
+func unescapeMarkdown(s string) string {
+	return escapedOrEntityPattern.ReplaceAllStringFunc(s, func(m string) string {
+		if m[0] == '\\' {
+			return m[1:]
+		}
+		return html.UnescapeString(m)
+	})
+}
+
+// normalizeURL percent-encodes the characters which are not allowed in URLs. Existing percent-encodings are kept.
+func normalizeURL(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '%' && i+2 < len(s) && isHexDigit(s[i+1]) && isHexDigit(s[i+2]):
+			sb.WriteString(s[i : i+3])
+			i += 2
+		case c < utf8.RuneSelf && (unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c)) || strings.IndexByte(";/?:@&=+$,-_.!~*'()#", c) >= 0):
+			sb.WriteByte(c)
+		default:
+			fmt.Fprintf(&sb, "%%%02X", c)
+		}
+	}
+	return sb.String()
+}
+
+// isHexDigit returns a boolean value indicating whether c is a hexadecimal digit.
+func isHexDigit(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
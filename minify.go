@@ -0,0 +1,317 @@
+package goDOM
+
+import (
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// MinifyOptions configures which minifications Minify skips. The zero value applies all of them.
+type MinifyOptions struct {
+	// KeepWhitespace keeps whitespace-only text and runs of whitespace in text.
+	KeepWhitespace bool
+	// KeepComments keeps all comments. Conditional comments, like <!--[if IE]>, are always kept.
+	KeepComments bool
+	// KeepEndTags writes the end tags which HTML allows to omit, like </li> or </p>.
+	KeepEndTags bool
+	// KeepDefaultAttributes keeps attributes whose value is the default value, like method="get" on <form>.
+	KeepDefaultAttributes bool
+	// KeepAttributeQuotes encloses all attribute values in double quotes.
+	KeepAttributeQuotes bool
+	// KeepBooleanValues writes the values of boolean attributes, like disabled="disabled".
+	KeepBooleanValues bool
+	// RemoveTextInputType removes type="text" from <input> elements. It is kept by default, because style sheets
+	// and scripts often select text inputs by the attribute, like input[type=text].
+	RemoveTextInputType bool
+}
+
+// Minify returns a minified representation of the DOM.
+//
+// Whitespace is collapsed and removed where it does not affect rendering, comments and optional end tags
+// are dropped, attributes with default values are removed, attribute values are unquoted where possible and
+// boolean attributes are written without value. Content of whitespace-sensitive elements like <pre> and
+// <script> is written unchanged. Parsing the result gives an equivalent tree.
+// This method is not part of the Javascript Document interface.
+func (d *DOM) Minify(opts MinifyOptions) (string, error) {
+	m := &minifier{opts: opts, r: &renderer{opts: RenderOptions{Quote: QuoteMinimal}}, xml: d.isXML()}
+	if opts.KeepAttributeQuotes {
+		m.r.opts.Quote = QuoteDouble
+		m.r.omitEmpty = !opts.KeepBooleanValues
+	}
+	return m.write(d), nil
+}
+
+// minifier holds the state of Minify and Canonicalize.
+type minifier struct {
+	opts MinifyOptions
+	r    *renderer
+	// ignoreAttributes and ignoreElements are the attributes and elements left out by Canonicalize.
+	ignoreAttributes []string
+	ignoreElements   selector
+	xml              bool
+}
+
+// write returns the minified representation of the DOM.
+func (m *minifier) write(d *DOM) string {
+	var sb strings.Builder
+	type entry struct {
+		node *html.Node
+		end  bool
+	}
+	stack := make([]entry, 0)
+	if d.fragment || d.node.Type == html.DocumentNode {
+		for child := d.node.LastChild; child != nil; child = child.PrevSibling {
+			stack = append(stack, entry{node: child})
+		}
+	} else {
+		stack = append(stack, entry{node: d.node})
+	}
+	verbatim := 0
+	for len(stack) > 0 {
+		e := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		n := e.node
+		if e.end {
+			if isVerbatim(n) {
+				verbatim--
+			}
+			if m.opts.KeepEndTags || !m.canOmitEndTag(n) {
+				sb.WriteString("</" + n.Data + ">")
+			}
+			continue
+		}
+		switch n.Type {
+		case html.TextNode:
+			if n.Parent != nil && n.Parent.Namespace == "" && slices.Contains(rawTextElements, n.Parent.Data) {
+				sb.WriteString(n.Data)
+			} else if verbatim > 0 {
+				sb.WriteString(escapeText(n.Data))
+			} else {
+				sb.WriteString(escapeText(m.text(n)))
+			}
+		case html.CommentNode:
+			if !m.isRemoved(n) {
+				sb.WriteString("<!--" + n.Data + "-->")
+			}
+		case html.DoctypeNode:
+			sb.WriteString(doctype(n))
+		case html.RawNode:
+			sb.WriteString(n.Data)
+		case html.ElementNode:
+			if m.isRemoved(n) {
+				continue
+			}
+			tag := *n
+			tag.Attr = m.attributes(n)
+			m.r.startTag(&sb, &tag, 0, false)
+			if isVoidElement(n.Data) || (n.Namespace != "" && n.FirstChild == nil) {
+				continue
+			}
+			if isVerbatim(n) {
+				verbatim++
+			}
+			stack = append(stack, entry{node: n, end: true})
+			for child := n.LastChild; child != nil; child = child.PrevSibling {
+				stack = append(stack, entry{node: child})
+			}
+		}
+	}
+	return sb.String()
+}
+
+// isRemoved returns a boolean value indicating whether Minify drops the node.
+func (m *minifier) isRemoved(node *html.Node) bool {
+	switch node.Type {
+	case html.CommentNode:
+		return !m.opts.KeepComments && !isConditionalComment(node.Data)
+	case html.TextNode:
+		return m.text(node) == "" && !isRawText(node)
+	case html.ElementNode:
+		return m.ignoreElements != nil && m.ignoreElements.match(node, m.xml)
+	}
+	return false
+}
+
+// text returns the minified text of a text node outside of whitespace-sensitive elements.
+// Whitespace is collapsed and removed at the edges of block elements.
+func (m *minifier) text(node *html.Node) string {
+	if m.opts.KeepWhitespace {
+		return node.Data
+	}
+	text := collapseWhitespace(node.Data)
+	parentIsBlock := node.Parent == nil || node.Parent.Type != html.ElementNode || isBlock(node.Parent)
+	// Text separated by removed nodes is written as one run, so the space is written after the previous text.
+	prev := m.sibling(node, false)
+	if (prev == nil && parentIsBlock) || (prev != nil && isBlock(prev)) ||
+		(prev != nil && prev.Type == html.TextNode && prev.Data != "" && isSpace(prev.Data[len(prev.Data)-1])) {
+		text = strings.TrimLeft(text, " ")
+	}
+	if next := m.sibling(node, true); (next == nil && parentIsBlock) || (next != nil && isBlock(next)) {
+		text = strings.TrimRight(text, " ")
+	}
+	return text
+}
+
+// sibling returns the next or previous sibling of the node which is not a removed comment or element.
+func (m *minifier) sibling(node *html.Node, next bool) *html.Node {
+	for {
+		if next {
+			node = node.NextSibling
+		} else {
+			node = node.PrevSibling
+		}
+		if node == nil || node.Type == html.TextNode || !m.isRemoved(node) {
+			return node
+		}
+	}
+}
+
+// nextWritten returns the next sibling of the node which Minify writes.
+func (m *minifier) nextWritten(node *html.Node) *html.Node {
+	for node = node.NextSibling; node != nil && m.isRemoved(node); node = node.NextSibling {
+	}
+	return node
+}
+
+// canOmitEndTag returns a boolean value indicating whether the end tag of the element can be omitted.
+//
+// See https://html.spec.whatwg.org/multipage/syntax.html#optional-tags
+func (m *minifier) canOmitEndTag(node *html.Node) bool {
+	if node.Namespace != "" {
+		return false
+	}
+	next := m.nextWritten(node)
+	followedBy := func(tags ...string) bool {
+		return next == nil || (next.Type == html.ElementNode && next.Namespace == "" && slices.Contains(tags, next.Data))
+	}
+	switch node.Data {
+	case "html", "body":
+		return next == nil || next.Type != html.CommentNode
+	case "head":
+		return next == nil || (next.Type != html.CommentNode && !(next.Type == html.TextNode && isSpace(next.Data[0])))
+	case "li":
+		return followedBy("li")
+	case "dt":
+		return next != nil && followedBy("dt", "dd")
+	case "dd":
+		return followedBy("dt", "dd")
+	case "p":
+		if next == nil {
+			parent := node.Parent
+			return parent != nil && parent.Type == html.ElementNode && !strings.Contains(parent.Data, "-") &&
+				!slices.Contains([]string{"a", "audio", "del", "ins", "map", "noscript", "video"}, parent.Data)
+		}
+		return followedBy("address", "article", "aside", "blockquote", "details", "dialog", "div", "dl", "fieldset",
+			"figcaption", "figure", "footer", "form", "h1", "h2", "h3", "h4", "h5", "h6", "header", "hgroup", "hr",
+			"main", "menu", "nav", "ol", "p", "pre", "search", "section", "table", "ul")
+	case "rt", "rp":
+		return followedBy("rt", "rp")
+	case "optgroup":
+		return followedBy("optgroup", "hr")
+	case "option":
+		return followedBy("option", "optgroup", "hr")
+	case "thead":
+		return next != nil && followedBy("tbody", "tfoot")
+	case "tbody":
+		return followedBy("tbody", "tfoot")
+	case "tfoot":
+		return next == nil
+	case "tr":
+		return followedBy("tr")
+	case "td", "th":
+		return followedBy("td", "th")
+	}
+	return false
+}
+
+// attributes returns the attributes of the element Minify writes.
+func (m *minifier) attributes(node *html.Node) []html.Attribute {
+	attrs := make([]html.Attribute, 0, len(node.Attr))
+	for _, attr := range node.Attr {
+		if slices.ContainsFunc(m.ignoreAttributes, func(name string) bool { return strings.EqualFold(name, attributeName(attr)) }) {
+			continue
+		}
+		if node.Namespace == "" && attr.Namespace == "" {
+			value, ok := defaultAttributeValues[node.Data+" "+attr.Key]
+			if ok && !m.opts.KeepDefaultAttributes && strings.EqualFold(attr.Val, value) {
+				continue
+			}
+			if m.opts.RemoveTextInputType && node.Data == "input" && attr.Key == "type" && strings.EqualFold(attr.Val, "text") {
+				continue
+			}
+			// The hidden attribute has other states than hidden, like hidden="until-found".
+			if !m.opts.KeepBooleanValues && (slices.Contains(booleanAttributes, attr.Key) ||
+				(attr.Key == "hidden" && strings.EqualFold(attr.Val, "hidden"))) {
+				attr.Val = ""
+			}
+		}
+		attrs = append(attrs, attr)
+	}
+	return attrs
+}
+
+// isConditionalComment returns a boolean value indicating whether the comment is a conditional comment
+// of Internet Explorer, like <!--[if IE]>...<![endif]-->.
+func isConditionalComment(data string) bool {
+	return strings.HasPrefix(data, "[if ") || strings.HasSuffix(data, "<![endif]")
+}
+
+// isRawText returns a boolean value indicating whether the text node is the content of
+// an element like <script> or <pre> whose text is written unchanged.
+func isRawText(node *html.Node) bool {
+	for parent := node.Parent; parent != nil; parent = parent.Parent {
+		if isVerbatim(parent) {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultAttributeValues maps "element attribute" to the default value of the attribute.
+// Attributes with these values can be removed without changing the meaning of the document.
+var defaultAttributeValues = map[string]string{
+	"area shape":        "rect",
+	"button type":       "submit",
+	"form autocomplete": "on",
+	"form enctype":      "application/x-www-form-urlencoded",
+	"form method":       "get",
+	"link media":        "all",
+	"script language":   "javascript",
+	"script type":       "text/javascript",
+	"style media":       "all",
+	"style type":        "text/css",
+	"td colspan":        "1",
+	"td rowspan":        "1",
+	"textarea wrap":     "soft",
+	"th colspan":        "1",
+	"th rowspan":        "1",
+}
+
+// booleanAttributes are the attributes whose presence alone has a meaning.
+var booleanAttributes = []string{
+	"allowfullscreen",
+	"async",
+	"autofocus",
+	"autoplay",
+	"checked",
+	"controls",
+	"default",
+	"defer",
+	"disabled",
+	"formnovalidate",
+	"inert",
+	"ismap",
+	"itemscope",
+	"loop",
+	"multiple",
+	"muted",
+	"nomodule",
+	"novalidate",
+	"open",
+	"playsinline",
+	"readonly",
+	"required",
+	"reversed",
+	"selected",
+}
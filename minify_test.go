@@ -0,0 +1,129 @@
+package goDOM_test
+
+import (
+	"testing"
+
+	"github.com/richi0/goDOM"
+)
+
+func minify(t *testing.T, dom *goDOM.DOM, opts goDOM.MinifyOptions) string {
+	t.Helper()
+	minified, err := dom.Minify(opts)
+	if err != nil {
+		t.Fatal("Unexpected minify error:", err)
+	}
+	return minified
+}
+
+func TestMinify(t *testing.T) {
+	minified := minify(t, createDOMFromString(renderTestHTML), goDOM.MinifyOptions{})
+	expected := `<!DOCTYPE html><html><head><title>T</title><style>
+ p { color: red }
+</style><body><div id=b class=a><p>Some <b>bold</b> text<ul><li>one<li>two <a href=x>link</a></ul><pre>
+
+  keep   this
+</pre><svg><rect width=1 /></svg><textarea> x</textarea></div>tail`
+	if minified != expected {
+		t.Errorf("Expected\n%s\nbut got\n%s", expected, minified)
+	}
+}
+
+func TestMinifyOptions(t *testing.T) {
+	dom := createDOMFromString(`<div id="d">
+  <!-- note --><!--[if IE]><p>IE</p><![endif]-->
+  <form method="GET"><input type="text" value="a b" disabled="disabled"><input type="checkbox" checked></form>
+  <table><tr><td colspan="1">x</td><td>y</td></tr></table>
+</div>`)
+	div := dom.GetElementById("d")
+	tests := []struct {
+		opts     goDOM.MinifyOptions
+		expected string
+	}{
+		{goDOM.MinifyOptions{},
+			`<div id=d><!--[if IE]><p>IE</p><![endif]--><form><input type=text value="a b" disabled><input type=checkbox checked></form><table><tbody><tr><td>x<td>y</table></div>`},
+		{goDOM.MinifyOptions{RemoveTextInputType: true},
+			`<div id=d><!--[if IE]><p>IE</p><![endif]--><form><input value="a b" disabled><input type=checkbox checked></form><table><tbody><tr><td>x<td>y</table></div>`},
+		{goDOM.MinifyOptions{KeepComments: true, KeepWhitespace: true},
+			"<div id=d>\n  <!-- note --><!--[if IE]><p>IE</p><![endif]-->\n  <form><input type=text value=\"a b\" disabled><input type=checkbox checked></form>\n  <table><tbody><tr><td>x<td>y</table>\n</div>"},
+		{goDOM.MinifyOptions{KeepEndTags: true, KeepDefaultAttributes: true},
+			`<div id=d><!--[if IE]><p>IE</p><![endif]--><form method=GET><input type=text value="a b" disabled><input type=checkbox checked></form><table><tbody><tr><td colspan=1>x</td><td>y</td></tr></tbody></table></div>`},
+		{goDOM.MinifyOptions{KeepAttributeQuotes: true},
+			`<div id="d"><!--[if IE]><p>IE</p><![endif]--><form><input type="text" value="a b" disabled><input type="checkbox" checked></form><table><tbody><tr><td>x<td>y</table></div>`},
+		{goDOM.MinifyOptions{KeepAttributeQuotes: true, KeepBooleanValues: true},
+			`<div id="d"><!--[if IE]><p>IE</p><![endif]--><form><input type="text" value="a b" disabled="disabled"><input type="checkbox" checked=""></form><table><tbody><tr><td>x<td>y</table></div>`},
+	}
+	for _, test := range tests {
+		if minified := minify(t, div, test.opts); minified != test.expected {
+			t.Errorf("Expected\n%s\nbut got\n%s", test.expected, minified)
+		}
+	}
+}
+
+func TestMinifyHidden(t *testing.T) {
+	dom := createDOMFromString(`<div id="d"><p hidden="hidden">a</p><p hidden="until-found">b</p><p hidden>c</p></div>`)
+	expected := `<div id=d><p hidden>a<p hidden=until-found>b<p hidden>c</div>`
+	if minified := minify(t, dom.GetElementById("d"), goDOM.MinifyOptions{}); minified != expected {
+		t.Errorf("Expected\n%s\nbut got\n%s", expected, minified)
+	}
+}
+
+func TestMinifyEndTags(t *testing.T) {
+	tests := []struct {
+		html     string
+		expected string
+	}{
+		{`<p>a</p><p>b</p><span>c</span>`, `<p>a<p>b</p><span>c</span>`},
+		{`<a href="x"><p>a</p></a>`, `<a href=x><p>a</p></a>`},
+		{`<dl><dt>a</dt><dd>b</dd><dt>c</dt></dl>`, `<dl><dt>a<dd>b<dt>c</dt></dl>`},
+		{`<select><option>a</option><optgroup><option>b</option></optgroup></select>`, `<select><option>a<optgroup><option>b</select>`},
+		{`<ul><li>a</li> text</ul>`, `<ul><li>a</li>text</ul>`},
+	}
+	for _, test := range tests {
+		dom := createDOMFromString(test.html)
+		if minified := minify(t, dom.GetElementsByTagName("body")[0], goDOM.MinifyOptions{}); minified != "<body>"+test.expected {
+			t.Errorf("Expected %s to be minified to %s but got %s", test.html, "<body>"+test.expected, minified)
+		}
+	}
+}
+
+// minifyDefaults are the attributes Minify removes from the test documents.
+var minifyDefaults = map[string]string{"input type": "text", "script type": "text/javascript", "style type": "text/css", "form method": "get"}
+
+func TestMinifyPreservesTree(t *testing.T) {
+	for _, dom := range []*goDOM.DOM{createTestDOM(), createDOMFromString(renderTestHTML)} {
+		for _, opts := range []goDOM.MinifyOptions{{}, {KeepWhitespace: true}, {KeepAttributeQuotes: true}} {
+			parsed := createDOMFromString(minify(t, dom, opts))
+			if parsed.InnerText() != dom.InnerText() {
+				t.Error("Expected minifying not to change the rendered text")
+			}
+			elements, parsedElements := dom.GetElementsByTagName("*"), parsed.GetElementsByTagName("*")
+			if len(elements) != len(parsedElements) {
+				t.Fatalf("Expected %d elements but got %d", len(elements), len(parsedElements))
+			}
+			for i, element := range elements {
+				if element.TagName() != parsedElements[i].TagName() {
+					t.Fatalf("Expected element %s but got %s", element.TagName(), parsedElements[i].TagName())
+				}
+				attributes, parsedAttributes := element.Attributes(), parsedElements[i].Attributes()
+				for key, value := range attributes {
+					parsedValue, ok := parsedAttributes[key]
+					switch {
+					case !ok && minifyDefaults[element.TagName()+" "+key] == value:
+					case ok && (parsedValue == value || (parsedValue == "" && value == key)):
+					default:
+						t.Errorf("Expected attribute %s=%q on %s but got %q", key, value, element.TagName(), parsedValue)
+					}
+				}
+				if len(parsedAttributes) > len(attributes) {
+					t.Errorf("Expected no additional attributes on %s", element.TagName())
+				}
+			}
+			pres, parsedPres := dom.GetElementsByTagName("pre"), parsed.GetElementsByTagName("pre")
+			for i := range pres {
+				if pres[i].Text(true) != parsedPres[i].Text(true) {
+					t.Errorf("Expected preformatted text %q to be kept but got %q", pres[i].Text(true), parsedPres[i].Text(true))
+				}
+			}
+		}
+	}
+}
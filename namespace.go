@@ -0,0 +1,238 @@
+package goDOM
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// URIs of the namespaces used in HTML documents.
+const (
+	NamespaceHTML   = "http://www.w3.org/1999/xhtml"
+	NamespaceSVG    = "http://www.w3.org/2000/svg"
+	NamespaceMathML = "http://www.w3.org/1998/Math/MathML"
+	NamespaceXLink  = "http://www.w3.org/1999/xlink"
+	NamespaceXML    = "http://www.w3.org/XML/1998/namespace"
+	NamespaceXMLNS  = "http://www.w3.org/2000/xmlns/"
+)
+
+// htmlNamespaces maps the namespace names of the HTML parser to namespace URIs.
+var htmlNamespaces = map[string]string{
+	"svg":   NamespaceSVG,
+	"math":  NamespaceMathML,
+	"xlink": NamespaceXLink,
+	"xml":   NamespaceXML,
+	"xmlns": NamespaceXMLNS,
+}
+
+// NamespaceURI returns the namespace URI of the element, like NamespaceSVG for an inline <svg>,
+// or the empty string if the element has no namespace or the node is not an element.
+//
+// See Javascript equivalent: https://developer.mozilla.org/en-US/docs/Web/API/Element/namespaceURI
+func (d *DOM) NamespaceURI() string {
+	if !d.isElementNode() {
+		return ""
+	}
+	return nodeNamespaceURI(d.node, d.isXML())
+}
+
+// LocalName returns the local part of the qualified name of the element.
+//
+// See Javascript equivalent: https://developer.mozilla.org/en-US/docs/Web/API/Element/localName
+func (d *DOM) LocalName() string {
+	if !d.isElementNode() {
+		return ""
+	}
+	return nodeLocalName(d.node, d.isXML())
+}
+
+// Prefix returns the namespace prefix of the element, or the empty string if no prefix is specified.
+// Elements of documents parsed as HTML never have a prefix.
+//
+// See Javascript equivalent: https://developer.mozilla.org/en-US/docs/Web/API/Element/prefix
+func (d *DOM) Prefix() string {
+	if !d.isElementNode() || !d.isXML() {
+		return ""
+	}
+	prefix, _, ok := strings.Cut(d.node.Data, ":")
+	if !ok {
+		return ""
+	}
+	return prefix
+}
+
+// GetAttributeNS returns the value of the attribute with the specified namespace and local name,
+// or the empty string if the element does not have the attribute.
+// Attributes without a namespace are found with the empty namespace.
+//
+// See Javascript equivalent: https://developer.mozilla.org/en-US/docs/Web/API/Element/getAttributeNS
+func (d *DOM) GetAttributeNS(namespace, localName string) string {
+	xml := d.isXML()
+	for _, attr := range d.node.Attr {
+		if ns, local := attributeNamespace(d.node, attr, xml); ns == namespace && local == localName {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+// SetAttributeNS sets the value of the attribute with the specified namespace and qualified name, like
+// "xlink:href". If the attribute already exists, the value is updated; otherwise a new attribute is added.
+//
+// Documents parsed as HTML can only represent the XLink, XML and XMLNS namespaces. Attributes in other
+// namespaces are stored under their qualified name without a namespace. In documents parsed by NewXML,
+// a declaration of the prefix is added if it is not bound to the namespace yet.
+//
+// See Javascript equivalent: https://developer.mozilla.org/en-US/docs/Web/API/Element/setAttributeNS
+func (d *DOM) SetAttributeNS(namespace, qualifiedName, value string) {
+	xml := d.isXML()
+	prefix, local, ok := strings.Cut(qualifiedName, ":")
+	if !ok {
+		prefix, local = "", qualifiedName
+	}
+	for i, attr := range d.node.Attr {
+		if ns, l := attributeNamespace(d.node, attr, xml); ns == namespace && l == local {
+			d.node.Attr[i].Val = value
+			return
+		}
+	}
+	if xml {
+		if prefix != "" && prefix != "xmlns" && lookupNamespaceURI(d.node, prefix) != namespace {
+			setNodeAttribute(d.node, "xmlns:"+prefix, namespace)
+		}
+		d.node.Attr = append(d.node.Attr, html.Attribute{Key: qualifiedName, Val: value})
+		return
+	}
+	for name, uri := range htmlNamespaces {
+		if uri == namespace && name != "svg" && name != "math" {
+			d.node.Attr = append(d.node.Attr, html.Attribute{Namespace: name, Key: local, Val: value})
+			return
+		}
+	}
+	d.node.Attr = append(d.node.Attr, html.Attribute{Key: qualifiedName, Val: value})
+}
+
+// GetElementsByTagNameNS returns a slice of elements with the given namespace and local name.
+// The special value "*" matches all namespaces or all local names.
+//
+// See Javascript equivalent: https://developer.mozilla.org/en-US/docs/Web/API/Element/getElementsByTagNameNS
+func (d *DOM) GetElementsByTagNameNS(namespace, localName string) []*DOM {
+	elements := make([]*DOM, 0)
+	nodes := d.getFlatElementList(true)
+	for _, node := range nodes {
+		if (namespace == "*" || node.NamespaceURI() == namespace) && (localName == "*" || node.LocalName() == localName) {
+			elements = append(elements, node)
+		}
+	}
+	return elements
+}
+
+// isXML returns a boolean value indicating whether the document was parsed by NewXML.
+func (d *DOM) isXML() bool {
+	return d.doc != nil && d.doc.xml
+}
+
+// nodeNamespaceURI returns the namespace URI of the element.
+//
+// The HTML parser uses the names "svg" and "math" for foreign elements and the empty string for
+// HTML elements, NewXML stores the namespace URI.
+func nodeNamespaceURI(node *html.Node, xml bool) string {
+	if xml {
+		return node.Namespace
+	}
+	if node.Namespace == "" {
+		return NamespaceHTML
+	}
+	return htmlNamespaces[node.Namespace]
+}
+
+// nodeLocalName returns the local part of the name of the element.
+func nodeLocalName(node *html.Node, xml bool) string {
+	if !xml {
+		return node.Data
+	}
+	if _, local, ok := strings.Cut(node.Data, ":"); ok {
+		return local
+	}
+	return node.Data
+}
+
+// attributeNamespace returns the namespace URI and the local name of an attribute of the node.
+//
+// The HTML parser splits the prefixed attributes of foreign elements into namespace and key. Attributes of
+// the tokens of a Rewriter and attributes added by SetAttribute keep the prefix in the key instead.
+func attributeNamespace(node *html.Node, attr html.Attribute, xml bool) (string, string) {
+	if !xml {
+		if attr.Namespace == "" && node.Namespace != "" {
+			prefix, local, ok := strings.Cut(attr.Key, ":")
+			if ok && (prefix == "xlink" || prefix == "xml" || prefix == "xmlns") {
+				return htmlNamespaces[prefix], local
+			}
+		}
+		return htmlNamespaces[attr.Namespace], attr.Key
+	}
+	if attr.Key == "xmlns" {
+		return NamespaceXMLNS, attr.Key
+	}
+	prefix, local, ok := strings.Cut(attr.Key, ":")
+	if !ok {
+		return "", attr.Key
+	}
+	return lookupNamespaceURI(node, prefix), local
+}
+
+// attributeName returns the qualified name of the attribute, like "xlink:href".
+func attributeName(attr html.Attribute) string {
+	if attr.Namespace == "" {
+		return attr.Key
+	}
+	return attr.Namespace + ":" + attr.Key
+}
+
+// lookupNamespaceURI returns the namespace URI bound to the prefix by the declarations
+// of the element and its ancestors in a document parsed by NewXML.
+func lookupNamespaceURI(node *html.Node, prefix string) string {
+	switch prefix {
+	case "xml":
+		return NamespaceXML
+	case "xmlns":
+		return NamespaceXMLNS
+	}
+	key := "xmlns:" + prefix
+	if prefix == "" {
+		key = "xmlns"
+	}
+	for n := node; n != nil && n.Type == html.ElementNode; n = n.Parent {
+		for _, attr := range n.Attr {
+			if attr.Key == key {
+				return attr.Val
+			}
+		}
+	}
+	return ""
+}
+
+// tokenNamespace returns the namespace name the HTML parser assigns to an element with the tag
+// opened inside parent. The content of the integration points of SVG and MathML is HTML again.
+func tokenNamespace(parent *html.Node, tag string) string {
+	switch tag {
+	case "svg", "math":
+		return tag
+	}
+	if parent == nil {
+		return ""
+	}
+	switch parent.Namespace {
+	case "svg":
+		switch strings.ToLower(parent.Data) {
+		case "foreignobject", "desc", "title":
+			return ""
+		}
+	case "math":
+		switch parent.Data {
+		case "mi", "mo", "mn", "ms", "mtext", "annotation-xml":
+			return ""
+		}
+	}
+	return parent.Namespace
+}
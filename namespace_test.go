@@ -0,0 +1,107 @@
+package goDOM_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/richi0/goDOM"
+)
+
+const namespaceTestHTML = `<p id="p">x</p><svg viewBox="0 0 1 1" xmlns:xlink="http://www.w3.org/1999/xlink">` +
+	`<linearGradient id="g"/><use xlink:href="#g"/><foreignObject><div id="inner"></div></foreignObject></svg>` +
+	`<math><mi>x</mi></math>`
+
+func TestNamespaceURI(t *testing.T) {
+	dom := createDOMFromString(namespaceTestHTML)
+	tests := map[string]string{
+		"p":              goDOM.NamespaceHTML,
+		"svg":            goDOM.NamespaceSVG,
+		"linearGradient": goDOM.NamespaceSVG,
+		"div":            goDOM.NamespaceHTML,
+		"mi":             goDOM.NamespaceMathML,
+	}
+	for tag, expected := range tests {
+		elements := dom.GetElementsByTagName(tag)
+		if len(elements) != 1 {
+			t.Fatal("Expected one element", tag, "but found", len(elements))
+		}
+		if uri := elements[0].NamespaceURI(); uri != expected {
+			t.Errorf("Expected namespace of %s to be %s but got %s", tag, expected, uri)
+		}
+		if elements[0].LocalName() != tag || elements[0].Prefix() != "" {
+			t.Errorf("Expected local name %s without prefix but got %s and %s", tag, elements[0].LocalName(), elements[0].Prefix())
+		}
+	}
+	if dom.NamespaceURI() != "" || dom.GetElementById("p").FirstChild().NamespaceURI() != "" {
+		t.Error("Expected no namespace for non-element nodes")
+	}
+}
+
+func TestNamespaceURIXML(t *testing.T) {
+	dom := createDOMFromXML(`<root xmlns="urn:a" xmlns:b="urn:b"><b:item b:id="1" id="2"/><item/><plain xmlns=""/></root>`)
+	items := dom.GetElementsByTagNameNS("urn:b", "item")
+	if len(items) != 1 || items[0].Prefix() != "b" || items[0].LocalName() != "item" || items[0].TagName() != "b:item" {
+		t.Fatal("Expected prefixed item element")
+	}
+	if items[0].GetAttributeNS("urn:b", "id") != "1" || items[0].GetAttributeNS("", "id") != "2" {
+		t.Error("Expected attributes to be found by namespace")
+	}
+	if len(dom.GetElementsByTagNameNS("urn:a", "*")) != 2 || len(dom.GetElementsByTagNameNS("*", "item")) != 2 {
+		t.Error("Expected wildcards to match")
+	}
+	if plain := dom.GetElementsByTagName("plain"); plain[0].NamespaceURI() != "" {
+		t.Error("Expected undeclared default namespace")
+	}
+}
+
+func TestAttributeNS(t *testing.T) {
+	dom := createDOMFromString(namespaceTestHTML)
+	use := dom.GetElementsByTagName("use")[0]
+	if use.GetAttributeNS(goDOM.NamespaceXLink, "href") != "#g" || use.GetAttributeNS("", "href") != "" {
+		t.Error("Expected xlink:href in the XLink namespace")
+	}
+	if use.Attributes()["xlink:href"] != "#g" || !use.HasAttribute("xlink:href") {
+		t.Error("Expected attributes to use qualified names", use.Attributes())
+	}
+	use.SetAttributeNS(goDOM.NamespaceXLink, "xlink:href", "#h")
+	use.SetAttributeNS(goDOM.NamespaceXML, "xml:lang", "en")
+	use.SetAttributeNS("", "href", "#i")
+	if use.GetAttributeNS(goDOM.NamespaceXLink, "href") != "#h" || use.GetAttributeNS(goDOM.NamespaceXML, "lang") != "en" ||
+		use.GetAttributeNS("", "href") != "#i" || len(use.Attributes()) != 3 {
+		t.Error("Expected namespaced attributes to be set", use.Attributes())
+	}
+	rendered, _ := use.Render()
+	if rendered != `<use xlink:href="#h" xml:lang="en" href="#i"></use>` {
+		t.Error("Unexpected rendering", rendered)
+	}
+
+	xml := createDOMFromXML(`<root><item/></root>`)
+	item := xml.GetElementsByTagName("item")[0]
+	item.SetAttributeNS("urn:x", "x:a", "1")
+	item.SetAttributeNS("urn:x", "x:a", "2")
+	rendered, _ = item.RenderXML()
+	if rendered != `<item xmlns:x="urn:x" x:a="2"/>` {
+		t.Error("Expected namespace declaration to be added but got", rendered)
+	}
+}
+
+func TestRenderXMLNamespaces(t *testing.T) {
+	dom := createDOMFromString(namespaceTestHTML)
+	rendered, err := dom.GetElementsByTagName("body")[0].RenderXML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := goDOM.NewXML(strings.NewReader(rendered))
+	if err != nil {
+		t.Fatal("Expected namespace-well-formed XML but got", err, rendered)
+	}
+	for _, element := range dom.GetElementsByTagNameNS("*", "*")[2:] {
+		found := parsed.GetElementsByTagNameNS(element.NamespaceURI(), element.LocalName())
+		if len(found) != 1 {
+			t.Error("Expected element", element.LocalName(), "in namespace", element.NamespaceURI())
+		}
+	}
+	if parsed.GetElementsByTagName("use")[0].GetAttributeNS(goDOM.NamespaceXLink, "href") != "#g" {
+		t.Error("Expected xlink:href to keep its namespace")
+	}
+}
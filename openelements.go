@@ -0,0 +1,250 @@
+package goDOM
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// maxOpenElements limits the depth of the stack of open elements of Rewrite and Stream. An element
+// opened at this depth closes the current element first and becomes its sibling, so the memory and the
+// cost of matching ancestors stay bounded for any input.
+const maxOpenElements = 512
+
+// An openElement is an entry of the stack of open elements of Rewrite and Stream.
+type openElement interface {
+	openNode() *html.Node
+}
+
+// Scopes of the HTML tree construction, see https://html.spec.whatwg.org/#has-an-element-in-scope.
+// Foreign elements are keyed by their namespace and lowercase tag name, like "svg desc".
+var (
+	defaultScope = map[string]bool{
+		"applet": true, "caption": true, "html": true, "table": true, "td": true, "th": true, "marquee": true,
+		"object": true, "template": true, "math mi": true, "math mo": true, "math mn": true, "math ms": true,
+		"math mtext": true, "math annotation-xml": true, "svg foreignobject": true, "svg desc": true, "svg title": true,
+	}
+	buttonScope   = extendScope(defaultScope, "button")
+	listItemScope = extendScope(defaultScope, "ol", "ul")
+	tableScope    = map[string]bool{"html": true, "table": true, "template": true}
+	// rowScope limits the search for the open cell closed by the next cell to the current row.
+	rowScope = extendScope(tableScope, "tr")
+)
+
+// extendScope returns a copy of the scope with additional HTML elements.
+func extendScope(scope map[string]bool, tags ...string) map[string]bool {
+	extended := make(map[string]bool, len(scope)+len(tags))
+	for key := range scope {
+		extended[key] = true
+	}
+	for _, tag := range tags {
+		extended[tag] = true
+	}
+	return extended
+}
+
+// specialElements are the HTML elements with special parsing rules, see https://html.spec.whatwg.org/#special.
+var specialElements = map[string]bool{
+	"address": true, "applet": true, "area": true, "article": true, "aside": true, "base": true, "basefont": true,
+	"bgsound": true, "blockquote": true, "body": true, "br": true, "button": true, "caption": true, "center": true,
+	"col": true, "colgroup": true, "dd": true, "details": true, "dir": true, "div": true, "dl": true, "dt": true,
+	"embed": true, "fieldset": true, "figcaption": true, "figure": true, "footer": true, "form": true, "frame": true,
+	"frameset": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "head": true,
+	"header": true, "hgroup": true, "hr": true, "html": true, "iframe": true, "img": true, "input": true,
+	"keygen": true, "li": true, "link": true, "listing": true, "main": true, "marquee": true, "menu": true,
+	"meta": true, "nav": true, "noembed": true, "noframes": true, "noscript": true, "object": true, "ol": true,
+	"p": true, "param": true, "plaintext": true, "pre": true, "script": true, "search": true, "section": true,
+	"select": true, "source": true, "style": true, "summary": true, "table": true, "tbody": true, "td": true,
+	"template": true, "textarea": true, "tfoot": true, "th": true, "thead": true, "title": true, "tr": true,
+	"track": true, "ul": true, "wbr": true, "xmp": true,
+}
+
+// formattingElements are the elements the HTML parser reopens after misnested markup. Their end tags
+// close the open elements inside them.
+var formattingElements = map[string]bool{
+	"a": true, "b": true, "big": true, "code": true, "em": true, "font": true, "i": true, "nobr": true,
+	"s": true, "small": true, "strike": true, "strong": true, "tt": true, "u": true,
+}
+
+// closesParagraph are the start tags which close an open p element.
+var closesParagraph = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "center": true, "details": true,
+	"dialog": true, "dir": true, "div": true, "dl": true, "fieldset": true, "figcaption": true, "figure": true,
+	"footer": true, "header": true, "hgroup": true, "main": true, "menu": true, "nav": true, "ol": true, "p": true,
+	"search": true, "section": true, "summary": true, "ul": true, "h1": true, "h2": true, "h3": true, "h4": true,
+	"h5": true, "h6": true, "pre": true, "listing": true, "form": true, "plaintext": true, "table": true,
+	"hr": true, "xmp": true, "li": true, "dd": true, "dt": true,
+}
+
+// inHead are the start tags which do not close an open head element.
+var inHead = map[string]bool{
+	"base": true, "basefont": true, "bgsound": true, "link": true, "meta": true, "title": true, "noscript": true,
+	"noframes": true, "style": true, "script": true, "template": true,
+}
+
+// breaksOutOfForeignContent are the start tags which close open SVG and MathML elements.
+var breaksOutOfForeignContent = map[string]bool{
+	"b": true, "big": true, "blockquote": true, "body": true, "br": true, "center": true, "code": true, "dd": true,
+	"div": true, "dl": true, "dt": true, "em": true, "embed": true, "h1": true, "h2": true, "h3": true, "h4": true,
+	"h5": true, "h6": true, "head": true, "hr": true, "i": true, "img": true, "li": true, "listing": true,
+	"menu": true, "meta": true, "nobr": true, "ol": true, "p": true, "pre": true, "ruby": true, "s": true,
+	"small": true, "span": true, "strong": true, "strike": true, "sub": true, "sup": true, "table": true,
+	"tt": true, "u": true, "ul": true, "var": true,
+}
+
+// startTagCloses returns the length the stack of open elements is reduced to before an element with the
+// tag name is opened. It applies the implied end tags of the HTML tree construction: an li closes the open li,
+// a div closes the open p, a td closes the open cell and so on, like the parser does.
+func startTagCloses[E openElement](stack []E, tag string) int {
+	n := len(stack)
+	if n > 0 && inForeignContent(stack[n-1].openNode()) {
+		if !breaksOutOfForeignContent[tag] {
+			return n
+		}
+		for n > 0 && inForeignContent(stack[n-1].openNode()) {
+			n--
+		}
+	}
+	stack = stack[:n]
+	isHTML := func(i int, tags ...string) bool {
+		node := stack[i].openNode()
+		for _, t := range tags {
+			if node.Namespace == "" && node.Data == t {
+				return true
+			}
+		}
+		return false
+	}
+	current := func(tags ...string) bool {
+		return n > 0 && isHTML(n-1, tags...)
+	}
+	if current("head") && !inHead[tag] {
+		n--
+	}
+	switch tag {
+	case "li", "dd", "dt":
+		items := []string{tag}
+		if tag != "li" {
+			items = []string{"dd", "dt"}
+		}
+		for i := n - 1; i >= 0; i-- {
+			if isHTML(i, items...) {
+				n = i
+				break
+			}
+			if node := stack[i].openNode(); node.Namespace == "" && specialElements[node.Data] && !isHTML(i, "address", "div", "p") {
+				break
+			}
+		}
+	case "option":
+		if current("option") {
+			n--
+		}
+	case "optgroup":
+		if current("option") {
+			n--
+		}
+		if current("optgroup") {
+			n--
+		}
+	case "tr":
+		if i := inScope(stack[:n], tableScope, "tr"); i >= 0 {
+			n = i
+		}
+	case "td", "th":
+		if i := inScope(stack[:n], rowScope, "td", "th"); i >= 0 {
+			n = i
+		}
+	case "tbody", "thead", "tfoot":
+		if i := inScope(stack[:n], tableScope, "tbody", "thead", "tfoot"); i >= 0 {
+			n = i
+		}
+	case "button":
+		if i := inScope(stack[:n], defaultScope, "button"); i >= 0 {
+			n = i
+		}
+	case "rb", "rtc":
+		for current("rb", "rp", "rt", "rtc") {
+			n--
+		}
+	case "rp", "rt":
+		for current("rb", "rp", "rt") {
+			n--
+		}
+	}
+	if closesParagraph[tag] {
+		if i := inScope(stack[:n], buttonScope, "p"); i >= 0 {
+			n = i
+		}
+	}
+	if len(tag) == 2 && tag[0] == 'h' && tag[1] >= '1' && tag[1] <= '6' && current("h1", "h2", "h3", "h4", "h5", "h6") {
+		n--
+	}
+	return n
+}
+
+// endTagCloses returns the index of the open element closed by the end tag with the tag name, or -1 if the
+// end tag is ignored. Like in the HTML tree construction, only elements in scope are closed.
+func endTagCloses[E openElement](stack []E, tag string) int {
+	// In SVG and MathML, end tags match the open foreign elements case-insensitively.
+	for i := len(stack) - 1; i >= 0 && stack[i].openNode().Namespace != ""; i-- {
+		if strings.EqualFold(stack[i].openNode().Data, tag) {
+			return i
+		}
+	}
+	switch {
+	case tag == "body" || tag == "html":
+		return inScope(stack, nil, tag)
+	case tag == "p":
+		return inScope(stack, buttonScope, tag)
+	case tag == "li":
+		return inScope(stack, listItemScope, tag)
+	case tag == "td" || tag == "th" || tag == "tr" || tag == "tbody" || tag == "thead" || tag == "tfoot" ||
+		tag == "table" || tag == "caption" || tag == "colgroup":
+		return inScope(stack, tableScope, tag)
+	case len(tag) == 2 && tag[0] == 'h' && tag[1] >= '1' && tag[1] <= '6':
+		return inScope(stack, defaultScope, "h1", "h2", "h3", "h4", "h5", "h6")
+	case specialElements[tag] || formattingElements[tag]:
+		return inScope(stack, defaultScope, tag)
+	}
+	// Any other end tag closes the matching element unless a special element is open inside it.
+	for i := len(stack) - 1; i >= 0; i-- {
+		node := stack[i].openNode()
+		if node.Namespace == "" && node.Data == tag {
+			return i
+		}
+		if node.Namespace == "" && specialElements[node.Data] {
+			return -1
+		}
+	}
+	return -1
+}
+
+// inScope returns the index of the innermost open HTML element with one of the tag names,
+// or -1 if an element of the scope is open inside it. A nil scope has no boundaries.
+func inScope[E openElement](stack []E, scope map[string]bool, tags ...string) int {
+	for i := len(stack) - 1; i >= 0; i-- {
+		node := stack[i].openNode()
+		if node.Namespace == "" {
+			for _, tag := range tags {
+				if node.Data == tag {
+					return i
+				}
+			}
+		}
+		key := node.Data
+		if node.Namespace != "" {
+			key = node.Namespace + " " + strings.ToLower(node.Data)
+		}
+		if scope[key] {
+			return -1
+		}
+	}
+	return -1
+}
+
+// inForeignContent returns a boolean value indicating whether the content of the element is parsed
+// as SVG or MathML, that is the element is foreign and not an HTML integration point.
+func inForeignContent(node *html.Node) bool {
+	return node.Namespace != "" && tokenNamespace(node, "") != ""
+}
@@ -0,0 +1,174 @@
+package goDOM
+
+import (
+	"reflect"
+	"slices"
+	"sort"
+
+	"golang.org/x/net/html"
+)
+
+// Bitmask values returned by CompareDocumentPosition.
+//
+// See Javascript equivalent: https://developer.mozilla.org/en-US/docs/Web/API/Node/compareDocumentPosition
+const (
+	DocumentPositionDisconnected           = 1
+	DocumentPositionPreceding              = 2
+	DocumentPositionFollowing              = 4
+	DocumentPositionContains               = 8
+	DocumentPositionContainedBy            = 16
+	DocumentPositionImplementationSpecific = 32
+)
+
+// IsSameNode returns a boolean value indicating whether the two DOM objects wrap the same node.
+//
+// See Javascript equivalent: https://developer.mozilla.org/en-US/docs/Web/API/Node/isSameNode
+func (d *DOM) IsSameNode(other *DOM) bool {
+	if d == nil || other == nil {
+		return false
+	}
+	return d.node != nil && d.node == other.node
+}
+
+// Contains returns a boolean value indicating whether other is a descendant of the node,
+// i.e. the node itself, one of its direct children, one of the children's direct children, and so on.
+//
+// See Javascript equivalent: https://developer.mozilla.org/en-US/docs/Web/API/Node/contains
+func (d *DOM) Contains(other *DOM) bool {
+	if d == nil || other == nil || d.node == nil {
+		return false
+	}
+	for node := other.node; node != nil; node = node.Parent {
+		if node == d.node {
+			return true
+		}
+	}
+	return false
+}
+
+// CompareDocumentPosition returns a bitmask indicating the position of other relative to the node.
+// The bits are the DocumentPosition constants. A nil node is disconnected and precedes all other nodes,
+// so that the result is consistent in both directions. Two nil nodes are only disconnected.
+//
+// See Javascript equivalent: https://developer.mozilla.org/en-US/docs/Web/API/Node/compareDocumentPosition
+func (d *DOM) CompareDocumentPosition(other *DOM) int {
+	if d.IsSameNode(other) {
+		return 0
+	}
+	missing, otherMissing := d == nil || d.node == nil, other == nil || other.node == nil
+	switch {
+	case missing && otherMissing:
+		return DocumentPositionDisconnected
+	case otherMissing:
+		return DocumentPositionDisconnected | DocumentPositionImplementationSpecific | DocumentPositionPreceding
+	case missing:
+		return DocumentPositionDisconnected | DocumentPositionImplementationSpecific | DocumentPositionFollowing
+	}
+	ancestors := ancestorChain(d.node)
+	otherAncestors := ancestorChain(other.node)
+	if ancestors[0] != otherAncestors[0] {
+		// Nodes in different trees get an arbitrary but consistent order.
+		position := DocumentPositionDisconnected | DocumentPositionImplementationSpecific
+		if reflect.ValueOf(ancestors[0]).Pointer() < reflect.ValueOf(otherAncestors[0]).Pointer() {
+			return position | DocumentPositionFollowing
+		}
+		return position | DocumentPositionPreceding
+	}
+	i := 0
+	for i < len(ancestors) && i < len(otherAncestors) && ancestors[i] == otherAncestors[i] {
+		i++
+	}
+	if i == len(ancestors) {
+		return DocumentPositionContainedBy | DocumentPositionFollowing
+	}
+	if i == len(otherAncestors) {
+		return DocumentPositionContains | DocumentPositionPreceding
+	}
+	for sibling := ancestors[i].NextSibling; sibling != nil; sibling = sibling.NextSibling {
+		if sibling == otherAncestors[i] {
+			return DocumentPositionFollowing
+		}
+	}
+	return DocumentPositionPreceding
+}
+
+// SortDocumentOrder sorts the given slice of elements in document order and returns it.
+// This method is not part of the Javascript Document interface.
+func SortDocumentOrder(nodes []*DOM) []*DOM {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return nodes[i].CompareDocumentPosition(nodes[j])&DocumentPositionFollowing != 0
+	})
+	return nodes
+}
+
+// Uniq returns a slice which contains every node of the given slice only once.
+// The order of the first occurrences is preserved.
+// This method is not part of the Javascript Document interface.
+func Uniq(nodes []*DOM) []*DOM {
+	seen := make(map[*html.Node]bool)
+	unique := make([]*DOM, 0)
+	for _, node := range nodes {
+		if node == nil || seen[node.node] {
+			continue
+		}
+		seen[node.node] = true
+		unique = append(unique, node)
+	}
+	return unique
+}
+
+// Union returns the nodes which are in a or b, without duplicates and in document order.
+// This method is not part of the Javascript Document interface.
+func Union(a, b []*DOM) []*DOM {
+	nodes := make([]*DOM, 0, len(a)+len(b))
+	nodes = append(nodes, a...)
+	nodes = append(nodes, b...)
+	return SortDocumentOrder(Uniq(nodes))
+}
+
+// Intersection returns the nodes which are in a and b, without duplicates and in document order.
+// This method is not part of the Javascript Document interface.
+func Intersection(a, b []*DOM) []*DOM {
+	inB := nodeSet(b)
+	nodes := make([]*DOM, 0)
+	for _, node := range Uniq(a) {
+		if inB[node.node] {
+			nodes = append(nodes, node)
+		}
+	}
+	return SortDocumentOrder(nodes)
+}
+
+// Difference returns the nodes which are in a but not in b, without duplicates and in document order.
+// This method is not part of the Javascript Document interface.
+func Difference(a, b []*DOM) []*DOM {
+	inB := nodeSet(b)
+	nodes := make([]*DOM, 0)
+	for _, node := range Uniq(a) {
+		if !inB[node.node] {
+			nodes = append(nodes, node)
+		}
+	}
+	return SortDocumentOrder(nodes)
+}
+
+// nodeSet returns a set of the underlying nodes of the given slice.
+func nodeSet(nodes []*DOM) map[*html.Node]bool {
+	set := make(map[*html.Node]bool)
+	for _, node := range nodes {
+		if node != nil {
+			set[node.node] = true
+		}
+	}
+	return set
+}
+
+// ancestorChain returns the given node and all its ancestors, starting with the root.
+func ancestorChain(node *html.Node) []*html.Node {
+	chain := make([]*html.Node, 0)
+	for ; node != nil; node = node.Parent {
+		chain = append(chain, node)
+	}
+	slices.Reverse(chain)
+	return chain
+}
@@ -0,0 +1,101 @@
+package goDOM_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/richi0/goDOM"
+)
+
+func TestContains(t *testing.T) {
+	dom := createTestDOM()
+	body := dom.GetElementsByTagName("body")[0]
+	link := dom.GetElementsByTagName("a")[0]
+	if !body.Contains(link) {
+		t.Error("Expected body to contain link")
+	}
+	if link.Contains(body) {
+		t.Error("Expected link not to contain body")
+	}
+	if !body.Contains(body) {
+		t.Error("Expected body to contain itself")
+	}
+}
+
+func TestCompareDocumentPosition(t *testing.T) {
+	dom := createDOMFromString(`<div id="a"><p id="b"></p></div><div id="c"></div>`)
+	a := dom.GetElementById("a")
+	b := dom.GetElementById("b")
+	c := dom.GetElementById("c")
+	if position := a.CompareDocumentPosition(a); position != 0 {
+		t.Error("Expected position 0, got:", position)
+	}
+	if position := a.CompareDocumentPosition(b); position != goDOM.DocumentPositionContainedBy|goDOM.DocumentPositionFollowing {
+		t.Error("Expected b to be contained by a, got:", position)
+	}
+	if position := b.CompareDocumentPosition(a); position != goDOM.DocumentPositionContains|goDOM.DocumentPositionPreceding {
+		t.Error("Expected a to contain b, got:", position)
+	}
+	if position := b.CompareDocumentPosition(c); position != goDOM.DocumentPositionFollowing {
+		t.Error("Expected c to follow b, got:", position)
+	}
+	if position := c.CompareDocumentPosition(b); position != goDOM.DocumentPositionPreceding {
+		t.Error("Expected b to precede c, got:", position)
+	}
+	other := createDOMFromString(`<div></div>`)
+	position := a.CompareDocumentPosition(other)
+	if position&goDOM.DocumentPositionDisconnected == 0 {
+		t.Error("Expected nodes to be disconnected, got:", position)
+	}
+	if position&goDOM.DocumentPositionFollowing == other.CompareDocumentPosition(a)&goDOM.DocumentPositionFollowing {
+		t.Error("Expected disconnected nodes to have a consistent order")
+	}
+	var missing *goDOM.DOM
+	if position := a.CompareDocumentPosition(missing); position&goDOM.DocumentPositionPreceding == 0 || position&goDOM.DocumentPositionDisconnected == 0 {
+		t.Error("Expected a nil node to be disconnected and preceding, got:", position)
+	}
+	if position := missing.CompareDocumentPosition(a); position&goDOM.DocumentPositionFollowing == 0 || position&goDOM.DocumentPositionDisconnected == 0 {
+		t.Error("Expected the node to follow a nil node, got:", position)
+	}
+	if position := missing.CompareDocumentPosition(nil); position != goDOM.DocumentPositionDisconnected {
+		t.Error("Expected nil nodes to be only disconnected, got:", position)
+	}
+}
+
+func TestSortDocumentOrder(t *testing.T) {
+	dom := createDOMFromString(`<div id="a"><p id="b"></p></div><div id="c"></div>`)
+	nodes := []*goDOM.DOM{dom.GetElementById("c"), dom.GetElementById("b"), dom.GetElementById("a")}
+	nodes = goDOM.SortDocumentOrder(nodes)
+	ids := make([]string, 0)
+	for _, node := range nodes {
+		ids = append(ids, node.Id())
+	}
+	if strings.Join(ids, ",") != "a,b,c" {
+		t.Error("Expected order a,b,c, got:", ids)
+	}
+}
+
+func TestSetOperations(t *testing.T) {
+	dom := createDOMFromString(`<div id="a" class="x"></div><div id="b" class="x y"></div><div id="c" class="y"></div>`)
+	x := dom.GetElementsByClassName("x")
+	y := dom.GetElementsByClassName("y")
+	join := func(nodes []*goDOM.DOM) string {
+		ids := make([]string, 0)
+		for _, node := range nodes {
+			ids = append(ids, node.Id())
+		}
+		return strings.Join(ids, ",")
+	}
+	if ids := join(goDOM.Uniq(append(x, x...))); ids != "a,b" {
+		t.Error("Expected uniq a,b, got:", ids)
+	}
+	if ids := join(goDOM.Union(y, x)); ids != "a,b,c" {
+		t.Error("Expected union a,b,c, got:", ids)
+	}
+	if ids := join(goDOM.Intersection(x, y)); ids != "b" {
+		t.Error("Expected intersection b, got:", ids)
+	}
+	if ids := join(goDOM.Difference(x, y)); ids != "a" {
+		t.Error("Expected difference a, got:", ids)
+	}
+}
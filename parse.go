@@ -0,0 +1,148 @@
+package goDOM
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/transform"
+)
+
+// document holds the state shared by all DOM objects of a parsed document.
+type document struct {
+	// encoding is the canonical name of the character encoding of the source.
+	encoding string
+	// positions maps nodes to their location in the source if positions are tracked.
+	positions map[*html.Node]*SourcePosition
+	// errors are the errors found in the markup if errors are reported.
+	errors []ParseError
+	// xml is set if the document was parsed by NewXML.
+	xml bool
+	// options are the options the document was parsed with.
+	options ParseOptions
+	// contentDocuments caches the documents parsed from the srcdoc attribute of iframes.
+	contentDocuments map[*html.Node]contentDocument
+	// generation is incremented on every structural mutation of the tree
+	// and invalidates the cached flat lists of its DOM objects.
+	generation uint64
+}
+
+// invalidateCaches marks the cached flat lists of the tree as outdated.
+func (d *document) invalidateCaches() {
+	if d != nil {
+		d.generation++
+	}
+}
+
+// ParseOptions configures how NewWithOptions parses a document.
+type ParseOptions struct {
+	// ContentType is the value of a Content-Type header, like "text/html; charset=Shift_JIS".
+	// Its charset parameter takes precedence over a meta charset declaration in the document.
+	ContentType string
+	// Encoding, if set, is the label of the encoding of the input, like "windows-1252".
+	// It disables the encoding detection.
+	Encoding string
+	// TrackPositions records the source position of every node, see SourcePosition.
+	TrackPositions bool
+	// ReportErrors collects the errors in the markup which the parser repairs, see ParseErrors.
+	ReportErrors bool
+	// Strict makes NewWithOptions return a ParseErrors error instead of a repaired tree
+	// if the markup contains errors.
+	Strict bool
+	// Limits restricts the size of the input and of the parsed tree.
+	Limits Limits
+	// IncludeTemplateContent makes queries like GetElementsByTagName return the content of <template>
+	// elements, which is excluded by default, see TemplateContent.
+	IncludeTemplateContent bool
+	// DisableScripting parses the document like a browser with scripting disabled, so that the content
+	// of <noscript> elements becomes elements instead of text.
+	DisableScripting bool
+}
+
+// NewWithOptions returns the parsed tree for the HTML from the given Reader as a DOM object.
+//
+// Unlike New, which expects UTF-8 input, NewWithOptions determines the character encoding of the input
+// from a byte order mark, the Content-Type of opts or a meta charset declaration and transcodes the input
+// to UTF-8 before parsing. The detected encoding is returned by Encoding.
+func NewWithOptions(r io.Reader, opts ParseOptions) (*DOM, error) {
+	input := bufio.NewReaderSize(opts.Limits.reader(r), 1024)
+	var enc encoding.Encoding
+	var name string
+	if opts.Encoding != "" {
+		enc, name = charset.Lookup(opts.Encoding)
+		if enc == nil {
+			return nil, fmt.Errorf("goDOM: unsupported encoding %q", opts.Encoding)
+		}
+	} else {
+		preview, err := input.Peek(1024)
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		enc, name, _ = charset.DetermineEncoding(preview, opts.ContentType)
+	}
+	var source io.Reader = input
+	if name == "utf-8" {
+		if preview, _ := input.Peek(3); bytes.Equal(preview, []byte("\xef\xbb\xbf")) {
+			input.Discard(3)
+		}
+	} else {
+		source = transform.NewReader(input, enc.NewDecoder())
+	}
+	source = opts.Limits.tokens(source)
+	doc := &document{encoding: name, options: opts}
+	if opts.ReportErrors || opts.Strict {
+		input, err := io.ReadAll(source)
+		if err != nil {
+			return nil, err
+		}
+		doc.errors = checkSource(input, !opts.DisableScripting)
+		if opts.Strict && len(doc.errors) > 0 {
+			return nil, ParseErrors(doc.errors)
+		}
+		source = bytes.NewReader(input)
+	}
+	var node *html.Node
+	var err error
+	if opts.TrackPositions {
+		node, doc.positions, err = parseWithPositions(source, !opts.DisableScripting)
+	} else {
+		node, err = html.ParseWithOptions(source, html.ParseOptionEnableScripting(!opts.DisableScripting))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := opts.Limits.check(node); err != nil {
+		return nil, err
+	}
+	return newDOM(node, doc), nil
+}
+
+// Encoding returns the canonical name of the character encoding the document was parsed from, like "utf-8"
+// or "shift_jis". The name is empty if the DOM was not created by New or NewWithOptions.
+//
+// See Javascript equivalent: https://developer.mozilla.org/en-US/docs/Web/API/Document/characterSet
+func (d *DOM) Encoding() string {
+	if d.doc == nil {
+		return ""
+	}
+	return d.doc.encoding
+}
+
+// RenderEncoded returns the rendered DOM encoded in the character encoding the document was parsed from.
+// Characters which the encoding cannot represent are written as numeric character references.
+// This method is not part of the Javascript Document interface.
+func (d *DOM) RenderEncoded() ([]byte, error) {
+	rendered, err := d.Render()
+	if err != nil {
+		return nil, err
+	}
+	enc, name := charset.Lookup(d.Encoding())
+	if enc == nil || name == "utf-8" {
+		return []byte(rendered), nil
+	}
+	return enc.NewEncoder().Bytes([]byte(rendered))
+}
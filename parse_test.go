@@ -0,0 +1,87 @@
+package goDOM_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/richi0/goDOM"
+	"golang.org/x/text/encoding/japanese"
+)
+
+func TestNewWithOptionsMetaCharset(t *testing.T) {
+	source, err := japanese.ShiftJIS.NewEncoder().String(`<html><head><meta charset="Shift_JIS"></head><body><p>こんにちは</p></body></html>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dom, err := goDOM.NewWithOptions(strings.NewReader(source), goDOM.ParseOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dom.Encoding() != "shift_jis" {
+		t.Error("Expected encoding to be shift_jis, got:", dom.Encoding())
+	}
+	if text := dom.GetElementsByTagName("p")[0].Text(false); text != "こんにちは" {
+		t.Error("Expected text to be transcoded, got:", text)
+	}
+	if dom.GetElementsByTagName("p")[0].Encoding() != "shift_jis" {
+		t.Error("Expected child elements to report the document encoding")
+	}
+	rendered, err := dom.RenderEncoded()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(rendered, []byte("<p>"+source[strings.Index(source, "<p>")+3:strings.Index(source, "</p>")]+"</p>")) {
+		t.Error("Expected rendered document to be encoded in shift_jis")
+	}
+}
+
+func TestNewWithOptionsContentType(t *testing.T) {
+	source := "<p>caf\xe9</p>"
+	dom, err := goDOM.NewWithOptions(strings.NewReader(source), goDOM.ParseOptions{ContentType: "text/html; charset=windows-1252"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dom.Encoding() != "windows-1252" {
+		t.Error("Expected encoding to be windows-1252, got:", dom.Encoding())
+	}
+	if text := dom.GetElementsByTagName("p")[0].Text(false); text != "café" {
+		t.Error("Expected text to be transcoded, got:", text)
+	}
+	dom.GetElementsByTagName("p")[0].SetAttribute("title", "☃")
+	rendered, _ := dom.RenderEncoded()
+	if !bytes.Contains(rendered, []byte("<p title=\"&#9731;\">caf\xe9</p>")) {
+		t.Error("Expected unsupported characters to be escaped, got:", string(rendered))
+	}
+	dom, err = goDOM.NewWithOptions(strings.NewReader(source), goDOM.ParseOptions{Encoding: "latin1"})
+	if err != nil || dom.Encoding() != "windows-1252" {
+		t.Error("Expected latin1 to be an alias of windows-1252, got:", dom.Encoding(), err)
+	}
+	if _, err := goDOM.NewWithOptions(strings.NewReader(source), goDOM.ParseOptions{Encoding: "unknown"}); err == nil {
+		t.Error("Expected an error for an unknown encoding")
+	}
+}
+
+func TestNewWithOptionsBOM(t *testing.T) {
+	dom, err := goDOM.NewWithOptions(strings.NewReader("\xef\xbb\xbf<p>h\xc3\xa9</p>"), goDOM.ParseOptions{ContentType: "text/html; charset=windows-1252"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dom.Encoding() != "utf-8" {
+		t.Error("Expected the byte order mark to take precedence, got:", dom.Encoding())
+	}
+	if text := dom.GetElementsByTagName("p")[0].Text(false); text != "hé" {
+		t.Errorf("Expected text to be %q, got: %q", "hé", text)
+	}
+	utf16 := []byte{0xff, 0xfe}
+	for _, c := range "<p>hi</p>" {
+		utf16 = append(utf16, byte(c), 0)
+	}
+	dom, err = goDOM.NewWithOptions(bytes.NewReader(utf16), goDOM.ParseOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dom.Encoding() != "utf-16le" || dom.GetElementsByTagName("p")[0].Text(false) != "hi" {
+		t.Error("Expected utf-16le document to be transcoded, got:", dom.Encoding())
+	}
+}
@@ -0,0 +1,270 @@
+package goDOM
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Codes of the errors reported by the parser.
+// They follow the names of the parse errors of the HTML specification where one exists.
+//
+// See https://html.spec.whatwg.org/multipage/parsing.html#parse-errors
+const (
+	ErrorCodeDuplicateAttribute      = "duplicate-attribute"
+	ErrorCodeEndTagWithAttributes    = "end-tag-with-attributes"
+	ErrorCodeEOFInTag                = "eof-in-tag"
+	ErrorCodeMisnestedTag            = "misnested-tag"
+	ErrorCodeNonVoidSelfClosing      = "non-void-html-element-start-tag-with-trailing-solidus"
+	ErrorCodeStrayEndTag             = "stray-end-tag"
+	ErrorCodeUnclosedElement         = "unclosed-element"
+	ErrorCodeUnexpectedNullCharacter = "unexpected-null-character"
+)
+
+// A ParseError describes a problem in the markup of a document which the parser repaired.
+type ParseError struct {
+	// Code identifies the kind of the error, see the ErrorCode constants.
+	Code string
+	// Message describes the error.
+	Message string
+	// Position is the location of the error in the source.
+	Position Position
+}
+
+// Error returns the error in the form "line:column: message".
+func (e ParseError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Position, e.Message)
+}
+
+// ParseErrors is the error returned by NewWithOptions in strict mode.
+// It contains all errors found in the document.
+type ParseErrors []ParseError
+
+// Error returns all errors, one per line.
+func (e ParseErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "\n")
+}
+
+// ParseErrors returns the errors found in the markup of the document.
+//
+// Errors are only collected if the document was parsed by NewWithOptions with ReportErrors set.
+// This method is not part of the Javascript Document interface.
+func (d *DOM) ParseErrors() []ParseError {
+	if d.doc == nil {
+		return nil
+	}
+	return d.doc.errors
+}
+
+// checkSource tokenizes the input and returns the errors of its markup.
+//
+// The check replays the token stream on a stack of open elements with the rules of the HTML tree
+// construction: the html, head and body elements and the tbody and tr elements of tables are implied
+// where the parser inserts them, elements whose end tag is optional, like <p> or <li>, are closed implicitly
+// and end tags only close elements in scope. Errors of the tree construction which the parser repairs
+// silently, like content after </body>, are not reported.
+func checkSource(input []byte, scripting bool) []ParseError {
+	errs := make([]ParseError, 0)
+	lines := newLineIndex(input)
+	report := func(code string, offset int, format string, args ...any) {
+		errs = append(errs, ParseError{code, fmt.Sprintf(format, args...), lines.position(offset)})
+	}
+	stack := make([]checkedElement, 0)
+	current := func() string {
+		if len(stack) == 0 || stack[len(stack)-1].node.Namespace != "" {
+			return ""
+		}
+		return stack[len(stack)-1].node.Data
+	}
+	// open pushes an element, implied elements have no offset.
+	open := func(tag string, offset int) *html.Node {
+		var parent *html.Node
+		if len(stack) > 0 {
+			parent = stack[len(stack)-1].node
+		}
+		node := &html.Node{Type: html.ElementNode, Data: tag, Namespace: tokenNamespace(parent, tag)}
+		stack = append(stack, checkedElement{node, offset})
+		return node
+	}
+	// closeTo reduces the stack to n elements and reports the elements from the index on which are not
+	// allowed to be closed implicitly.
+	closeTo := func(n, from, offset int, format string, tag string) {
+		for _, element := range stack[from:] {
+			if !element.optional() {
+				report(ErrorCodeMisnestedTag, offset, format, tag, element.node.Data, lines.position(element.offset))
+			}
+		}
+		stack = stack[:n]
+	}
+	headSeen, bodySeen := false, false
+	// after is the tag name of the last end tag of the body or html element, until more content follows.
+	after := ""
+	impliedHTML := func() {
+		if len(stack) == 0 {
+			open("html", -1)
+		}
+	}
+	impliedBody := func() {
+		if bodySeen {
+			return
+		}
+		impliedHTML()
+		if current() == "head" {
+			stack = stack[:len(stack)-1]
+		}
+		open("body", -1)
+		headSeen, bodySeen = true, true
+	}
+	for _, t := range tokenizeSource(input, scripting) {
+		token := t.token
+		switch token.Type {
+		case html.ErrorToken:
+			if bytes.HasPrefix(t.raw, []byte("<")) {
+				report(ErrorCodeEOFInTag, t.start, "unexpected end of file in tag")
+			}
+		case html.TextToken:
+			if i := bytes.IndexByte(t.raw, 0); i >= 0 {
+				report(ErrorCodeUnexpectedNullCharacter, t.start+i, "unexpected null character")
+			}
+			if strings.TrimLeft(token.Data, " \t\n\f\r") == "" {
+				continue
+			}
+			if tag := current(); !bodySeen && (tag == "" || tag == "html" || tag == "head") {
+				impliedBody()
+			}
+			after = ""
+		case html.StartTagToken, html.SelfClosingTagToken:
+			seen := make(map[string]bool)
+			for _, attr := range token.Attr {
+				if seen[attr.Key] {
+					report(ErrorCodeDuplicateAttribute, t.start, "duplicate attribute %q on <%s>", attr.Key, token.Data)
+				}
+				seen[attr.Key] = true
+			}
+			after = ""
+			switch tag := token.Data; {
+			case tag == "html" || tag == "head" || tag == "body":
+				// The parser merges the attributes of repeated html and body elements and ignores them otherwise.
+				if bodySeen || (tag == "head" && headSeen) {
+					continue
+				}
+				if tag == "html" {
+					if len(stack) == 0 {
+						open(tag, t.start)
+					}
+					continue
+				}
+				impliedHTML()
+				if tag == "body" {
+					if current() == "head" {
+						stack = stack[:len(stack)-1]
+					}
+					bodySeen = true
+				}
+				open(tag, t.start)
+				headSeen = true
+				continue
+			case !bodySeen && inHead[tag]:
+				impliedHTML()
+				if !headSeen {
+					open("head", -1)
+					headSeen = true
+				}
+			default:
+				impliedBody()
+			}
+			n := startTagCloses(stack, token.Data)
+			closeTo(n, n, t.start, "start tag <%s> closes unclosed element <%s> at %s", token.Data)
+			// Rows and cells outside of their table sections imply the missing elements like the parser does.
+			switch token.Data {
+			case "tr":
+				if current() == "table" {
+					open("tbody", -1)
+				}
+			case "td", "th":
+				if current() == "table" {
+					open("tbody", -1)
+				}
+				if tag := current(); tag == "tbody" || tag == "thead" || tag == "tfoot" {
+					open("tr", -1)
+				}
+			case "col":
+				if current() == "table" {
+					open("colgroup", -1)
+				}
+			}
+			node := open(token.Data, t.start)
+			if token.Type == html.SelfClosingTagToken && node.Namespace == "" && !isVoidElement(token.Data) {
+				report(ErrorCodeNonVoidSelfClosing, t.start, "self-closing syntax on non-void element <%s>", token.Data)
+			}
+			// Self-closing foreign elements are complete, self-closing html elements stay open.
+			if isVoidElement(token.Data) || (token.Type == html.SelfClosingTagToken && node.Namespace != "") {
+				stack = stack[:len(stack)-1]
+			}
+		case html.EndTagToken:
+			// The tokenizer drops the attributes of end tags, so they are scanned from the source.
+			if len(scanAttributes(t.raw[1:])) > 0 {
+				report(ErrorCodeEndTagWithAttributes, t.start, "end tag </%s> has attributes", token.Data)
+			}
+			format := "end tag </%s> closes unclosed element <%s> at %s"
+			switch tag := token.Data; {
+			case tag == "head" && !bodySeen:
+				// Before the body, </head> closes the open or implied head.
+				if current() == "head" {
+					stack = stack[:len(stack)-1]
+				} else if headSeen {
+					report(ErrorCodeStrayEndTag, t.start, "stray end tag </%s>", tag)
+				}
+				impliedHTML()
+				headSeen = true
+			case (tag == "body" || tag == "html") && inScope(stack, nil, "svg", "math") < 0:
+				// The body stays open after </body> and </html>, content that follows is still added to it.
+				impliedBody()
+				index := inScope(stack, defaultScope, "body")
+				if index < 0 || after == "html" || (after == "body" && tag == "body") {
+					report(ErrorCodeStrayEndTag, t.start, "stray end tag </%s>", tag)
+					continue
+				}
+				closeTo(index+1, index+1, t.start, format, tag)
+				after = tag
+			default:
+				index := endTagCloses(stack, tag)
+				if index < 0 {
+					report(ErrorCodeStrayEndTag, t.start, "stray end tag </%s>", tag)
+					continue
+				}
+				closeTo(index, index+1, t.start, format, tag)
+			}
+		}
+	}
+	for _, element := range stack {
+		if !element.optional() {
+			report(ErrorCodeUnclosedElement, element.offset, "element <%s> is never closed", element.node.Data)
+		}
+	}
+	return errs
+}
+
+// A checkedElement is an entry of the stack of open elements of checkSource.
+type checkedElement struct {
+	node *html.Node
+	// offset is the offset of the start tag, or -1 for elements implied by the parser.
+	offset int
+}
+
+// openNode returns the node of the element.
+func (e checkedElement) openNode() *html.Node {
+	return e.node
+}
+
+// optional returns a boolean value indicating whether the element may be closed implicitly.
+func (e checkedElement) optional() bool {
+	return e.offset < 0 || (e.node.Namespace == "" && slices.Contains(optionalEndTagElements, e.node.Data))
+}
@@ -0,0 +1,105 @@
+package goDOM_test
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/richi0/goDOM"
+)
+
+func TestParseErrors(t *testing.T) {
+	source := "<div id=a id=b>\n<b><i>text</b></i>\n<p>one<p>two</span>\n<span/><svg><rect/></svg></div class=x>\n<section>"
+	dom, err := goDOM.NewWithOptions(strings.NewReader(source), goDOM.ParseOptions{ReportErrors: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []struct {
+		code string
+		line int
+	}{
+		{goDOM.ErrorCodeDuplicateAttribute, 1},
+		{goDOM.ErrorCodeMisnestedTag, 2},
+		{goDOM.ErrorCodeStrayEndTag, 2},
+		{goDOM.ErrorCodeStrayEndTag, 3},
+		{goDOM.ErrorCodeNonVoidSelfClosing, 4},
+		{goDOM.ErrorCodeEndTagWithAttributes, 4},
+		{goDOM.ErrorCodeMisnestedTag, 4},
+		{goDOM.ErrorCodeUnclosedElement, 5},
+	}
+	errs := dom.ParseErrors()
+	if len(errs) != len(expected) {
+		t.Fatal("Expected", len(expected), "errors but found", len(errs), errs)
+	}
+	for i, e := range expected {
+		if errs[i].Code != e.code || errs[i].Position.Line != e.line {
+			t.Errorf("Expected error %d to be %s on line %d, got: %s %v", i, e.code, e.line, errs[i].Code, errs[i])
+		}
+	}
+	if len(dom.GetElementsByTagName("p")) != 2 {
+		t.Error("Expected the tree to be repaired")
+	}
+}
+
+func TestParseErrorsStrict(t *testing.T) {
+	_, err := goDOM.NewWithOptions(strings.NewReader("<div><b>bold</div>"), goDOM.ParseOptions{Strict: true})
+	var parseErrors goDOM.ParseErrors
+	if !errors.As(err, &parseErrors) {
+		t.Fatal("Expected ParseErrors but got", err)
+	}
+	if len(parseErrors) != 1 || err.Error() != "1:13: end tag </div> closes unclosed element <b> at 1:6" {
+		t.Error("Unexpected errors:", err)
+	}
+	dom, err := goDOM.NewWithOptions(strings.NewReader("<ul><li>one<li>two</ul><p>text<br></p><img src=x>"), goDOM.ParseOptions{Strict: true})
+	if err != nil {
+		t.Fatal("Expected no errors but got", err)
+	}
+	if len(dom.ParseErrors()) != 0 {
+		t.Error("Expected no errors but found", dom.ParseErrors())
+	}
+}
+
+func TestParseErrorsImpliedElements(t *testing.T) {
+	valid := []string{
+		"<!DOCTYPE html><title>x</title><p>a</body></html>",
+		"<table><tr><td>a</td></tr></tbody></table>",
+		"<table><td>a</td></tr></tbody></table>",
+		"<title>x</title></head><p>a",
+		"<dl><dt>a<dd>b</dl><select><option>a<option>b</select>",
+		"<svg><title>t</title><rect/></svg><math><mi>x</mi></math>",
+	}
+	for _, source := range valid {
+		if _, err := goDOM.NewWithOptions(strings.NewReader(source), goDOM.ParseOptions{Strict: true}); err != nil {
+			t.Errorf("Expected no errors for %q but got %v", source, err)
+		}
+	}
+	invalid := map[string]string{
+		"<p>one<div>two</div></p>":                "1:21: stray end tag </p>",
+		"<p>a</body></html></html>":               "1:19: stray end tag </html>",
+		"<p>a</body></body>":                      "1:12: stray end tag </body>",
+		"<div><span>a</body>":                     "1:13: end tag </body> closes unclosed element <div> at 1:1\n1:13: end tag </body> closes unclosed element <span> at 1:6",
+		"<table><tr><td>a</td></tr></li></table>": "1:27: stray end tag </li>",
+		"<h1>a<h2>b</h2>":                         "1:6: start tag <h2> closes unclosed element <h1> at 1:1",
+	}
+	for source, expected := range invalid {
+		_, err := goDOM.NewWithOptions(strings.NewReader(source), goDOM.ParseOptions{Strict: true})
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected %q for %q but got %v", expected, source, err)
+		}
+	}
+}
+
+func TestParseErrorsInDocument(t *testing.T) {
+	source, err := os.ReadFile("test_data/index.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dom, err := goDOM.NewWithOptions(strings.NewReader(string(source)), goDOM.ParseOptions{ReportErrors: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dom.ParseErrors()) != 0 {
+		t.Error("Expected no errors in a valid document but found", dom.ParseErrors())
+	}
+}
@@ -0,0 +1,207 @@
+package goDOM
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/net/html"
+)
+
+// ErrPatchConflict is returned by ApplyPatch if the DOM does not match the base of the patch.
+var ErrPatchConflict = errors.New("goDOM: patch conflict")
+
+// A Patch is an edit script together with checksums of the tree it applies to and of the tree it produces.
+// Patches are created by NewPatch, serialized as JSON and applied with ApplyPatch.
+type Patch struct {
+	// Base is the checksum of the tree the patch applies to. If it is empty, ApplyPatch only checks
+	// the preconditions of the single edits.
+	Base string `json:"base,omitempty"`
+	// Target is the checksum of the tree after the patch is applied. If it is empty, the result is not checked.
+	Target string `json:"target,omitempty"`
+	// Edits are the edits of the patch in the order they are applied, see Edit.
+	Edits []Edit `json:"edits"`
+}
+
+// NewPatch returns the patch which turns a into b, see Diff.
+//
+// Nodes ignored by the options are kept as they are in a, so that applying the patch to a
+// only results in b if nothing was ignored.
+// This function is not part of the Javascript Document interface.
+func NewPatch(a, b *DOM, opts DiffOptions) (*Patch, error) {
+	d, err := diff(a.node, b.node, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Patch{Base: strictHash(a.node), Target: strictHash(d.root), Edits: d.edits}, nil
+}
+
+// ReadPatch returns the patch from its JSON representation from the given Reader.
+// This function is not part of the Javascript Document interface.
+func ReadPatch(r io.Reader) (*Patch, error) {
+	var p Patch
+	if err := json.NewDecoder(r).Decode(&p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// ApplyPatch applies the edits of the patch to the DOM and its descendants.
+//
+// An error wrapping ErrPatchConflict is returned if the DOM does not match the base of the patch:
+// if its checksum differs from Base, if a path of an edit does not exist, if a deleted or moved node
+// differs from the node of the edit, or if an updated text or attribute differs from the old value.
+// The patch is checked on a copy first, so the DOM is left unchanged if an error is returned.
+// This method is not part of the Javascript Document interface.
+func (d *DOM) ApplyPatch(p *Patch) error {
+	if p.Base != "" && strictHash(d.node) != p.Base {
+		return fmt.Errorf("%w: the DOM does not match the base of the patch", ErrPatchConflict)
+	}
+	trial := cloneDeep(d.node)
+	if err := applyEdits(trial, p.Edits); err != nil {
+		return err
+	}
+	if p.Target != "" && strictHash(trial) != p.Target {
+		return fmt.Errorf("%w: the result does not match the target of the patch", ErrPatchConflict)
+	}
+	applyEdits(d.node, p.Edits)
+	d.doc.invalidateCaches()
+	return nil
+}
+
+// applyEdits applies the edits to the tree with the given root.
+func applyEdits(root *html.Node, edits []Edit) error {
+	for i, e := range edits {
+		if err := applyEdit(root, e); err != nil {
+			return fmt.Errorf("%w: edit %d: %s", ErrPatchConflict, i, err.Error())
+		}
+	}
+	return nil
+}
+
+// applyEdit applies a single edit to the tree with the given root. The returned errors describe the conflict.
+func applyEdit(root *html.Node, e Edit) error {
+	switch e.Op {
+	case EditInsert:
+		if e.Node == nil || e.Node.Type == "document" || e.Node.Type == "fragment" {
+			return errors.New("insert without a valid node")
+		}
+		node, _, err := fromJSONNode(e.Node)
+		if err != nil {
+			return err
+		}
+		return insertAtPath(root, node, e.Path)
+	case EditDelete:
+		node, err := resolvePath(root, e.Path)
+		if err != nil {
+			return err
+		}
+		if node == root {
+			return errors.New("cannot delete the root")
+		}
+		if err := checkHash(node, e); err != nil {
+			return err
+		}
+		node.Parent.RemoveChild(node)
+	case EditMove:
+		node, err := resolvePath(root, e.From)
+		if err != nil {
+			return err
+		}
+		if node == root {
+			return errors.New("cannot move the root")
+		}
+		if err := checkHash(node, e); err != nil {
+			return err
+		}
+		parent, next := node.Parent, node.NextSibling
+		parent.RemoveChild(node)
+		if err := insertAtPath(root, node, e.Path); err != nil {
+			parent.InsertBefore(node, next)
+			return err
+		}
+	case EditUpdateText:
+		node, err := resolvePath(root, e.Path)
+		if err != nil {
+			return err
+		}
+		if node.Type != html.TextNode && node.Type != html.CommentNode && node.Type != html.RawNode {
+			return fmt.Errorf("%s is not a text node", e.Location)
+		}
+		if e.Old != nil && node.Data != *e.Old {
+			return fmt.Errorf("text of %s is %q, expected %q", e.Location, node.Data, *e.Old)
+		}
+		node.Data = deref(e.New)
+	case EditUpdateAttribute:
+		node, err := resolvePath(root, e.Path)
+		if err != nil {
+			return err
+		}
+		if node.Type != html.ElementNode && node.Type != html.DoctypeNode {
+			return fmt.Errorf("%s cannot have attributes", e.Location)
+		}
+		var current *string
+		for _, attr := range node.Attr {
+			if attr.Namespace == e.Namespace && attr.Key == e.Name {
+				current = &attr.Val
+				break
+			}
+		}
+		if (current == nil) != (e.Old == nil) || (current != nil && *current != *e.Old) {
+			return fmt.Errorf("attribute %s of %s is %q, expected %q", e.Name, e.Location, deref(current), deref(e.Old))
+		}
+		switch {
+		case e.New == nil:
+			removeAttribute(node, e.Namespace, e.Name)
+		case current == nil:
+			insertAttribute(node, html.Attribute{Namespace: e.Namespace, Key: e.Name, Val: *e.New}, e.Index)
+		default:
+			setAttribute(node, html.Attribute{Namespace: e.Namespace, Key: e.Name, Val: *e.New})
+		}
+	default:
+		return fmt.Errorf("invalid operation %q", e.Op)
+	}
+	return nil
+}
+
+// resolvePath returns the node at the path of child indices from the root.
+func resolvePath(root *html.Node, path []int) (*html.Node, error) {
+	node := root
+	for _, index := range path {
+		child := childAt(node, index)
+		if index < 0 || child == nil {
+			return nil, fmt.Errorf("path %v does not exist", path)
+		}
+		node = child
+	}
+	return node, nil
+}
+
+// insertAtPath inserts the node so that it is at the path afterwards.
+func insertAtPath(root, node *html.Node, path []int) error {
+	if len(path) == 0 {
+		return errors.New("cannot insert at the root")
+	}
+	parent, err := resolvePath(root, path[:len(path)-1])
+	if err != nil {
+		return err
+	}
+	if parent.Type != html.ElementNode && parent.Type != html.DocumentNode {
+		return fmt.Errorf("path %v cannot have children", path)
+	}
+	index := path[len(path)-1]
+	if index < 0 || index > nodeLength(parent) {
+		return fmt.Errorf("path %v does not exist", path)
+	}
+	parent.InsertBefore(node, childAt(parent, index))
+	return nil
+}
+
+// checkHash compares the node with the checksum of the edit, if there is one.
+func checkHash(node *html.Node, e Edit) error {
+	if e.Hash != "" && strictHash(node) != e.Hash {
+		return fmt.Errorf("node %s has changed", e.Location)
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package goDOM_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/richi0/goDOM"
+)
+
+func applyPatch(t *testing.T, a, b *goDOM.DOM) {
+	t.Helper()
+	patch, err := goDOM.NewPatch(a, b, goDOM.DiffOptions{})
+	if err != nil {
+		t.Fatal("Unexpected patch error:", err)
+	}
+	if err := a.ApplyPatch(patch); err != nil {
+		t.Fatal("Unexpected apply error:", err)
+	}
+	expected, _ := b.Render()
+	if rendered, _ := a.Render(); rendered != expected {
+		t.Errorf("Expected\n%s\nbut got\n%s", expected, rendered)
+	}
+}
+
+func TestApplyPatch(t *testing.T) {
+	tests := [][2]string{
+		{`<p>Hello</p>`, `<p>World</p>`},
+		{`<a href="/a" title="t">x</a>`, `<a href="/b" rel="next">x</a>`},
+		{`<ul><li>a</li><li>b</li><li>c</li></ul>`, `<ul><li>c</li><li>a</li></ul><p>b</p>`},
+		{`<div id="x"><p>one</p><p>two</p></div><div id="y"></div>`, `<div id="y"><p>two</p></div><div id="x"><p>one!</p></div>`},
+		{`<p>a</p><p>b</p>`, `<div><p>a</p><p>b</p></div>`},
+		{`<div><p>a</p><p>b</p></div>`, `<p>b</p><!--c--><p>a</p>`},
+		{`<table><tr><td>1<td>2</table>`, `<table><tr><td>2<td>1<td>3</table>`},
+		{`<li class="c2">x</li>`, `<li id="i1" class="c2">x</li>`},
+		{`<a href="/a" title="t">x</a>`, `<a rel="r" href="/a" lang="en" title="t" id="i">x</a>`},
+		{`<p id=x class=a title=t>x</p>`, `<p id=x title=u data-z=1 class=a>x</p>`},
+		{`<p a=1 b=2 c=3 d=4>x</p>`, `<p d=4 c=3 b=2 a=1>x</p>`},
+	}
+	for _, test := range tests {
+		applyPatch(t, createDOMFromString(test[0]), createDOMFromString(test[1]))
+	}
+}
+
+func TestApplyPatchDocument(t *testing.T) {
+	source, err := os.ReadFile("test_data/index.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	modified := strings.Replace(string(source), "</h2>", " (changed)</h2>", 3)
+	modified = strings.Replace(modified, "<p>", "<p>New paragraph.</p><p>", 2)
+	modified = strings.Replace(modified, "</ul>", "<li>Added</li></ul>", 1)
+	applyPatch(t, createTestDOM(), createDOMFromString(modified))
+}
+
+func TestPatchJSON(t *testing.T) {
+	a := createDOMFromString(`<ul><li>a</li><li>b</li></ul>`)
+	b := createDOMFromString(`<ul><li>b</li><li class="x">a</li><li>c</li></ul>`)
+	patch, err := goDOM.NewPatch(a, b, goDOM.DiffOptions{})
+	if err != nil {
+		t.Fatal("Unexpected patch error:", err)
+	}
+	data, err := json.Marshal(patch)
+	if err != nil {
+		t.Fatal("Unexpected marshal error:", err)
+	}
+	read, err := goDOM.ReadPatch(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal("Unexpected read error:", err)
+	}
+	if err := a.ApplyPatch(read); err != nil {
+		t.Fatal("Unexpected apply error:", err)
+	}
+	expected, _ := b.Render()
+	if rendered, _ := a.Render(); rendered != expected {
+		t.Errorf("Expected\n%s\nbut got\n%s", expected, rendered)
+	}
+}
+
+func TestApplyPatchConflict(t *testing.T) {
+	a := createDOMFromString(`<p id="a">one</p><p id="b">two</p>`)
+	b := createDOMFromString(`<p id="a">one</p><p id="b">three</p>`)
+	patch, err := goDOM.NewPatch(a, b, goDOM.DiffOptions{})
+	if err != nil {
+		t.Fatal("Unexpected patch error:", err)
+	}
+
+	changed := createDOMFromString(`<p id="a" class="x">one</p><p id="b">two</p>`)
+	before, _ := changed.Render()
+	if err := changed.ApplyPatch(patch); !errors.Is(err, goDOM.ErrPatchConflict) {
+		t.Error("Expected a conflict for a different base but got", err)
+	}
+	if after, _ := changed.Render(); after != before {
+		t.Error("Expected the DOM to be unchanged after a conflict")
+	}
+
+	// Without the checksums, only the edited nodes have to match.
+	patch.Base, patch.Target = "", ""
+	if err := changed.ApplyPatch(patch); err != nil {
+		t.Error("Unexpected apply error:", err)
+	}
+	if rendered, _ := changed.Render(); !strings.Contains(rendered, `<p id="a" class="x">one</p><p id="b">three</p>`) {
+		t.Error("Unexpected result:", rendered)
+	}
+	conflicting := createDOMFromString(`<p id="a">one</p><p id="b">other</p>`)
+	if err := conflicting.ApplyPatch(patch); !errors.Is(err, goDOM.ErrPatchConflict) {
+		t.Error("Expected a conflict for a changed text but got", err)
+	}
+	missing := createDOMFromString(`<p id="a">one</p>`)
+	if err := missing.ApplyPatch(patch); !errors.Is(err, goDOM.ErrPatchConflict) {
+		t.Error("Expected a conflict for a missing node but got", err)
+	}
+
+	// The target checksum includes the order of the attributes.
+	reordered, err := goDOM.NewPatch(createDOMFromString(`<p a=1 b=2>x</p>`), createDOMFromString(`<p b=2 a=1>x</p>`), goDOM.DiffOptions{})
+	if err != nil {
+		t.Fatal("Unexpected patch error:", err)
+	}
+	reordered.Edits = nil
+	if err := createDOMFromString(`<p a=1 b=2>x</p>`).ApplyPatch(reordered); !errors.Is(err, goDOM.ErrPatchConflict) {
+		t.Error("Expected a conflict for a different attribute order but got", err)
+	}
+}
+
+func TestNewPatchOptions(t *testing.T) {
+	a := createDOMFromString("<div>\n  <p>Hello</p>\n</div>")
+	b := createDOMFromString(`<div><p>Hello</p><p>World</p></div>`)
+	opts := goDOM.DiffOptions{IgnoreWhitespace: true}
+	patch, err := goDOM.NewPatch(a, b, opts)
+	if err != nil {
+		t.Fatal("Unexpected patch error:", err)
+	}
+	if err := a.ApplyPatch(patch); err != nil {
+		t.Fatal("Unexpected apply error:", err)
+	}
+	if script, _ := goDOM.Diff(a, b, opts); len(script.Edits) != 0 {
+		t.Errorf("Expected no differences but got\n%s", script)
+	}
+}
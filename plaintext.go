@@ -0,0 +1,87 @@
+package goDOM
+
+import (
+	"io"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var (
+	blankLinePattern = regexp.MustCompile(`\n[ \t]*\n\s*`)
+	urlPattern       = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s<>"]+`)
+)
+
+// FromPlainText returns the HTML for the plain text from the given Reader as a fragment.
+//
+// Paragraphs separated by blank lines become <p> elements and line breaks within a paragraph become
+// <br> elements. URLs starting with http://, https:// or www. become links. Trailing punctuation and
+// unbalanced closing parentheses are not part of a link.
+// This function is not part of the Javascript Document interface.
+func FromPlainText(r io.Reader) (*DOM, error) {
+	source, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	text := strings.TrimSpace(lineEndingPattern.ReplaceAllString(string(source), "\n"))
+	fragment := &html.Node{Type: html.DocumentNode}
+	if text == "" {
+		return &DOM{node: fragment, doc: &document{encoding: "utf-8"}, fragment: true}, nil
+	}
+	for _, paragraph := range blankLinePattern.Split(text, -1) {
+		p := &html.Node{Type: html.ElementNode, Data: "p", DataAtom: atom.P}
+		for i, line := range strings.Split(paragraph, "\n") {
+			if i > 0 {
+				p.AppendChild(&html.Node{Type: html.ElementNode, Data: "br", DataAtom: atom.Br})
+			}
+			appendLinkedText(p, line)
+		}
+		fragment.AppendChild(p)
+	}
+	return &DOM{node: fragment, doc: &document{encoding: "utf-8"}, fragment: true}, nil
+}
+
+// appendLinkedText appends the text to the node, with the URLs in the text as <a> elements.
+func appendLinkedText(node *html.Node, text string) {
+	start := 0
+	for _, match := range urlPattern.FindAllStringIndex(text, -1) {
+		if match[0] < start {
+			continue
+		}
+		url := trimURL(text[match[0]:match[1]])
+		if strings.HasSuffix(strings.ToLower(url), "://") || strings.EqualFold(url, "www.") {
+			continue
+		}
+		if match[0] > start {
+			node.AppendChild(&html.Node{Type: html.TextNode, Data: text[start:match[0]]})
+		}
+		href := url
+		if strings.HasPrefix(strings.ToLower(url), "www.") {
+			href = "http://" + url
+		}
+		a := &html.Node{Type: html.ElementNode, Data: "a", DataAtom: atom.A, Attr: []html.Attribute{{Key: "href", Val: href}}}
+		a.AppendChild(&html.Node{Type: html.TextNode, Data: url})
+		node.AppendChild(a)
+		start = match[0] + len(url)
+	}
+	if start < len(text) {
+		node.AppendChild(&html.Node{Type: html.TextNode, Data: text[start:]})
+	}
+}
+
+// trimURL removes trailing punctuation and unbalanced closing parentheses from an URL found in text.
+func trimURL(url string) string {
+	for url != "" {
+		last := url[len(url)-1]
+		if strings.IndexByte(".,:;!?'*_~", last) >= 0 {
+			url = url[:len(url)-1]
+		} else if last == ')' && strings.Count(url, ")") > strings.Count(url, "(") {
+			url = url[:len(url)-1]
+		} else {
+			break
+		}
+	}
+	return url
+}
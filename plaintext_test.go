@@ -0,0 +1,32 @@
+package goDOM_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/richi0/goDOM"
+)
+
+func TestFromPlainText(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{"", ""},
+		{"a <b>\r\nline\n\n \n\nnext", "<p>a &lt;b&gt;<br/>line</p><p>next</p>"},
+		{"See https://example.com/a?b=1&c=2.", `<p>See <a href="https://example.com/a?b=1&amp;c=2">https://example.com/a?b=1&amp;c=2</a>.</p>`},
+		{"(www.example.com/wiki/A_(b)) and http://", `<p>(<a href="http://www.example.com/wiki/A_(b)">www.example.com/wiki/A_(b)</a>) and http://</p>`},
+	}
+	for _, test := range tests {
+		dom, err := goDOM.FromPlainText(strings.NewReader(test.text))
+		if err != nil {
+			t.Fatal("Unexpected error:", err)
+		}
+		if rendered, _ := dom.Render(); rendered != test.expected {
+			t.Errorf("%q: expected\n%s\nbut got\n%s", test.text, test.expected, rendered)
+		}
+		if dom.TagName() != "fragment" {
+			t.Errorf("%q: expected a fragment but got %q", test.text, dom.TagName())
+		}
+	}
+}
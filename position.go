@@ -0,0 +1,463 @@
+package goDOM
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// A Position describes a location in the source of a document.
+type Position struct {
+	// Offset is the byte offset, starting at 0.
+	Offset int `json:"offset"`
+	// Line is the line number, starting at 1.
+	Line int `json:"line"`
+	// Column is the column number, starting at 1 (byte count).
+	Column int `json:"column"`
+}
+
+// String returns the position in the form "line:column".
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// A Span describes a part of the source of a document. End is the position after the last byte.
+type Span struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// SourcePosition describes where a node was found in the source of a document.
+type SourcePosition struct {
+	// StartTag is the span of the start tag of an element or of the whole node for other node types.
+	StartTag Span `json:"startTag"`
+	// EndTag is the span of the end tag of an element. It is the zero Span if the element has no end tag.
+	EndTag Span `json:"endTag"`
+	// Attributes contains the span of every attribute of an element, from the start of the name
+	// to the end of the value, keyed by the attribute name.
+	Attributes map[string]Span `json:"attributes,omitempty"`
+}
+
+// SourcePosition returns the position of the node in the source of the document, or nil if it is unknown.
+//
+// Positions are only recorded if the document was parsed by NewWithOptions with TrackPositions set.
+// Elements implied by the parser, like a missing <body>, have no position. Offsets refer to the input
+// after it was transcoded to UTF-8.
+// This method is not part of the Javascript Document interface.
+func (d *DOM) SourcePosition() *SourcePosition {
+	if d.doc == nil || d.doc.positions == nil {
+		return nil
+	}
+	return d.doc.positions[d.node]
+}
+
+// positionMarker is the name of the attribute which links start tags to the elements created by the parser.
+const positionMarker = "data-godom-source-position"
+
+// positionComment is the prefix of the comment which links start tags of formatting elements to the elements
+// created by the parser.
+const positionComment = "godom-source-position:"
+
+// sourceToken is a token of the source together with its location.
+type sourceToken struct {
+	token html.Token
+	start int
+	end   int
+	raw   []byte
+	// formatting is set for start tags of HTML formatting elements, like <b>.
+	formatting bool
+}
+
+// parseWithPositions parses the source and records the position of every node.
+//
+// The tree builder of golang.org/x/net/html does not report positions, so the source is tokenized first
+// like the parser tokenizes it and every start tag is annotated with a marker attribute holding the index
+// of its token. The marker is inserted right after the tag name: the tokenizer leaves the tag name and a
+// quoted attribute value on the same characters, so the rest of the tag is read exactly as in the source.
+// The parser compares the attributes of formatting elements like <b> to limit how many identical ones it
+// reopens, so these are marked by a comment right after the start tag instead, which becomes their first
+// child. The marked source is parsed, the markers link the elements back to their tokens and are removed.
+// End tags, comments and text are assigned to the nodes in order.
+func parseWithPositions(source io.Reader, scripting bool) (*html.Node, map[*html.Node]*SourcePosition, error) {
+	input, err := io.ReadAll(source)
+	if err != nil {
+		return nil, nil, err
+	}
+	tokens := tokenizeSource(input, scripting)
+	var marked bytes.Buffer
+	for i, t := range tokens {
+		raw := t.raw
+		if (t.token.Type != html.StartTagToken && t.token.Type != html.SelfClosingTagToken) || !bytes.HasSuffix(raw, []byte(">")) {
+			marked.Write(raw)
+			continue
+		}
+		if t.formatting {
+			marked.Write(raw)
+			fmt.Fprintf(&marked, "<!--%s%d-->", positionComment, i)
+			continue
+		}
+		end := 1
+		for end < len(raw) && !isSpace(raw[end]) && raw[end] != '/' && raw[end] != '>' {
+			end++
+		}
+		marked.Write(raw[:end])
+		fmt.Fprintf(&marked, ` %s="%d"`, positionMarker, i)
+		marked.Write(raw[end:])
+	}
+	node, err := html.ParseWithOptions(&marked, html.ParseOptionEnableScripting(scripting))
+	if err != nil {
+		return nil, nil, err
+	}
+	lines := newLineIndex(input)
+	positions := make(map[*html.Node]*SourcePosition)
+	elements := make(map[int]*html.Node)
+	record := func(n *html.Node, marker string) {
+		index, err := strconv.Atoi(marker)
+		if err != nil || index >= len(tokens) {
+			return
+		}
+		if _, ok := elements[index]; ok {
+			return
+		}
+		t := tokens[index]
+		positions[n] = &SourcePosition{
+			StartTag:   lines.span(t.start, t.end),
+			Attributes: attributeSpans(t, lines),
+		}
+		elements[index] = n
+	}
+	markers := make([]*html.Node, 0)
+	for _, n := range preOrder(node) {
+		switch n.Type {
+		case html.ElementNode:
+			for i, attr := range n.Attr {
+				if attr.Key == positionMarker {
+					n.Attr = append(n.Attr[:i], n.Attr[i+1:]...)
+					record(n, attr.Val)
+					break
+				}
+			}
+		case html.CommentNode:
+			if marker, ok := strings.CutPrefix(n.Data, positionComment); ok {
+				markers = append(markers, n)
+				// The comment belongs to the element if the start tag was not ignored.
+				if index, err := strconv.Atoi(marker); err == nil && index < len(tokens) &&
+					n.Parent.Type == html.ElementNode && n.Parent.Data == tokens[index].token.Data && n.PrevSibling == nil {
+					record(n.Parent, marker)
+				}
+			}
+		}
+	}
+	for _, marker := range markers {
+		removeMarker(marker)
+	}
+	comments := make([]*html.Node, 0)
+	texts := make([]*html.Node, 0)
+	for _, n := range preOrder(node) {
+		switch n.Type {
+		case html.CommentNode, html.DoctypeNode:
+			comments = append(comments, n)
+		case html.TextNode:
+			texts = append(texts, n)
+		}
+	}
+	assignEndTags(tokens, elements, positions, lines)
+	assignComments(tokens, comments, positions, lines)
+	assignTexts(tokens, texts, positions, lines)
+	return node, positions, nil
+}
+
+// removeMarker removes a marker comment and merges the text nodes it separated.
+func removeMarker(marker *html.Node) {
+	previous, next := marker.PrevSibling, marker.NextSibling
+	marker.Parent.RemoveChild(marker)
+	if previous != nil && next != nil && previous.Type == html.TextNode && next.Type == html.TextNode {
+		previous.Data += next.Data
+		next.Parent.RemoveChild(next)
+	}
+}
+
+// tokenizeSource splits the input into tokens and records their byte offsets.
+//
+// Like the tree builder of golang.org/x/net/html, the tokenizer is told where CDATA sections are allowed
+// and where start tags like <title> or <noscript> do not start raw text, so that it returns the tokens the
+// parser reads. The open elements are tracked with the implied end tags, like in Stream.
+func tokenizeSource(input []byte, scripting bool) []sourceToken {
+	tokens := make([]sourceToken, 0)
+	z := html.NewTokenizer(bytes.NewReader(input))
+	stack := make([]checkedElement, 0)
+	offset := 0
+	for {
+		// The parser allows CDATA sections if the current node is foreign.
+		z.AllowCDATA(len(stack) > 0 && stack[len(stack)-1].node.Namespace != "")
+		tokenType := z.Next()
+		raw := z.Raw()
+		if tokenType == html.ErrorToken {
+			if len(raw) > 0 {
+				tokens = append(tokens, sourceToken{html.Token{Type: html.ErrorToken}, offset, offset + len(raw), raw, false})
+			}
+			return tokens
+		}
+		t := sourceToken{z.Token(), offset, offset + len(raw), input[offset : offset+len(raw)], false}
+		offset += len(raw)
+		switch tokenType {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			stack, t.formatting = openSourceElement(z, stack, t.token, scripting)
+		case html.EndTagToken:
+			if index := endTagCloses(stack, t.token.Data); index >= 0 {
+				stack = stack[:index]
+			}
+		}
+		tokens = append(tokens, t)
+	}
+}
+
+// ignoredInSelect are the start tags which the parser ignores in a <select> element without reading their
+// content as raw text.
+var ignoredInSelect = map[string]bool{
+	"iframe": true, "noembed": true, "noframes": true, "noscript": true, "plaintext": true, "style": true,
+	"title": true, "xmp": true,
+}
+
+// openSourceElement updates the stack of open elements of tokenizeSource for a start tag and switches
+// the tokenizer out of raw text mode where the parser does. It returns whether the start tag creates
+// an HTML formatting element.
+func openSourceElement(z *html.Tokenizer, stack []checkedElement, token html.Token, scripting bool) ([]checkedElement, bool) {
+	tag := token.Data
+	if selectOpen(stack) {
+		switch tag {
+		case "option", "optgroup", "script", "template":
+		case "select", "input", "keygen", "textarea":
+			// These close the select element, only the nested <select> is not opened.
+			for len(stack) > 0 && stack[len(stack)-1].node.Data != "select" {
+				stack = stack[:len(stack)-1]
+			}
+			stack = stack[:max(len(stack)-1, 0)]
+			if tag == "select" {
+				return stack, false
+			}
+		default:
+			if ignoredInSelect[tag] {
+				z.NextIsNotRawText()
+			}
+			return stack, false
+		}
+	}
+	stack = stack[:startTagCloses(stack, tag)]
+	if len(stack) >= maxOpenElements {
+		stack = stack[:len(stack)-1]
+	}
+	var parent *html.Node
+	if len(stack) > 0 {
+		parent = stack[len(stack)-1].node
+	}
+	node := &html.Node{Type: html.ElementNode, Data: tag, Namespace: tokenNamespace(parent, tag)}
+	if node.Namespace != "" || (tag == "noscript" && !scripting) {
+		z.NextIsNotRawText()
+	}
+	// Like the parser, only foreign elements are closed by the self-closing flag.
+	if !isVoidElement(tag) && (token.Type == html.StartTagToken || node.Namespace == "") {
+		stack = append(stack, checkedElement{node, 0})
+	}
+	return stack, node.Namespace == "" && formattingElements[tag]
+}
+
+// selectOpen returns a boolean value indicating whether the current node is a <select> element or an option in it.
+func selectOpen(stack []checkedElement) bool {
+	for i := len(stack) - 1; i >= 0; i-- {
+		node := stack[i].node
+		if node.Namespace != "" {
+			return false
+		}
+		switch node.Data {
+		case "option", "optgroup":
+			continue
+		case "select":
+			return true
+		}
+		return false
+	}
+	return false
+}
+
+// assignEndTags links end tags to the elements they close by matching them with the start tags of the same name.
+// The self-closing flag only closes foreign elements, HTML elements like <a/> stay open.
+func assignEndTags(tokens []sourceToken, elements map[int]*html.Node, positions map[*html.Node]*SourcePosition, lines lineIndex) {
+	open := make(map[string][]*html.Node)
+	for i, t := range tokens {
+		switch t.token.Type {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			element, ok := elements[i]
+			if ok && !isVoidElement(t.token.Data) && (t.token.Type == html.StartTagToken || element.Namespace == "") {
+				open[t.token.Data] = append(open[t.token.Data], element)
+			}
+		case html.EndTagToken:
+			stack := open[t.token.Data]
+			if len(stack) == 0 {
+				continue
+			}
+			element := stack[len(stack)-1]
+			open[t.token.Data] = stack[:len(stack)-1]
+			positions[element].EndTag = lines.span(t.start, t.end)
+		}
+	}
+}
+
+// assignComments links comment and doctype tokens to the nodes in document order.
+func assignComments(tokens []sourceToken, nodes []*html.Node, positions map[*html.Node]*SourcePosition, lines lineIndex) {
+	i := 0
+	for _, t := range tokens {
+		if i == len(nodes) {
+			return
+		}
+		if t.token.Type != html.CommentToken && t.token.Type != html.DoctypeToken {
+			continue
+		}
+		if (t.token.Type == html.CommentToken) == (nodes[i].Type == html.CommentNode) {
+			positions[nodes[i]] = &SourcePosition{StartTag: lines.span(t.start, t.end)}
+			i++
+		}
+	}
+}
+
+// assignTexts links text tokens to the text nodes in document order.
+//
+// The parser merges adjacent text tokens, drops some whitespace and moves misplaced text, so every
+// text node is matched with the next text token whose content fits, looking ahead a limited number of tokens.
+func assignTexts(tokens []sourceToken, nodes []*html.Node, positions map[*html.Node]*SourcePosition, lines lineIndex) {
+	const lookahead = 32
+	texts := make([]sourceToken, 0)
+	for _, t := range tokens {
+		if t.token.Type == html.TextToken {
+			texts = append(texts, t)
+		}
+	}
+	cursor := 0
+	for _, node := range nodes {
+		data := node.Data
+		for k := cursor; k < len(texts) && k < cursor+lookahead; k++ {
+			t := texts[k]
+			start := t.start
+			if !strings.HasPrefix(t.token.Data, data) && !strings.HasPrefix(data, t.token.Data) {
+				if !strings.HasSuffix(t.token.Data, data) {
+					continue
+				}
+				// Leading whitespace of the token was dropped by the parser.
+				start = t.end - min(len(data), t.end-t.start)
+			}
+			length := len(t.token.Data)
+			j := k
+			for length < len(data) && j+1 < len(texts) {
+				j++
+				length += len(texts[j].token.Data)
+			}
+			positions[node] = &SourcePosition{StartTag: lines.span(start, texts[j].end)}
+			cursor = j + 1
+			break
+		}
+	}
+}
+
+// attributeSpans returns the spans of the attributes of a start tag token.
+func attributeSpans(t sourceToken, lines lineIndex) map[string]Span {
+	spans := make(map[string]Span)
+	for _, attr := range scanAttributes(t.raw) {
+		key := strings.ToLower(string(t.raw[attr[0]:attr[1]]))
+		if _, ok := spans[key]; !ok && key != positionMarker {
+			spans[key] = lines.span(t.start+attr[0], t.start+attr[2])
+		}
+	}
+	return spans
+}
+
+// scanAttributes returns the start and end of the name and the end of every attribute in a raw start tag.
+// It follows the attribute states of the tokenizer of golang.org/x/net/html.
+func scanAttributes(raw []byte) [][3]int {
+	attrs := make([][3]int, 0)
+	i := 1
+	for i < len(raw) && !isSpace(raw[i]) && raw[i] != '/' && raw[i] != '>' {
+		i++
+	}
+	skipSpace := func() {
+		for i < len(raw) && isSpace(raw[i]) {
+			i++
+		}
+	}
+	skipSpace()
+	for i < len(raw) && raw[i] != '>' {
+		keyStart := i
+		for i < len(raw) {
+			c := raw[i]
+			if (c == '=' && i > keyStart) || isSpace(c) || c == '/' || c == '>' {
+				break
+			}
+			i++
+		}
+		keyEnd, end := i, i
+		if keyEnd == keyStart {
+			i++
+		}
+		skipSpace()
+		if i < len(raw) && raw[i] == '=' && keyEnd > keyStart {
+			i++
+			skipSpace()
+			if i < len(raw) && (raw[i] == '"' || raw[i] == '\'') {
+				quote := raw[i]
+				i++
+				for i < len(raw) && raw[i] != quote {
+					i++
+				}
+				i = min(i+1, len(raw))
+			} else {
+				for i < len(raw) && !isSpace(raw[i]) && raw[i] != '>' {
+					i++
+				}
+			}
+			end = i
+		}
+		if keyEnd > keyStart {
+			attrs = append(attrs, [3]int{keyStart, keyEnd, end})
+		}
+		skipSpace()
+	}
+	return attrs
+}
+
+// lineIndex converts byte offsets to positions.
+type lineIndex []int
+
+// newLineIndex returns the offsets of the line starts of the input.
+func newLineIndex(input []byte) lineIndex {
+	lines := lineIndex{0}
+	for i, c := range input {
+		if c == '\n' {
+			lines = append(lines, i+1)
+		}
+	}
+	return lines
+}
+
+// position returns the position of the byte offset.
+func (l lineIndex) position(offset int) Position {
+	line := sort.Search(len(l), func(i int) bool { return l[i] > offset }) - 1
+	return Position{Offset: offset, Line: line + 1, Column: offset - l[line] + 1}
+}
+
+// span returns the span between the byte offsets.
+func (l lineIndex) span(start, end int) Span {
+	return Span{l.position(start), l.position(end)}
+}
+
+// isVoidElement returns a boolean value indicating whether the element never has an end tag.
+func isVoidElement(tag string) bool {
+	switch tag {
+	case "area", "base", "br", "col", "embed", "hr", "img", "input", "keygen", "link", "meta", "param", "source", "track", "wbr":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+package goDOM_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/richi0/goDOM"
+)
+
+func TestSourcePosition(t *testing.T) {
+	source := "<!DOCTYPE html>\n<html><body>\n  <div id=\"a\" class='x'>Hello <b>world</b></div>\n  <!-- note --><br/>\n<p>one<p>two\n</body></html>"
+	dom, err := goDOM.NewWithOptions(strings.NewReader(source), goDOM.ParseOptions{TrackPositions: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	div := dom.GetElementById("a")
+	if div.HasAttribute("data-godom-source-position") {
+		t.Error("Expected marker attributes to be removed")
+	}
+	position := div.SourcePosition()
+	if position == nil {
+		t.Fatal("Expected div to have a source position")
+	}
+	if position.StartTag.Start != (goDOM.Position{Offset: 31, Line: 3, Column: 3}) {
+		t.Error("Unexpected start tag position:", position.StartTag.Start)
+	}
+	if source[position.StartTag.Start.Offset:position.StartTag.End.Offset] != `<div id="a" class='x'>` {
+		t.Error("Unexpected start tag span:", position.StartTag)
+	}
+	if source[position.EndTag.Start.Offset:position.EndTag.End.Offset] != "</div>" {
+		t.Error("Unexpected end tag span:", position.EndTag)
+	}
+	class := position.Attributes["class"]
+	if source[class.Start.Offset:class.End.Offset] != "class='x'" || class.Start.Column != 15 {
+		t.Error("Unexpected attribute span:", class)
+	}
+	text := div.FirstChild().SourcePosition()
+	if text == nil || source[text.StartTag.Start.Offset:text.StartTag.End.Offset] != "Hello " {
+		t.Error("Unexpected text position:", text)
+	}
+	comment := div.NextSibling().NextSibling().SourcePosition()
+	if comment == nil || source[comment.StartTag.Start.Offset:comment.StartTag.End.Offset] != "<!-- note -->" {
+		t.Error("Unexpected comment position:", comment)
+	}
+	br := dom.GetElementsByTagName("br")[0].SourcePosition()
+	if br == nil || br.StartTag.Start.Line != 4 || br.EndTag != (goDOM.Span{}) {
+		t.Error("Unexpected br position:", br)
+	}
+	paragraphs := dom.GetElementsByTagName("p")
+	if paragraphs[1].SourcePosition().StartTag.Start.Line != 5 || paragraphs[0].SourcePosition().EndTag != (goDOM.Span{}) {
+		t.Error("Unexpected paragraph positions")
+	}
+	if dom.GetElementsByTagName("head")[0].SourcePosition() != nil {
+		t.Error("Expected implied head element to have no position")
+	}
+}
+
+func TestSourcePositionInDocument(t *testing.T) {
+	source, err := os.ReadFile("test_data/index.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dom, err := goDOM.NewWithOptions(strings.NewReader(string(source)), goDOM.ParseOptions{TrackPositions: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dom.GetElementsByTagName("a")) != 1182 {
+		t.Error("Expected the marked document to have the same elements")
+	}
+	for _, link := range dom.GetElementsByTagName("a") {
+		position := link.SourcePosition()
+		if position == nil {
+			t.Fatal("Expected every link to have a position")
+		}
+		tag := string(source[position.StartTag.Start.Offset:position.StartTag.End.Offset])
+		if !strings.HasPrefix(tag, "<a") {
+			t.Fatal("Unexpected start tag:", tag)
+		}
+		if href, ok := position.Attributes["href"]; ok && !strings.HasPrefix(string(source[href.Start.Offset:href.End.Offset]), "href=") {
+			t.Fatal("Unexpected href span:", string(source[href.Start.Offset:href.End.Offset]))
+		}
+		if end := string(source[position.EndTag.Start.Offset:position.EndTag.End.Offset]); !strings.HasPrefix(end, "</a") {
+			t.Fatal("Unexpected end tag:", end)
+		}
+	}
+	if createTestDOM().GetElementsByTagName("a")[0].SourcePosition() != nil {
+		t.Error("Expected no positions without TrackPositions")
+	}
+}
+
+func TestSourcePositionParsesLikeNew(t *testing.T) {
+	sources := []string{
+		`<a id=x href=foo/>t</a>`,
+		`<a/b>x</a><div/>y</div>`,
+		`<img src=a.png/><input value=/ disabled/><svg><path d=M0/></svg>`,
+		"<p\tclass=a\n>x</p><br/ ><td/x>",
+		`<svg><![CDATA[ a > <b> ]]></svg><p><![CDATA[ <i> ]]></p>`,
+		`<p><b><b><b><b>x</p><p>y`,
+		`<p><b class=a><b class=a><b class=a><b class=a>x</p><p>y<a href=1>z<div>w</a>v`,
+		`<table><tr><td>a</td></tr>x<b>y</b><div>z</div></table>`,
+		`<select><option>a<title><b>b</b></title><textarea>c<i>d</i></select>`,
+		`<svg><title><b>t</b></title><style>s<i>x</i></style></svg>`,
+		`<table><b>x<!--c-->y</b></table>`,
+	}
+	for _, source := range sources {
+		tracked, err := goDOM.NewWithOptions(strings.NewReader(source), goDOM.ParseOptions{TrackPositions: true, ReportErrors: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected, _ := createDOMFromString(source).Render()
+		if rendered, _ := tracked.Render(); rendered != expected {
+			t.Errorf("Expected %q to parse like New\n%s\nbut got\n%s", source, expected, rendered)
+		}
+		for _, b := range tracked.GetElementsByTagName("b") {
+			if position := b.SourcePosition(); position != nil && !strings.HasPrefix(source[position.StartTag.Start.Offset:], "<b") {
+				t.Errorf("Expected the position of a start tag of <b> in %q", source)
+			}
+		}
+	}
+	source := `<a id=x href=foo/>t</a>`
+	dom, _ := goDOM.NewWithOptions(strings.NewReader(source), goDOM.ParseOptions{TrackPositions: true})
+	position := dom.GetElementById("x").SourcePosition()
+	if end := source[position.EndTag.Start.Offset:position.EndTag.End.Offset]; end != "</a>" {
+		t.Errorf("Expected the end tag of a self-closing HTML element but got %q", end)
+	}
+}
@@ -0,0 +1,447 @@
+package goDOM
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// ErrInvalidState is returned when an operation is not allowed in the current state of a Range.
+var ErrInvalidState = errors.New("goDOM: the range partially selects a non-text node")
+
+// A Range represents a fragment of a document that can contain nodes and parts of text nodes.
+//
+// A boundary point is a container node and an offset. If the container is a text node,
+// the offset is a byte offset into its text, otherwise it is the index of a child node.
+//
+// See Javascript equivalent: https://developer.mozilla.org/en-US/docs/Web/API/Range
+type Range struct {
+	startContainer *html.Node
+	startOffset    int
+	endContainer   *html.Node
+	endOffset      int
+	doc            *document
+}
+
+// CreateRange returns a new Range collapsed at the start of the node.
+//
+// See Javascript equivalent: https://developer.mozilla.org/en-US/docs/Web/API/Document/createRange
+func (d *DOM) CreateRange() *Range {
+	return &Range{d.node, 0, d.node, 0, d.doc}
+}
+
+// StartContainer returns the node within which the Range starts.
+//
+// See Javascript equivalent: https://developer.mozilla.org/en-US/docs/Web/API/Range/startContainer
+func (r *Range) StartContainer() *DOM {
+	return newDOM(r.startContainer, r.doc)
+}
+
+// StartOffset returns a number representing where in the StartContainer the Range starts.
+//
+// See Javascript equivalent: https://developer.mozilla.org/en-US/docs/Web/API/Range/startOffset
+func (r *Range) StartOffset() int {
+	return r.startOffset
+}
+
+// EndContainer returns the node within which the Range ends.
+//
+// See Javascript equivalent: https://developer.mozilla.org/en-US/docs/Web/API/Range/endContainer
+func (r *Range) EndContainer() *DOM {
+	return newDOM(r.endContainer, r.doc)
+}
+
+// EndOffset returns a number representing where in the EndContainer the Range ends.
+//
+// See Javascript equivalent: https://developer.mozilla.org/en-US/docs/Web/API/Range/endOffset
+func (r *Range) EndOffset() int {
+	return r.endOffset
+}
+
+// Collapsed returns a boolean value indicating whether the Range's start and end points are at the same position.
+//
+// See Javascript equivalent: https://developer.mozilla.org/en-US/docs/Web/API/Range/collapsed
+func (r *Range) Collapsed() bool {
+	return r.startContainer == r.endContainer && r.startOffset == r.endOffset
+}
+
+// CommonAncestorContainer returns the deepest node that contains both boundary points of the Range.
+//
+// See Javascript equivalent: https://developer.mozilla.org/en-US/docs/Web/API/Range/commonAncestorContainer
+func (r *Range) CommonAncestorContainer() *DOM {
+	return newDOM(commonAncestor(r.startContainer, r.endContainer), r.doc)
+}
+
+// SetStart sets the start position of the Range.
+// If the start is after the end, the Range is collapsed to the new start.
+//
+// See Javascript equivalent: https://developer.mozilla.org/en-US/docs/Web/API/Range/setStart
+func (r *Range) SetStart(node *DOM, offset int) {
+	r.startContainer, r.startOffset = node.node, clampOffset(node.node, offset)
+	if compareBoundaryPoints(r.startContainer, r.startOffset, r.endContainer, r.endOffset) > 0 {
+		r.endContainer, r.endOffset = r.startContainer, r.startOffset
+	}
+}
+
+// SetEnd sets the end position of the Range.
+// If the end is before the start, the Range is collapsed to the new end.
+//
+// See Javascript equivalent: https://developer.mozilla.org/en-US/docs/Web/API/Range/setEnd
+func (r *Range) SetEnd(node *DOM, offset int) {
+	r.endContainer, r.endOffset = node.node, clampOffset(node.node, offset)
+	if compareBoundaryPoints(r.startContainer, r.startOffset, r.endContainer, r.endOffset) > 0 {
+		r.startContainer, r.startOffset = r.endContainer, r.endOffset
+	}
+}
+
+// SelectNodeContents sets the Range to contain the contents of the given node.
+//
+// See Javascript equivalent: https://developer.mozilla.org/en-US/docs/Web/API/Range/selectNodeContents
+func (r *Range) SelectNodeContents(node *DOM) {
+	r.startContainer, r.startOffset = node.node, 0
+	r.endContainer, r.endOffset = node.node, nodeLength(node.node)
+}
+
+// ToString returns the text of all text nodes in the Range.
+//
+// See Javascript equivalent: https://developer.mozilla.org/en-US/docs/Web/API/Range/toString
+func (r *Range) ToString() string {
+	start, end := r.startContainer, r.endContainer
+	if start == nil || end == nil {
+		return ""
+	}
+	if start == end && start.Type == html.TextNode {
+		return start.Data[r.startOffset:r.endOffset]
+	}
+	var sb strings.Builder
+	if start.Type == html.TextNode {
+		sb.WriteString(start.Data[r.startOffset:])
+	}
+	for _, node := range newDOM(commonAncestor(start, end), nil).getFlatNodeList(false) {
+		text := node.node
+		if text.Type != html.TextNode || text == start || text == end {
+			continue
+		}
+		if compareBoundaryPoints(text, 0, start, r.startOffset) > 0 &&
+			compareBoundaryPoints(text, len(text.Data), end, r.endOffset) < 0 {
+			sb.WriteString(text.Data)
+		}
+	}
+	if end.Type == html.TextNode {
+		sb.WriteString(end.Data[:r.endOffset])
+	}
+	return sb.String()
+}
+
+// ExtractContents moves the contents of the Range from the document into a new fragment and returns it.
+// Partially selected elements are cloned, so that the fragment keeps the structure of the extracted content.
+// Afterwards the Range is collapsed at the position of the extracted content.
+//
+// See Javascript equivalent: https://developer.mozilla.org/en-US/docs/Web/API/Range/extractContents
+func (r *Range) ExtractContents() *DOM {
+	fragment := &html.Node{Type: html.DocumentNode}
+	if r.startContainer == nil || r.endContainer == nil || r.Collapsed() {
+		return &DOM{node: fragment, doc: r.doc, fragment: true}
+	}
+	startParent, startBefore, endParent, endBefore := r.splitBoundaries()
+	// The range collapses to the position where the start side of the extracted content was.
+	parent, offset := startParent, nodeLength(startParent)
+	if !isAncestorOrSelf(startParent, endParent) {
+		partial := childContaining(commonAncestor(startParent, endParent), startParent)
+		parent, offset = partial.Parent, nodeIndex(partial)+1
+	} else if startBefore != nil {
+		offset = nodeIndex(startBefore)
+	}
+	for _, node := range extractBetween(startParent, startBefore, endParent, endBefore) {
+		fragment.AppendChild(node)
+	}
+	r.startContainer, r.startOffset = parent, offset
+	r.endContainer, r.endOffset = parent, offset
+	r.doc.invalidateCaches()
+	return &DOM{node: fragment, doc: r.doc, fragment: true}
+}
+
+// SurroundContents moves the content of the Range into newParent and places newParent at the start of the Range.
+// Existing children of newParent are removed. ErrInvalidState is returned if the Range partially selects
+// a node which is not a text node.
+//
+// See Javascript equivalent: https://developer.mozilla.org/en-US/docs/Web/API/Range/surroundContents
+func (r *Range) SurroundContents(newParent *DOM) error {
+	if boundaryParent(r.startContainer) != boundaryParent(r.endContainer) {
+		return ErrInvalidState
+	}
+	for child := newParent.node.FirstChild; child != nil; child = newParent.node.FirstChild {
+		newParent.node.RemoveChild(child)
+	}
+	if newParent.node.Parent != nil {
+		newParent.node.Parent.RemoveChild(newParent.node)
+	}
+	parent, startBefore, _, endBefore := r.splitBoundaries()
+	for node := startBefore; node != endBefore; {
+		next := node.NextSibling
+		parent.RemoveChild(node)
+		newParent.node.AppendChild(node)
+		node = next
+	}
+	parent.InsertBefore(newParent.node, endBefore)
+	r.startContainer, r.startOffset = parent, nodeIndex(newParent.node)
+	r.endContainer, r.endOffset = parent, r.startOffset+1
+	r.doc.invalidateCaches()
+	return nil
+}
+
+// FindTextOptions configures the search of FindText.
+type FindTextOptions struct {
+	// IgnoreCase compares the text case-insensitively.
+	IgnoreCase bool
+	// Regexp, if set, is used to find the matches instead of the query.
+	Regexp *regexp.Regexp
+}
+
+// FindText searches the inner text of the node for the query and returns a Range for each match.
+// The search runs over the text as returned by InnerText, so a query can span multiple text nodes
+// and inline elements. The boundaries of the returned ranges are text nodes.
+// This method is not part of the Javascript Document interface.
+func (d *DOM) FindText(query string, opts FindTextOptions) []*Range {
+	ranges := make([]*Range, 0)
+	re := opts.Regexp
+	if re == nil {
+		if query == "" {
+			return ranges
+		}
+		pattern := regexp.QuoteMeta(query)
+		if opts.IgnoreCase {
+			pattern = "(?i)" + pattern
+		}
+		re = regexp.MustCompile(pattern)
+	}
+	for _, match := range d.findTextMatches(re) {
+		start, end := match.segments[0], match.segments[len(match.segments)-1]
+		ranges = append(ranges, &Range{
+			startContainer: start.node,
+			startOffset:    match.startOffset,
+			endContainer:   end.node,
+			endOffset:      match.endOffset,
+			doc:            d.doc,
+		})
+	}
+	return ranges
+}
+
+// textMatch is a match of a regular expression in the inner text of a node.
+type textMatch struct {
+	// segments are the parts of the inner text the match overlaps.
+	segments []textSegment
+	// startOffset is the offset of the match in the text node of the first segment.
+	startOffset int
+	// endOffset is the offset of the end of the match in the text node of the last segment.
+	endOffset int
+}
+
+// findTextMatches returns all non-empty matches of re in the inner text of the node.
+func (d *DOM) findTextMatches(re *regexp.Regexp) []textMatch {
+	matches := make([]textMatch, 0)
+	b := d.innerText(context.Background(), true)
+	segments := b.segments
+	for _, match := range re.FindAllStringIndex(b.sb.String(), -1) {
+		if match[0] == match[1] {
+			continue
+		}
+		// The start is mapped to the first segment ending after it,
+		// the end to the last segment starting before it.
+		first := sort.Search(len(segments), func(i int) bool {
+			return segments[i].start+segments[i].length > match[0]
+		})
+		last := sort.Search(len(segments), func(i int) bool {
+			return segments[i].start >= match[1]
+		}) - 1
+		if first >= len(segments) || last < first {
+			continue
+		}
+		start, end := segments[first], segments[last]
+		matches = append(matches, textMatch{
+			segments:    segments[first : last+1],
+			startOffset: start.offset + max(match[0]-start.start, 0),
+			endOffset:   end.offset + min(match[1]-end.start, end.length),
+		})
+	}
+	return matches
+}
+
+// splitBoundaries splits the text nodes at the boundary points of the Range and returns
+// the boundary points as parent nodes and the child nodes they precede.
+// The end is split first, so that splitting the start cannot move the end boundary.
+func (r *Range) splitBoundaries() (startParent, startBefore, endParent, endBefore *html.Node) {
+	endParent, endBefore = splitAt(r.endContainer, r.endOffset)
+	startParent, startBefore = splitAt(r.startContainer, r.startOffset)
+	return startParent, startBefore, endParent, endBefore
+}
+
+// splitAt converts a boundary point to a parent node and the child node it precedes.
+// Text nodes are split at the offset if needed.
+func splitAt(container *html.Node, offset int) (parent, before *html.Node) {
+	if container.Type != html.TextNode {
+		return container, childAt(container, offset)
+	}
+	parent = container.Parent
+	if offset == 0 {
+		return parent, container
+	}
+	if offset >= len(container.Data) {
+		return parent, container.NextSibling
+	}
+	tail := &html.Node{Type: html.TextNode, Data: container.Data[offset:]}
+	container.Data = container.Data[:offset]
+	parent.InsertBefore(tail, container.NextSibling)
+	return parent, tail
+}
+
+// extractBetween removes the nodes between two boundary points from the tree and returns them.
+// Ancestors of the boundary points below their common ancestor are cloned and keep the extracted descendants.
+func extractBetween(startParent, startBefore, endParent, endBefore *html.Node) []*html.Node {
+	nodes := make([]*html.Node, 0)
+	ancestor := commonAncestor(startParent, endParent)
+	from, to := startBefore, endBefore
+	if startParent != ancestor {
+		partial := childContaining(ancestor, startParent)
+		clone := cloneShallow(partial)
+		for _, node := range extractBetween(startParent, startBefore, partial, nil) {
+			clone.AppendChild(node)
+		}
+		nodes = append(nodes, clone)
+		from = partial.NextSibling
+	}
+	var endPartial *html.Node
+	if endParent != ancestor {
+		endPartial = childContaining(ancestor, endParent)
+		to = endPartial
+	}
+	for node := from; node != nil && node != to; {
+		next := node.NextSibling
+		ancestor.RemoveChild(node)
+		nodes = append(nodes, node)
+		node = next
+	}
+	if endPartial != nil {
+		clone := cloneShallow(endPartial)
+		for _, node := range extractBetween(endPartial, endPartial.FirstChild, endParent, endBefore) {
+			clone.AppendChild(node)
+		}
+		nodes = append(nodes, clone)
+	}
+	return nodes
+}
+
+// compareBoundaryPoints returns -1, 0 or 1 if the first boundary point is before, equal to or after the second one.
+func compareBoundaryPoints(nodeA *html.Node, offsetA int, nodeB *html.Node, offsetB int) int {
+	if nodeA == nodeB {
+		switch {
+		case offsetA < offsetB:
+			return -1
+		case offsetA > offsetB:
+			return 1
+		}
+		return 0
+	}
+	position := newDOM(nodeA, nil).CompareDocumentPosition(newDOM(nodeB, nil))
+	if position&DocumentPositionPreceding != 0 {
+		return -compareBoundaryPoints(nodeB, offsetB, nodeA, offsetA)
+	}
+	if position&DocumentPositionContainedBy != 0 {
+		if nodeIndex(childContaining(nodeA, nodeB)) < offsetA {
+			return 1
+		}
+	}
+	return -1
+}
+
+// commonAncestor returns the deepest node which is an ancestor of or equal to both nodes.
+func commonAncestor(a, b *html.Node) *html.Node {
+	for node := a; node != nil; node = node.Parent {
+		if isAncestorOrSelf(node, b) {
+			return node
+		}
+	}
+	return nil
+}
+
+// isAncestorOrSelf returns a boolean value indicating whether ancestor is node or one of its ancestors.
+func isAncestorOrSelf(ancestor, node *html.Node) bool {
+	for ; node != nil; node = node.Parent {
+		if node == ancestor {
+			return true
+		}
+	}
+	return false
+}
+
+// childContaining returns the child of ancestor which is an ancestor of or equal to node.
+func childContaining(ancestor, node *html.Node) *html.Node {
+	for node != nil && node.Parent != ancestor {
+		node = node.Parent
+	}
+	return node
+}
+
+// boundaryParent returns the node whose children a boundary point in container lies between.
+func boundaryParent(container *html.Node) *html.Node {
+	if container != nil && container.Type == html.TextNode {
+		return container.Parent
+	}
+	return container
+}
+
+// cloneShallow returns a copy of the node without its children.
+func cloneShallow(node *html.Node) *html.Node {
+	clone := &html.Node{
+		Type:      node.Type,
+		DataAtom:  node.DataAtom,
+		Data:      node.Data,
+		Namespace: node.Namespace,
+	}
+	clone.Attr = append(clone.Attr, node.Attr...)
+	return clone
+}
+
+// nodeIndex returns the index of the node in the child list of its parent.
+func nodeIndex(node *html.Node) int {
+	index := 0
+	for sibling := node.PrevSibling; sibling != nil; sibling = sibling.PrevSibling {
+		index++
+	}
+	return index
+}
+
+// nodeLength returns the length of the node: the number of bytes of a text node
+// or the number of children of any other node.
+func nodeLength(node *html.Node) int {
+	if node == nil {
+		return 0
+	}
+	if node.Type == html.TextNode || node.Type == html.CommentNode {
+		return len(node.Data)
+	}
+	length := 0
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		length++
+	}
+	return length
+}
+
+// childAt returns the child at the given index or nil if there are fewer children.
+func childAt(node *html.Node, index int) *html.Node {
+	child := node.FirstChild
+	for ; child != nil && index > 0; index-- {
+		child = child.NextSibling
+	}
+	return child
+}
+
+// clampOffset limits the offset to the valid offsets of the node.
+func clampOffset(node *html.Node, offset int) int {
+	return max(0, min(offset, nodeLength(node)))
+}
@@ -0,0 +1,117 @@
+package goDOM_test
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/richi0/goDOM"
+)
+
+func TestRangeToString(t *testing.T) {
+	dom := createDOMFromString(`<p id="p">Hello <b>big</b> world</p>`)
+	p := dom.GetElementById("p")
+	r := dom.CreateRange()
+	if !r.Collapsed() {
+		t.Error("Expected new range to be collapsed")
+	}
+	r.SelectNodeContents(p)
+	if text := r.ToString(); text != "Hello big world" {
+		t.Errorf("Expected range text to be %q but got %q", "Hello big world", text)
+	}
+	r.SetStart(p.FirstChild(), 2)
+	r.SetEnd(p.LastChild(), 3)
+	if text := r.ToString(); text != "llo big wo" {
+		t.Errorf("Expected range text to be %q but got %q", "llo big wo", text)
+	}
+	if r.CommonAncestorContainer().TagName() != "p" {
+		t.Error("Expected common ancestor to be p")
+	}
+}
+
+func TestRangeExtractContents(t *testing.T) {
+	dom := createDOMFromString(`<p id="p">Hello <b>big</b> world</p>`)
+	p := dom.GetElementById("p")
+	r := dom.CreateRange()
+	r.SetStart(p.FirstChild(), 2)
+	r.SetEnd(p.FirstElementChild().FirstChild(), 1)
+	fragment := r.ExtractContents()
+	if fragment.TagName() != "fragment" {
+		t.Error("Expected the extracted contents to be a document fragment but got", fragment.TagName())
+	}
+	html, _ := fragment.Render()
+	if html != "llo <b>b</b>" {
+		t.Errorf("Expected fragment to be %q but got %q", "llo <b>b</b>", html)
+	}
+	html, _ = p.Render()
+	if html != `<p id="p">He<b>ig</b> world</p>` {
+		t.Errorf("Expected paragraph to be %q but got %q", `<p id="p">He<b>ig</b> world</p>`, html)
+	}
+	if !r.Collapsed() || r.StartContainer().TagName() != "p" || r.StartOffset() != 1 {
+		t.Error("Expected range to be collapsed after the first text node, got:", r.StartContainer().TagName(), r.StartOffset())
+	}
+	if len(dom.GetElementsByTagName("b")) != 1 {
+		t.Error("Expected the cached element list to be updated")
+	}
+	empty := r.ExtractContents()
+	if html, _ := empty.Render(); empty.TagName() != "fragment" || html != "" {
+		t.Error("Expected an empty document fragment for a collapsed range")
+	}
+}
+
+func TestRangeSurroundContents(t *testing.T) {
+	dom := createDOMFromString(`<p id="p">Hello <b>big</b> world</p>`)
+	p := dom.GetElementById("p")
+	r := dom.CreateRange()
+	r.SetStart(p.FirstChild(), 2)
+	r.SetEnd(p.LastChild(), 3)
+	mark := dom.CreateElement("mark")
+	if err := r.SurroundContents(mark); err != nil {
+		t.Fatal("Expected no error but got", err)
+	}
+	html, _ := p.Render()
+	if html != `<p id="p">He<mark>llo <b>big</b> wo</mark>rld</p>` {
+		t.Error("Unexpected paragraph:", html)
+	}
+	r.SetStart(p.FirstChild(), 1)
+	r.SetEnd(mark.FirstElementChild().FirstChild(), 1)
+	if err := r.SurroundContents(dom.CreateElement("i")); !errors.Is(err, goDOM.ErrInvalidState) {
+		t.Error("Expected ErrInvalidState but got", err)
+	}
+}
+
+func TestFindText(t *testing.T) {
+	dom := createDOMFromString(`<div><p>Buy <b>n</b>ow</p><p>buy   NOW</p><script>buy now</script></div>`)
+	ranges := dom.FindText("buy now", goDOM.FindTextOptions{IgnoreCase: true})
+	if len(ranges) != 2 {
+		t.Fatal("Expected 2 ranges but found", len(ranges))
+	}
+	for _, r := range ranges {
+		if r.StartContainer().TagName() != "text" || r.EndContainer().TagName() != "text" {
+			t.Error("Expected the ranges to start and end in text nodes")
+		}
+	}
+	if text := ranges[0].ToString(); text != "Buy now" {
+		t.Errorf("Expected range text to be %q but got %q", "Buy now", text)
+	}
+	if text := ranges[1].ToString(); text != "buy   NOW" {
+		t.Errorf("Expected range text to be %q but got %q", "buy   NOW", text)
+	}
+	ranges = dom.FindText("", goDOM.FindTextOptions{Regexp: regexp.MustCompile(`[Bb]uy`)})
+	if len(ranges) != 2 {
+		t.Error("Expected 2 ranges but found", len(ranges))
+	}
+}
+
+func TestFindTextInDocument(t *testing.T) {
+	dom := createTestDOM()
+	ranges := dom.FindText("Enumerated types", goDOM.FindTextOptions{})
+	if len(ranges) == 0 {
+		t.Fatal("Expected ranges but found none")
+	}
+	for _, r := range ranges {
+		if text := r.ToString(); text != "Enumerated types" {
+			t.Errorf("Expected range text to be %q but got %q", "Enumerated types", text)
+		}
+	}
+}
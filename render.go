@@ -0,0 +1,369 @@
+package goDOM
+
+import (
+	"bufio"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// QuoteStyle selects how attribute values are quoted.
+type QuoteStyle int
+
+const (
+	// QuoteDouble encloses attribute values in double quotes.
+	QuoteDouble QuoteStyle = iota
+	// QuoteSingle encloses attribute values in single quotes.
+	QuoteSingle
+	// QuoteMinimal leaves attribute values unquoted where HTML allows it and uses double quotes otherwise.
+	// Empty values are omitted, so that <input value=""> is written as <input value>.
+	QuoteMinimal
+)
+
+// RenderOptions configures how RenderTo formats the DOM.
+type RenderOptions struct {
+	// Indent is written once per nesting level at the start of every line, like "  " or "\t".
+	Indent string
+	// MaxWidth is the maximum length of a line with a start tag. Longer start tags are written with one
+	// attribute per line. Text is never wrapped. Zero means no limit.
+	MaxWidth int
+	// SortAttributes writes the attributes in alphabetical order instead of the source order.
+	SortAttributes bool
+	// Quote is the quote style of attribute values.
+	Quote QuoteStyle
+	// CollapseWhitespace collapses runs of whitespace in text to a single space, which does not change
+	// how the text is displayed. Otherwise text and inline elements are written as they are.
+	CollapseWhitespace bool
+}
+
+// RenderTo writes a formatted representation of the DOM to w.
+//
+// Every block element, like <div> or <li>, starts on a new line and its content is indented by one level.
+// Runs of text and inline elements, like <a> or <b>, stay together on one line. Whitespace is only
+// changed where it does not affect rendering: the content of whitespace-sensitive elements like <pre>,
+// <textarea>, <script> and <style> is written unchanged and inline text is never wrapped.
+// This method is not part of the Javascript Document interface.
+func (d *DOM) RenderTo(w io.Writer, opts RenderOptions) error {
+	r := &renderer{w: bufio.NewWriter(w), opts: opts}
+	nodes := []*html.Node{d.node}
+	if d.fragment || d.node.Type == html.DocumentNode {
+		nodes = nodes[:0]
+		for child := d.node.FirstChild; child != nil; child = child.NextSibling {
+			nodes = append(nodes, child)
+		}
+	}
+	r.pretty(nodes)
+	return r.w.Flush()
+}
+
+// renderer writes nodes according to RenderOptions.
+type renderer struct {
+	w    *bufio.Writer
+	opts RenderOptions
+	// omitEmpty writes attributes with an empty value without value for every quote style.
+	omitEmpty bool
+}
+
+// prettyItem is a line or a block of lines written by renderer.pretty.
+type prettyItem struct {
+	// run is a run of inline nodes written on one line.
+	run []*html.Node
+	// block is a node which starts on a new line.
+	block *html.Node
+	// end is set for the item writing the end tag of block.
+	end   bool
+	level int
+}
+
+// pretty writes the nodes, one block per line. The tree is walked with an explicit stack.
+func (r *renderer) pretty(nodes []*html.Node) {
+	stack := r.group(nodes, 0, nil)
+	for len(stack) > 0 {
+		item := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		switch {
+		case item.end:
+			r.line(item.level, "</"+item.block.Data+">")
+		case item.run != nil:
+			var sb strings.Builder
+			for _, node := range item.run {
+				r.compact(&sb, node, r.opts.CollapseWhitespace)
+			}
+			if text := strings.Trim(sb.String(), asciiWhitespace); text != "" {
+				r.line(item.level, text)
+			}
+		case item.block.Type != html.ElementNode:
+			var sb strings.Builder
+			r.compact(&sb, item.block, false)
+			r.line(item.level, sb.String())
+		case isVerbatim(item.block) || !hasBlockChild(item.block):
+			var sb strings.Builder
+			r.startTag(&sb, item.block, item.level, true)
+			if !isVoidElement(item.block.Data) && (item.block.FirstChild != nil || item.block.Namespace == "") {
+				var content strings.Builder
+				verbatim := isVerbatim(item.block)
+				for child := item.block.FirstChild; child != nil; child = child.NextSibling {
+					r.compact(&content, child, !verbatim && r.opts.CollapseWhitespace)
+				}
+				if verbatim {
+					sb.WriteString(content.String())
+				} else {
+					sb.WriteString(strings.Trim(content.String(), asciiWhitespace))
+				}
+				sb.WriteString("</" + item.block.Data + ">")
+			}
+			r.line(item.level, sb.String())
+		default:
+			var sb strings.Builder
+			r.startTag(&sb, item.block, item.level, true)
+			r.line(item.level, sb.String())
+			children := make([]*html.Node, 0)
+			for child := item.block.FirstChild; child != nil; child = child.NextSibling {
+				children = append(children, child)
+			}
+			stack = append(stack, prettyItem{block: item.block, end: true, level: item.level})
+			stack = r.group(children, item.level+1, stack)
+		}
+	}
+}
+
+// group splits the nodes into blocks and runs of inline nodes and pushes them on the stack in reverse order.
+func (r *renderer) group(nodes []*html.Node, level int, stack []prettyItem) []prettyItem {
+	items := make([]prettyItem, 0)
+	var run []*html.Node
+	for _, node := range nodes {
+		if isBlock(node) {
+			if run != nil {
+				items = append(items, prettyItem{run: run, level: level})
+				run = nil
+			}
+			items = append(items, prettyItem{block: node, level: level})
+			continue
+		}
+		run = append(run, node)
+	}
+	if run != nil {
+		items = append(items, prettyItem{run: run, level: level})
+	}
+	for i := len(items) - 1; i >= 0; i-- {
+		stack = append(stack, items[i])
+	}
+	return stack
+}
+
+// line writes the indented line.
+func (r *renderer) line(level int, s string) {
+	for i := 0; i < level; i++ {
+		r.w.WriteString(r.opts.Indent)
+	}
+	r.w.WriteString(s)
+	r.w.WriteByte('\n')
+}
+
+// startTag writes the start tag of the element. If wrap is set and the tag does not fit into MaxWidth,
+// every attribute is written on a new line indented one level deeper than level.
+func (r *renderer) startTag(sb *strings.Builder, node *html.Node, level int, wrap bool) {
+	attrs := r.attributes(node)
+	closing := ">"
+	if node.Namespace != "" && node.FirstChild == nil {
+		closing = "/>"
+		// A slash directly after an unquoted value would become part of the value.
+		if len(attrs) > 0 && !strings.HasSuffix(attrs[len(attrs)-1], `"`) && !strings.HasSuffix(attrs[len(attrs)-1], "'") {
+			closing = " />"
+		}
+	}
+	width := len(node.Data) + 1 + len(closing) + level*len(r.opts.Indent)
+	for _, attr := range attrs {
+		width += len(attr) + 1
+	}
+	separator := " "
+	if wrap && r.opts.MaxWidth > 0 && width > r.opts.MaxWidth && len(attrs) > 1 {
+		separator = "\n" + strings.Repeat(r.opts.Indent, level+1)
+	}
+	sb.WriteString("<" + node.Data)
+	for _, attr := range attrs {
+		sb.WriteString(separator + attr)
+	}
+	sb.WriteString(closing)
+	if (node.Data == "pre" || node.Data == "listing" || node.Data == "textarea") && node.FirstChild != nil &&
+		node.FirstChild.Type == html.TextNode && strings.HasPrefix(node.FirstChild.Data, "\n") {
+		// The parser drops a newline directly after the start tag, so it is written twice.
+		sb.WriteByte('\n')
+	}
+}
+
+// attributes returns the attributes of the element in the form key="value".
+func (r *renderer) attributes(node *html.Node) []string {
+	attrs := slices.Clone(node.Attr)
+	if r.opts.SortAttributes {
+		sort.SliceStable(attrs, func(i, j int) bool { return attributeName(attrs[i]) < attributeName(attrs[j]) })
+	}
+	written := make([]string, 0, len(attrs))
+	for _, attr := range attrs {
+		written = append(written, attributeName(attr)+r.attributeValue(attr.Val))
+	}
+	return written
+}
+
+// attributeValue returns the quoted and escaped value of an attribute including the equals sign.
+func (r *renderer) attributeValue(value string) string {
+	if value == "" && r.omitEmpty {
+		return ""
+	}
+	switch r.opts.Quote {
+	case QuoteSingle:
+		return "='" + strings.ReplaceAll(strings.ReplaceAll(value, "&", "&amp;"), "'", "&#39;") + "'"
+	case QuoteMinimal:
+		if value == "" {
+			return ""
+		}
+		if !strings.ContainsAny(value, asciiWhitespace+"\"'=<>`") {
+			return "=" + strings.ReplaceAll(value, "&", "&amp;")
+		}
+	}
+	return `="` + strings.ReplaceAll(strings.ReplaceAll(value, "&", "&amp;"), `"`, "&quot;") + `"`
+}
+
+// compact writes the node without formatting. If collapse is set, runs of whitespace in text outside
+// of whitespace-sensitive elements are collapsed to a single space.
+func (r *renderer) compact(sb *strings.Builder, node *html.Node, collapse bool) {
+	type entry struct {
+		node *html.Node
+		end  bool
+	}
+	stack := []entry{{node: node}}
+	verbatim := 0
+	for len(stack) > 0 {
+		e := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		n := e.node
+		if e.end {
+			sb.WriteString("</" + n.Data + ">")
+			if isVerbatim(n) {
+				verbatim--
+			}
+			continue
+		}
+		switch n.Type {
+		case html.TextNode:
+			switch {
+			case n.Parent != nil && n.Parent.Namespace == "" && slices.Contains(rawTextElements, n.Parent.Data):
+				sb.WriteString(n.Data)
+			case collapse && verbatim == 0:
+				sb.WriteString(escapeText(collapseWhitespace(n.Data)))
+			default:
+				sb.WriteString(escapeText(n.Data))
+			}
+		case html.CommentNode:
+			sb.WriteString("<!--" + n.Data + "-->")
+		case html.DoctypeNode:
+			sb.WriteString(doctype(n))
+		case html.RawNode:
+			sb.WriteString(n.Data)
+		case html.DocumentNode:
+			for child := n.LastChild; child != nil; child = child.PrevSibling {
+				stack = append(stack, entry{node: child})
+			}
+		case html.ElementNode:
+			r.startTag(sb, n, 0, false)
+			if isVoidElement(n.Data) || (n.Namespace != "" && n.FirstChild == nil) {
+				continue
+			}
+			if isVerbatim(n) {
+				verbatim++
+			}
+			stack = append(stack, entry{node: n, end: true})
+			for child := n.LastChild; child != nil; child = child.PrevSibling {
+				stack = append(stack, entry{node: child})
+			}
+		}
+	}
+}
+
+// asciiWhitespace contains the whitespace characters of HTML.
+const asciiWhitespace = " \t\n\f\r"
+
+// textEscaper escapes the characters of text which would otherwise be parsed as markup.
+var textEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\r", "&#13;")
+
+// escapeText escapes text content.
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+// collapseWhitespace replaces every run of ASCII whitespace by a single space.
+func collapseWhitespace(s string) string {
+	var sb strings.Builder
+	space := false
+	for i := 0; i < len(s); i++ {
+		if isSpace(s[i]) {
+			space = true
+			continue
+		}
+		if space {
+			sb.WriteByte(' ')
+			space = false
+		}
+		sb.WriteByte(s[i])
+	}
+	if space {
+		sb.WriteByte(' ')
+	}
+	return sb.String()
+}
+
+// doctype returns the doctype declaration of the node.
+func doctype(node *html.Node) string {
+	var sb strings.Builder
+	sb.WriteString("<!DOCTYPE " + node.Data)
+	public, system := nodeAttribute(node, "public"), nodeAttribute(node, "system")
+	if public != "" {
+		sb.WriteString(` PUBLIC "` + public + `"`)
+	} else if system != "" {
+		sb.WriteString(" SYSTEM")
+	}
+	if system != "" {
+		sb.WriteString(` "` + system + `"`)
+	}
+	sb.WriteString(">")
+	return sb.String()
+}
+
+// isBlock returns a boolean value indicating whether RenderTo starts the node on a new line.
+//
+// Metadata elements like <link> or <script> are only blocks in the head, since they can appear inside text
+// in the body. Other elements, including unknown and custom elements, are inline like in CSS.
+func isBlock(node *html.Node) bool {
+	switch node.Type {
+	case html.DoctypeNode:
+		return true
+	case html.ElementNode:
+		if node.Namespace != "" {
+			return false
+		}
+		if slices.Contains(metadataElements, node.Data) {
+			return node.Parent == nil || node.Parent.Type != html.ElementNode || node.Parent.Data == "head" || node.Parent.Data == "html"
+		}
+		return slices.Contains(blockElements, node.Data) || slices.Contains(layoutElements, node.Data)
+	}
+	return false
+}
+
+// hasBlockChild returns a boolean value indicating whether a child of the node starts on a new line.
+func hasBlockChild(node *html.Node) bool {
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if isBlock(child) {
+			return true
+		}
+	}
+	return false
+}
+
+// isVerbatim returns a boolean value indicating whether the content of the element is whitespace-sensitive.
+func isVerbatim(node *html.Node) bool {
+	return node.Type == html.ElementNode && node.Namespace == "" &&
+		(slices.Contains(preformattedElements, node.Data) || slices.Contains(rawTextElements, node.Data))
+}
@@ -0,0 +1,110 @@
+package goDOM_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/richi0/goDOM"
+)
+
+const renderTestHTML = `<!DOCTYPE html><html><head><title>T</title><style>
+ p { color: red }
+</style></head><body>
+  <div id="b" class="a"><p>Some   <b>bold</b>
+   text</p><ul><li>one<li>two <a href="x">link</a></ul><pre>
+
+  keep   this
+</pre><svg><rect width="1"/></svg><textarea>
+ x</textarea></div><!-- c --> tail
+</body></html>`
+
+func renderTo(t *testing.T, dom *goDOM.DOM, opts goDOM.RenderOptions) string {
+	t.Helper()
+	var out bytes.Buffer
+	if err := dom.RenderTo(&out, opts); err != nil {
+		t.Fatal("Unexpected render error:", err)
+	}
+	return out.String()
+}
+
+func TestRenderTo(t *testing.T) {
+	rendered := renderTo(t, createDOMFromString(renderTestHTML), goDOM.RenderOptions{Indent: "  ", CollapseWhitespace: true})
+	expected := `<!DOCTYPE html>
+<html>
+  <head>
+    <title>T</title>
+    <style>
+ p { color: red }
+</style>
+  </head>
+  <body>
+    <div id="b" class="a">
+      <p>Some <b>bold</b> text</p>
+      <ul>
+        <li>one</li>
+        <li>two <a href="x">link</a></li>
+      </ul>
+      <pre>
+
+  keep   this
+</pre>
+      <svg><rect width="1"/></svg><textarea> x</textarea>
+    </div>
+    <!-- c --> tail
+  </body>
+</html>
+`
+	if rendered != expected {
+		t.Errorf("Expected\n%s\nbut got\n%s", expected, rendered)
+	}
+}
+
+func TestRenderToOptions(t *testing.T) {
+	dom := createDOMFromString(`<div id="b" class="a" title="it's &quot;x&quot;"><p>a   <b> b </b></p><input value="" disabled><svg><rect width="1"/></svg></div>`)
+	div := dom.GetElementById("b")
+	tests := []struct {
+		opts     goDOM.RenderOptions
+		expected string
+	}{
+		{goDOM.RenderOptions{Indent: "\t", SortAttributes: true, CollapseWhitespace: true},
+			"<div class=\"a\" id=\"b\" title=\"it's &quot;x&quot;\">\n\t<p>a <b> b </b></p>\n\t<input disabled=\"\" value=\"\"><svg><rect width=\"1\"/></svg>\n</div>\n"},
+		{goDOM.RenderOptions{Quote: goDOM.QuoteSingle},
+			"<div id='b' class='a' title='it&#39;s \"x\"'>\n<p>a   <b> b </b></p>\n<input value='' disabled=''><svg><rect width='1'/></svg>\n</div>\n"},
+		{goDOM.RenderOptions{Quote: goDOM.QuoteMinimal, CollapseWhitespace: true},
+			"<div id=b class=a title=\"it's &quot;x&quot;\">\n<p>a <b> b </b></p>\n<input value disabled><svg><rect width=1 /></svg>\n</div>\n"},
+		{goDOM.RenderOptions{Indent: "  ", MaxWidth: 20, CollapseWhitespace: true},
+			"<div\n  id=\"b\"\n  class=\"a\"\n  title=\"it's &quot;x&quot;\">\n  <p>a <b> b </b></p>\n  <input value=\"\" disabled=\"\"><svg><rect width=\"1\"/></svg>\n</div>\n"},
+	}
+	for _, test := range tests {
+		if rendered := renderTo(t, div, test.opts); rendered != test.expected {
+			t.Errorf("Expected\n%s\nbut got\n%s", test.expected, rendered)
+		}
+	}
+}
+
+func TestRenderToKeepsWhitespace(t *testing.T) {
+	span := createDOMFromString(`<span>a   b</span>`).GetElementsByTagName("span")[0]
+	if rendered := renderTo(t, span, goDOM.RenderOptions{}); rendered != "<span>a   b</span>\n" {
+		t.Errorf("Expected whitespace to be kept by default but got %q", rendered)
+	}
+}
+
+func TestRenderToPreservesRendering(t *testing.T) {
+	for _, dom := range []*goDOM.DOM{createTestDOM(), createDOMFromString(renderTestHTML)} {
+		for _, opts := range []goDOM.RenderOptions{{Indent: "  ", MaxWidth: 80}, {Quote: goDOM.QuoteMinimal, SortAttributes: true}} {
+			parsed := createDOMFromString(renderTo(t, dom, opts))
+			if parsed.InnerText() != dom.InnerText() {
+				t.Error("Expected formatting not to change the rendered text")
+			}
+			if len(parsed.GetElementsByTagName("*")) != len(dom.GetElementsByTagName("*")) {
+				t.Error("Expected formatting not to change the elements")
+			}
+			pres, parsedPres := dom.GetElementsByTagName("pre"), parsed.GetElementsByTagName("pre")
+			for i := range pres {
+				if pres[i].Text(true) != parsedPres[i].Text(true) {
+					t.Errorf("Expected preformatted text %q to be kept but got %q", pres[i].Text(true), parsedPres[i].Text(true))
+				}
+			}
+		}
+	}
+}
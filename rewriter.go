@@ -0,0 +1,354 @@
+package goDOM
+
+import (
+	"bufio"
+	"io"
+
+	"golang.org/x/net/html"
+)
+
+// A Rewriter transforms HTML while streaming it from a Reader to a Writer.
+//
+// Handlers are registered for selectors and called for every matching element or text in the input.
+// Unlike the DOM, the Rewriter never holds the whole document in memory: only the current token
+// and the stack of open elements are kept. Since there is no tree, selectors are matched against the
+// elements as they are opened in the source. Elements without an end tag, like li and p, are closed
+// like the HTML parser closes them, but other corrections the parser applies to misnested markup are not.
+// The stack is limited to a depth of 512 elements. Unmodified parts of the input are written byte for byte.
+// The Rewriter is not part of the Javascript Document interface.
+type Rewriter struct {
+	elementHandlers []rewriteHandler[*RewriteElement]
+	textHandlers    []rewriteHandler[*RewriteText]
+}
+
+// rewriteHandler is a handler with the selector which triggers it.
+type rewriteHandler[T any] struct {
+	selector selector
+	fn       func(T) error
+}
+
+// NewRewriter returns a Rewriter without handlers.
+func NewRewriter() *Rewriter {
+	return &Rewriter{}
+}
+
+// OnElement registers fn to be called for every element matching selector.
+// An error is returned if the selector is invalid.
+//
+// Supported are type, universal, id, class and attribute selectors (with the operators =, ~=, |=, ^=, $= and *=)
+// combined with the descendant and child combinators, like "div.note > a[href], #main". Type and attribute
+// selectors can have one of the namespace prefixes html, svg, math, xlink, xml and xmlns, like "svg|rect"
+// or "[xlink|href]". Pseudo-classes are not supported.
+func (rw *Rewriter) OnElement(selector string, fn func(*RewriteElement) error) error {
+	s, err := parseSelector(selector)
+	if err != nil {
+		return err
+	}
+	rw.elementHandlers = append(rw.elementHandlers, rewriteHandler[*RewriteElement]{s, fn})
+	return nil
+}
+
+// OnAttribute registers fn to rewrite the value of the attribute key of every element matching selector
+// which has the attribute. An error is returned if the selector is invalid.
+func (rw *Rewriter) OnAttribute(selector, key string, fn func(value string) string) error {
+	return rw.OnElement(selector, func(e *RewriteElement) error {
+		if e.HasAttribute(key) {
+			e.SetAttribute(key, fn(e.GetAttribute(key)))
+		}
+		return nil
+	})
+}
+
+// OnText registers fn to be called for every text chunk whose parent element matches selector.
+// An error is returned if the selector is invalid.
+func (rw *Rewriter) OnText(selector string, fn func(*RewriteText) error) error {
+	s, err := parseSelector(selector)
+	if err != nil {
+		return err
+	}
+	rw.textHandlers = append(rw.textHandlers, rewriteHandler[*RewriteText]{s, fn})
+	return nil
+}
+
+// A RewriteElement is an element in the stream of a Rewriter.
+type RewriteElement struct {
+	node         *html.Node
+	modified     bool
+	removed      bool
+	before       []string
+	after        []string
+	prepend      []string
+	append       []string
+	innerContent *string
+}
+
+// TagName returns the tag name of the element.
+func (e *RewriteElement) TagName() string {
+	return e.node.Data
+}
+
+// GetAttribute returns the value of the attribute or the empty string if the element does not have it.
+func (e *RewriteElement) GetAttribute(key string) string {
+	return nodeAttribute(e.node, key)
+}
+
+// HasAttribute returns a boolean value indicating whether the element has the attribute.
+func (e *RewriteElement) HasAttribute(key string) bool {
+	for _, attr := range e.node.Attr {
+		if attr.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// SetAttribute sets the value of the attribute. If the attribute does not exist, it is added.
+func (e *RewriteElement) SetAttribute(key, value string) {
+	e.modified = true
+	setNodeAttribute(e.node, key, value)
+}
+
+// RemoveAttribute removes the attribute from the element.
+func (e *RewriteElement) RemoveAttribute(key string) {
+	e.modified = true
+	removeNodeAttribute(e.node, key)
+}
+
+// Before inserts the HTML content before the element.
+func (e *RewriteElement) Before(content string) {
+	e.before = append(e.before, content)
+}
+
+// After inserts the HTML content after the element.
+func (e *RewriteElement) After(content string) {
+	e.after = append(e.after, content)
+}
+
+// Prepend inserts the HTML content at the start of the element's content.
+func (e *RewriteElement) Prepend(content string) {
+	e.prepend = append(e.prepend, content)
+}
+
+// Append inserts the HTML content at the end of the element's content.
+func (e *RewriteElement) Append(content string) {
+	e.append = append(e.append, content)
+}
+
+// SetInnerContent replaces the content of the element with the HTML content.
+func (e *RewriteElement) SetInnerContent(content string) {
+	e.innerContent = &content
+}
+
+// Replace replaces the element and its content with the HTML content.
+func (e *RewriteElement) Replace(content string) {
+	e.removed = true
+	e.before = append(e.before, content)
+}
+
+// Remove removes the element and its content.
+func (e *RewriteElement) Remove() {
+	e.removed = true
+}
+
+// A RewriteText is a chunk of text in the stream of a Rewriter.
+type RewriteText struct {
+	text     string
+	replaced *string
+}
+
+// Text returns the unescaped text.
+func (t *RewriteText) Text() string {
+	return t.text
+}
+
+// Replace replaces the text with the HTML content.
+func (t *RewriteText) Replace(content string) {
+	t.replaced = &content
+}
+
+// Remove removes the text.
+func (t *RewriteText) Remove() {
+	empty := ""
+	t.replaced = &empty
+}
+
+// rewriterElement is an element on the stack of a Rewriter.
+type rewriterElement struct {
+	node *html.Node
+	// skip is set if the content of the element is not written.
+	skip bool
+	// removed is set if the end tag of the element is not written.
+	removed bool
+	append  []string
+	after   []string
+}
+
+// openNode returns the node of the element.
+func (e *rewriterElement) openNode() *html.Node {
+	return e.node
+}
+
+// Rewrite reads HTML from r, applies the handlers and writes the result to w.
+// It stops at the first error of a handler, r or w.
+func (rw *Rewriter) Rewrite(w io.Writer, r io.Reader) error {
+	out := bufio.NewWriter(w)
+	z := html.NewTokenizer(r)
+	stack := make([]*rewriterElement, 0)
+	parent := func() *html.Node {
+		if len(stack) == 0 {
+			return nil
+		}
+		return stack[len(stack)-1].node
+	}
+	skipping := func() bool {
+		return len(stack) > 0 && stack[len(stack)-1].skip
+	}
+	closeElement := func(e *rewriterElement, endTag []byte) {
+		if !e.skip {
+			writeStrings(out, e.append)
+		}
+		if !e.removed {
+			out.Write(endTag)
+		}
+		writeStrings(out, e.after)
+	}
+	for {
+		tokenType := z.Next()
+		switch tokenType {
+		case html.ErrorToken:
+			if err := z.Err(); err != io.EOF {
+				return err
+			}
+			for i := len(stack) - 1; i >= 0; i-- {
+				closeElement(stack[i], nil)
+			}
+			return out.Flush()
+		case html.StartTagToken, html.SelfClosingTagToken:
+			// The tokenizer lowercases and unescapes in place, so the raw bytes are copied first.
+			raw := append([]byte(nil), z.Raw()...)
+			token := z.Token()
+			for closeTo := startTagCloses(stack, token.Data); len(stack) > closeTo; stack = stack[:len(stack)-1] {
+				closeElement(stack[len(stack)-1], nil)
+			}
+			node := &html.Node{Type: html.ElementNode, Data: token.Data, Attr: token.Attr, Parent: parent()}
+			node.Namespace = tokenNamespace(node.Parent, token.Data)
+			// Like the parser, only foreign elements are closed by the self-closing flag.
+			opens := !isVoidElement(token.Data) && (tokenType == html.StartTagToken || node.Namespace == "")
+			if opens && len(stack) >= maxOpenElements {
+				closeElement(stack[len(stack)-1], nil)
+				stack = stack[:len(stack)-1]
+				node.Parent = parent()
+				node.Namespace = tokenNamespace(node.Parent, token.Data)
+			}
+			if skipping() {
+				if opens {
+					stack = append(stack, &rewriterElement{node: node, skip: true, removed: true})
+				}
+				continue
+			}
+			element := &RewriteElement{node: node}
+			for _, handler := range rw.elementHandlers {
+				if handler.selector.match(node, false) {
+					if err := handler.fn(element); err != nil {
+						return err
+					}
+				}
+			}
+			writeStrings(out, element.before)
+			if !element.removed {
+				if element.modified {
+					token.Attr = node.Attr
+					out.WriteString(token.String())
+				} else {
+					out.Write(raw)
+				}
+			}
+			if !opens {
+				writeStrings(out, element.after)
+				continue
+			}
+			open := &rewriterElement{node: node, removed: element.removed, append: element.append, after: element.after}
+			if element.removed {
+				open.skip = true
+			} else {
+				writeStrings(out, element.prepend)
+				if element.innerContent != nil {
+					out.WriteString(*element.innerContent)
+					open.skip = true
+				}
+			}
+			stack = append(stack, open)
+		case html.EndTagToken:
+			raw := append([]byte(nil), z.Raw()...)
+			name, _ := z.TagName()
+			index := endTagCloses(stack, string(name))
+			if index < 0 {
+				if !skipping() {
+					out.Write(raw)
+				}
+				continue
+			}
+			// Elements without an end tag are closed implicitly by the end tag of an ancestor.
+			for i := len(stack) - 1; i > index; i-- {
+				closeElement(stack[i], nil)
+			}
+			closeElement(stack[index], raw)
+			stack = stack[:index]
+		case html.TextToken:
+			if skipping() {
+				continue
+			}
+			raw := append([]byte(nil), z.Raw()...)
+			if len(stack) == 0 || len(rw.textHandlers) == 0 {
+				out.Write(raw)
+				continue
+			}
+			text := &RewriteText{text: string(z.Text())}
+			for _, handler := range rw.textHandlers {
+				if handler.selector.match(parent(), false) {
+					if err := handler.fn(text); err != nil {
+						return err
+					}
+				}
+			}
+			if text.replaced != nil {
+				out.WriteString(*text.replaced)
+			} else {
+				out.Write(raw)
+			}
+		default:
+			if !skipping() {
+				out.Write(z.Raw())
+			}
+		}
+	}
+}
+
+// writeStrings writes all strings to w.
+func writeStrings(w *bufio.Writer, content []string) {
+	for _, s := range content {
+		w.WriteString(s)
+	}
+}
+
+// setNodeAttribute sets the value of the attribute of the node. If the attribute does not exist, it is added.
+func setNodeAttribute(node *html.Node, key, value string) {
+	for i, attr := range node.Attr {
+		if attr.Key == key {
+			node.Attr[i].Val = value
+			return
+		}
+	}
+	node.Attr = append(node.Attr, html.Attribute{Key: key, Val: value})
+}
+
+// removeNodeAttribute removes the attribute from the node.
+func removeNodeAttribute(node *html.Node, key string) {
+	attrs := make([]html.Attribute, 0, len(node.Attr))
+	for _, attr := range node.Attr {
+		if attr.Key != key {
+			attrs = append(attrs, attr)
+		}
+	}
+	node.Attr = attrs
+}
@@ -0,0 +1,244 @@
+package goDOM_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/richi0/goDOM"
+)
+
+func rewrite(t *testing.T, rw *goDOM.Rewriter, input string) string {
+	t.Helper()
+	var out strings.Builder
+	if err := rw.Rewrite(&out, strings.NewReader(input)); err != nil {
+		t.Fatal("Unexpected rewrite error:", err)
+	}
+	return out.String()
+}
+
+func TestRewriterAttribute(t *testing.T) {
+	rw := goDOM.NewRewriter()
+	err := rw.OnAttribute("a[href^='/']", "href", func(value string) string {
+		return "https://example.com" + value
+	})
+	if err != nil {
+		t.Fatal("Unexpected selector error:", err)
+	}
+	input := `<!DOCTYPE html><P CLASS=x><a href="/wiki">Wiki</a> <a href="http://a.b">&amp;</a><a>x</a></P>`
+	expected := `<!DOCTYPE html><P CLASS=x><a href="https://example.com/wiki">Wiki</a> <a href="http://a.b">&amp;</a><a>x</a></P>`
+	if out := rewrite(t, rw, input); out != expected {
+		t.Errorf("Expected\n%s\nbut got\n%s", expected, out)
+	}
+}
+
+func TestRewriterElement(t *testing.T) {
+	rw := goDOM.NewRewriter()
+	rw.OnElement("div.ad", func(e *goDOM.RewriteElement) error {
+		e.Remove()
+		return nil
+	})
+	rw.OnElement("#main > h1", func(e *goDOM.RewriteElement) error {
+		e.Replace("<h2>Title</h2>")
+		return nil
+	})
+	rw.OnElement("ul", func(e *goDOM.RewriteElement) error {
+		e.Before("<hr>")
+		e.Prepend("<li>first</li>")
+		e.Append("<li>last</li>")
+		e.After("<hr>")
+		return nil
+	})
+	rw.OnElement("p[data-x]", func(e *goDOM.RewriteElement) error {
+		e.SetInnerContent("new")
+		e.RemoveAttribute("data-x")
+		return nil
+	})
+	input := `<div id="main"><h1>Old <b>title</b></h1><div class="ad box"><div>nested</div>ad</div>` +
+		`<ul><li>a<li>b</ul><p data-x="1">old <i>content</i></p><img src="x.png"></div>`
+	expected := `<div id="main"><h2>Title</h2>` +
+		`<hr><ul><li>first</li><li>a<li>b<li>last</li></ul><hr><p>new</p><img src="x.png"></div>`
+	if out := rewrite(t, rw, input); out != expected {
+		t.Errorf("Expected\n%s\nbut got\n%s", expected, out)
+	}
+}
+
+func TestRewriterText(t *testing.T) {
+	rw := goDOM.NewRewriter()
+	rw.OnText("p em", func(text *goDOM.RewriteText) error {
+		text.Replace(strings.ToUpper(text.Text()))
+		return nil
+	})
+	rw.OnText("script", func(text *goDOM.RewriteText) error {
+		text.Remove()
+		return nil
+	})
+	input := `<p>a <em>b &lt; c</em></p><em>d</em><script>var x = "<p>";</script>`
+	expected := `<p>a <em>B < C</em></p><em>d</em><script></script>`
+	if out := rewrite(t, rw, input); out != expected {
+		t.Errorf("Expected\n%s\nbut got\n%s", expected, out)
+	}
+}
+
+func TestRewriterHandlerError(t *testing.T) {
+	rw := goDOM.NewRewriter()
+	errStop := errors.New("stop")
+	rw.OnElement("b", func(e *goDOM.RewriteElement) error {
+		return errStop
+	})
+	var out bytes.Buffer
+	if err := rw.Rewrite(&out, strings.NewReader("<a><b></b></a>")); !errors.Is(err, errStop) {
+		t.Error("Expected handler error but got", err)
+	}
+}
+
+func TestRewriterInvalidSelector(t *testing.T) {
+	rw := goDOM.NewRewriter()
+	for _, selector := range []string{"", "a,", "> a", "a >", "a[href", "a[href='x]", "#", "a:hover", "div..x"} {
+		if err := rw.OnElement(selector, func(e *goDOM.RewriteElement) error { return nil }); err == nil {
+			t.Errorf("Expected error for selector %q", selector)
+		}
+	}
+	for _, selector := range []string{"*", "a, b", "div > p a", "[lang~=en]", `a[href$=".pdf"]`, "DIV#x.y.z"} {
+		if err := rw.OnElement(selector, func(e *goDOM.RewriteElement) error { return nil }); err != nil {
+			t.Errorf("Unexpected error for selector %q: %s", selector, err)
+		}
+	}
+}
+
+func TestRewriterSelectors(t *testing.T) {
+	input := `<div id="a" class="x y"><p lang="en-us de"><a href="/doc.pdf">1</a></p><section><a href="/b">2</a></section></div>`
+	tests := map[string]string{
+		"a":                 "12",
+		"div a":             "12",
+		"div > a":           "",
+		"p > a":             "1",
+		"div.x.y section a": "2",
+		"#a > section > a":  "2",
+		"[lang~=de] a":      "1",
+		"[lang|=en] a":      "1",
+		"[lang|=e] a":       "",
+		`a[href$=".pdf"]`:   "1",
+		"a[href*=b], p a":   "12",
+		"div.z a":           "",
+		"section > * , p *": "12",
+	}
+	for selector, expected := range tests {
+		rw := goDOM.NewRewriter()
+		matched := ""
+		err := rw.OnText(selector, func(text *goDOM.RewriteText) error {
+			matched += text.Text()
+			return nil
+		})
+		if err != nil {
+			t.Errorf("Unexpected error for selector %q: %s", selector, err)
+			continue
+		}
+		rewrite(t, rw, input)
+		if matched != expected {
+			t.Errorf("Expected selector %q to match %q but matched %q", selector, expected, matched)
+		}
+	}
+}
+
+func TestRewriterImpliedEndTags(t *testing.T) {
+	tests := map[string]string{
+		"ul > li":         "abc",
+		"li li":           "",
+		"p > b":           "x",
+		"div > b":         "y",
+		"dl > dd":         "d",
+		"tr > td":         "123",
+		"select > option": "op",
+	}
+	input := `<ul><li>a<li>b<li>c</ul><div><p><b>x</b><div><b>y</b></div></div><dl><dt>t<dd>d</dl>` +
+		`<table><tr><td>1<td>2<tr><td>3</table><select><option>o<option>p</select>`
+	for selector, expected := range tests {
+		rw := goDOM.NewRewriter()
+		matched := ""
+		if err := rw.OnText(selector, func(text *goDOM.RewriteText) error {
+			matched += text.Text()
+			return nil
+		}); err != nil {
+			t.Fatal("Unexpected selector error:", err)
+		}
+		if out := rewrite(t, rw, input); out != input {
+			t.Errorf("Expected the input unchanged but got\n%s", out)
+		}
+		if matched != expected {
+			t.Errorf("Expected selector %q to match %q but matched %q", selector, expected, matched)
+		}
+	}
+
+	rw := goDOM.NewRewriter()
+	rw.OnElement("li.x", func(e *goDOM.RewriteElement) error {
+		e.Remove()
+		return nil
+	})
+	if out := rewrite(t, rw, `<ul><li class="x">a<li>b</ul>`); out != `<ul><li>b</ul>` {
+		t.Error("Expected an implicitly closed element to be removed without its siblings but got", out)
+	}
+}
+
+func TestRewriterDepth(t *testing.T) {
+	input := strings.Repeat("<li>item", 20000) + strings.Repeat("<div>", 20000)
+	rw := goDOM.NewRewriter()
+	count := 0
+	rw.OnElement("li, div div div", func(e *goDOM.RewriteElement) error {
+		count++
+		return nil
+	})
+	if out := rewrite(t, rw, input); out != input {
+		t.Error("Expected the input unchanged")
+	}
+	if count != 20000+20000-2 {
+		t.Error("Expected every element to be matched but got", count)
+	}
+}
+
+func TestRewriterEquivalence(t *testing.T) {
+	input, err := os.ReadFile("test_data/index.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rewriteHref := func(value string) string {
+		if strings.HasPrefix(value, "/") {
+			return "https://en.wikipedia.org" + value
+		}
+		return value
+	}
+
+	rw := goDOM.NewRewriter()
+	rw.OnAttribute("a[href]", "href", rewriteHref)
+	rw.OnElement("a[title]", func(e *goDOM.RewriteElement) error {
+		e.RemoveAttribute("title")
+		return nil
+	})
+	var out bytes.Buffer
+	if err := rw.Rewrite(&out, bytes.NewReader(input)); err != nil {
+		t.Fatal("Unexpected rewrite error:", err)
+	}
+	streamed, err := goDOM.New(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tree := createTestDOM()
+	for _, a := range tree.GetElementsByTagName("a") {
+		if a.HasAttribute("href") {
+			a.SetAttribute("href", rewriteHref(a.Attributes()["href"]))
+		}
+		a.RemoveAttribute("title")
+	}
+
+	expected, _ := tree.Render()
+	actual, _ := streamed.Render()
+	if expected != actual {
+		t.Error("Expected streaming rewrite to be equivalent to the tree mutation")
+	}
+	if !strings.Contains(actual, `href="https://en.wikipedia.org/wiki/`) {
+		t.Error("Expected rewritten links in the output")
+	}
+}
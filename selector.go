@@ -0,0 +1,375 @@
+package goDOM
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// A selector is a parsed group of simple CSS selectors, like "div.note > a[href], #main".
+//
+// Supported are type, universal, id, class and attribute selectors (with the operators
+// =, ~=, |=, ^=, $= and *=) combined with the descendant and child combinators.
+// Type and attribute selectors can have a namespace prefix, like "svg|rect" or "[xlink|href]",
+// see selectorNamespaces. Without a prefix, type selectors match elements in any namespace and
+// attribute selectors match attributes without a namespace, like in CSS.
+type selector []complexSelector
+
+// selectorNamespaces are the namespace prefixes known to selectors. "*|" matches any namespace and "|" no namespace.
+var selectorNamespaces = map[string]string{
+	"html":  NamespaceHTML,
+	"svg":   NamespaceSVG,
+	"math":  NamespaceMathML,
+	"xlink": NamespaceXLink,
+	"xml":   NamespaceXML,
+	"xmlns": NamespaceXMLNS,
+}
+
+// complexSelector is a chain of compound selectors. The last compound matches the element itself.
+type complexSelector []compoundSelector
+
+// compoundSelector matches a single element.
+type compoundSelector struct {
+	tag       string
+	namespace selectorNamespace
+	id        string
+	classes   []string
+	attrs     []attributeSelector
+	// child is set if the compound must match the parent of the element matched by the next compound,
+	// otherwise any ancestor.
+	child bool
+}
+
+// attributeSelector matches an attribute of an element.
+type attributeSelector struct {
+	key       string
+	namespace selectorNamespace
+	op        string
+	value     string
+}
+
+// selectorNamespace is the namespace of a type or attribute selector.
+type selectorNamespace struct {
+	// any is set if the selector matches all namespaces.
+	any bool
+	uri string
+}
+
+// match returns a boolean value indicating whether the namespace URI matches.
+func (n selectorNamespace) match(uri string) bool {
+	return n.any || n.uri == uri
+}
+
+// parseSelector parses a group of selectors.
+func parseSelector(s string) (selector, error) {
+	group := make(selector, 0)
+	for _, part := range splitSelectorGroup(s) {
+		complex, err := parseComplexSelector(part)
+		if err != nil {
+			return nil, err
+		}
+		group = append(group, complex)
+	}
+	if len(group) == 0 {
+		return nil, fmt.Errorf("goDOM: empty selector %q", s)
+	}
+	return group, nil
+}
+
+// splitSelectorGroup splits a group of selectors at the commas which are not inside brackets or quotes.
+func splitSelectorGroup(s string) []string {
+	parts := make([]string, 0)
+	depth, quote, start := 0, byte(0), 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+		case c == ',' && depth == 0:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// parseComplexSelector parses compound selectors separated by combinators.
+func parseComplexSelector(s string) (complexSelector, error) {
+	complex := make(complexSelector, 0)
+	i := 0
+	child := false
+	for {
+		for i < len(s) && (isSpace(s[i]) || s[i] == '>') {
+			if s[i] == '>' {
+				if len(complex) == 0 || child {
+					return nil, fmt.Errorf("goDOM: unexpected combinator in selector %q", s)
+				}
+				child = true
+			}
+			i++
+		}
+		if i == len(s) {
+			break
+		}
+		compound, next, err := parseCompoundSelector(s, i)
+		if err != nil {
+			return nil, err
+		}
+		if child {
+			complex[len(complex)-1].child = true
+			child = false
+		}
+		complex = append(complex, compound)
+		i = next
+	}
+	if len(complex) == 0 || child {
+		return nil, fmt.Errorf("goDOM: invalid selector %q", s)
+	}
+	return complex, nil
+}
+
+// parseCompoundSelector parses the compound selector starting at i and returns the index after it.
+func parseCompoundSelector(s string, i int) (compoundSelector, int, error) {
+	compound := compoundSelector{}
+	start := i
+	namespace, next, err := parseSelectorNamespace(s, i, selectorNamespace{any: true})
+	if err != nil {
+		return compound, i, err
+	}
+	if next > i && (next == len(s) || (s[next] != '*' && !isSelectorNameStart(s[next]))) {
+		return compound, i, fmt.Errorf("goDOM: missing type after namespace in selector %q", s)
+	}
+	compound.namespace, i = namespace, next
+	if i < len(s) && s[i] == '*' {
+		i++
+	} else if name, next := readSelectorName(s, i); next > i {
+		compound.tag = name
+		i = next
+	}
+	for i < len(s) && !isSpace(s[i]) && s[i] != '>' {
+		switch s[i] {
+		case '#':
+			name, next := readSelectorName(s, i+1)
+			if next == i+1 {
+				return compound, i, fmt.Errorf("goDOM: missing id in selector %q", s)
+			}
+			compound.id, i = name, next
+		case '.':
+			name, next := readSelectorName(s, i+1)
+			if next == i+1 {
+				return compound, i, fmt.Errorf("goDOM: missing class in selector %q", s)
+			}
+			compound.classes, i = append(compound.classes, name), next
+		case '[':
+			attr, next, err := parseAttributeSelector(s, i+1)
+			if err != nil {
+				return compound, i, err
+			}
+			compound.attrs, i = append(compound.attrs, attr), next
+		default:
+			return compound, i, fmt.Errorf("goDOM: unexpected %q in selector %q", s[i], s)
+		}
+	}
+	if i == start {
+		return compound, i, fmt.Errorf("goDOM: invalid selector %q", s)
+	}
+	return compound, i, nil
+}
+
+// parseAttributeSelector parses the attribute selector after the opening bracket at i.
+func parseAttributeSelector(s string, i int) (attributeSelector, int, error) {
+	attr := attributeSelector{}
+	namespace, next, err := parseSelectorNamespace(s, i, selectorNamespace{})
+	if err != nil {
+		return attr, i, err
+	}
+	attr.namespace, i = namespace, next
+	name, next := readSelectorName(s, i)
+	if next == i {
+		return attr, i, fmt.Errorf("goDOM: missing attribute name in selector %q", s)
+	}
+	attr.key, i = name, next
+	for _, op := range []string{"=", "~=", "|=", "^=", "$=", "*="} {
+		if strings.HasPrefix(s[i:], op) {
+			attr.op = op
+			i += len(op)
+			break
+		}
+	}
+	if attr.op != "" {
+		if i < len(s) && (s[i] == '"' || s[i] == '\'') {
+			end := strings.IndexByte(s[i+1:], s[i])
+			if end < 0 {
+				return attr, i, fmt.Errorf("goDOM: unterminated string in selector %q", s)
+			}
+			attr.value, i = s[i+1:i+1+end], i+end+2
+		} else {
+			attr.value, i = readSelectorName(s, i)
+		}
+	}
+	if i >= len(s) || s[i] != ']' {
+		return attr, i, fmt.Errorf("goDOM: missing ] in selector %q", s)
+	}
+	return attr, i + 1, nil
+}
+
+// parseSelectorNamespace parses a namespace prefix like "svg|", "*|" or "|" starting at i
+// and returns the index after it. If there is no prefix, the index is unchanged and def is returned.
+func parseSelectorNamespace(s string, i int, def selectorNamespace) (selectorNamespace, int, error) {
+	prefix, next := readSelectorName(s, i)
+	if next == i && next < len(s) && s[next] == '*' {
+		prefix, next = "*", next+1
+	}
+	// A | followed by = is the |= operator of an attribute selector.
+	if next >= len(s) || s[next] != '|' || (next+1 < len(s) && s[next+1] == '=') {
+		return def, i, nil
+	}
+	switch prefix {
+	case "*":
+		return selectorNamespace{any: true}, next + 1, nil
+	case "":
+		return selectorNamespace{}, next + 1, nil
+	}
+	uri, ok := selectorNamespaces[prefix]
+	if !ok {
+		return def, i, fmt.Errorf("goDOM: unknown namespace prefix %q in selector %q", prefix, s)
+	}
+	return selectorNamespace{uri: uri}, next + 1, nil
+}
+
+// readSelectorName reads an identifier starting at i and returns it and the index after it.
+func readSelectorName(s string, i int) (string, int) {
+	start := i
+	for i < len(s) && isSelectorNameStart(s[i]) {
+		i++
+	}
+	return s[start:i], i
+}
+
+// isSelectorNameStart returns a boolean value indicating whether c can be part of an identifier.
+func isSelectorNameStart(c byte) bool {
+	return c == '-' || c == '_' || c >= 0x80 || ('0' <= c && c <= '9') || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+// match returns a boolean value indicating whether the element matches the selector.
+// The ancestors of the element are reached through the Parent links of the nodes.
+// Names are compared case-sensitively in documents parsed by NewXML, otherwise case-insensitively.
+func (s selector) match(node *html.Node, xml bool) bool {
+	if node == nil {
+		return false
+	}
+	for _, complex := range s {
+		if complex.match(node, xml) {
+			return true
+		}
+	}
+	return false
+}
+
+// match returns a boolean value indicating whether the element matches the complex selector.
+func (c complexSelector) match(node *html.Node, xml bool) bool {
+	if !c[len(c)-1].match(node, xml) {
+		return false
+	}
+	return c.matchAncestors(len(c)-2, node.Parent, xml)
+}
+
+// matchAncestors matches the compounds up to index i against the element parent and its ancestors.
+func (c complexSelector) matchAncestors(i int, parent *html.Node, xml bool) bool {
+	if i < 0 {
+		return true
+	}
+	for node := parent; node != nil && node.Type == html.ElementNode; node = node.Parent {
+		if c[i].match(node, xml) && c.matchAncestors(i-1, node.Parent, xml) {
+			return true
+		}
+		if c[i].child {
+			return false
+		}
+	}
+	return false
+}
+
+// match returns a boolean value indicating whether the element matches the compound selector.
+func (c compoundSelector) match(node *html.Node, xml bool) bool {
+	if node.Type != html.ElementNode {
+		return false
+	}
+	if !c.namespace.match(nodeNamespaceURI(node, xml)) {
+		return false
+	}
+	if c.tag != "" && !equalNames(c.tag, nodeLocalName(node, xml), xml) {
+		return false
+	}
+	if c.id != "" && nodeAttribute(node, "id") != c.id {
+		return false
+	}
+	if len(c.classes) > 0 {
+		classes := strings.Fields(nodeAttribute(node, "class"))
+		for _, class := range c.classes {
+			if !slices.Contains(classes, class) {
+				return false
+			}
+		}
+	}
+	for _, attr := range c.attrs {
+		if !attr.match(node, xml) {
+			return false
+		}
+	}
+	return true
+}
+
+// match returns a boolean value indicating whether the element has a matching attribute.
+func (a attributeSelector) match(node *html.Node, xml bool) bool {
+	for _, attr := range node.Attr {
+		namespace, local := attributeNamespace(node, attr, xml)
+		if !equalNames(a.key, local, xml) || !a.namespace.match(namespace) {
+			continue
+		}
+		switch a.op {
+		case "=":
+			return attr.Val == a.value
+		case "~=":
+			return slices.Contains(strings.Fields(attr.Val), a.value)
+		case "|=":
+			return attr.Val == a.value || strings.HasPrefix(attr.Val, a.value+"-")
+		case "^=":
+			return a.value != "" && strings.HasPrefix(attr.Val, a.value)
+		case "$=":
+			return a.value != "" && strings.HasSuffix(attr.Val, a.value)
+		case "*=":
+			return a.value != "" && strings.Contains(attr.Val, a.value)
+		}
+		return true
+	}
+	return false
+}
+
+// equalNames compares the name of a selector with the name of an element or attribute.
+func equalNames(selector, name string, xml bool) bool {
+	if xml {
+		return selector == name
+	}
+	return strings.EqualFold(selector, name)
+}
+
+// nodeAttribute returns the value of the attribute of the node or the empty string.
+func nodeAttribute(node *html.Node, key string) string {
+	for _, attr := range node.Attr {
+		if attr.Key == key {
+			return attr.Val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,103 @@
+package goDOM_test
+
+import (
+	"io"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/richi0/goDOM"
+)
+
+// countMatches returns the number of elements of the input which match the selector in a Rewriter.
+func countMatches(t *testing.T, input, selector string) (int, error) {
+	t.Helper()
+	count := 0
+	rw := goDOM.NewRewriter()
+	if err := rw.OnElement(selector, func(e *goDOM.RewriteElement) error {
+		count++
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+	if err := rw.Rewrite(io.Discard, strings.NewReader(input)); err != nil {
+		t.Fatal(err)
+	}
+	return count, nil
+}
+
+func TestSelectors(t *testing.T) {
+	input := namespaceTestHTML + `<div class="a b"><a href="/x" title="T">1</a><span><a>2</a></span></div>`
+	tests := map[string]int{
+		"a":                        2,
+		"div > a":                  1,
+		"div.a.b a":                2,
+		"A[HREF]":                  1,
+		"svg|*":                    4,
+		"svg|linearGradient":       1,
+		"svg|lineargradient":       1,
+		"html|div":                 2,
+		"math|*":                   2,
+		"*|mi":                     1,
+		"|mi":                      0,
+		"svg > *":                  3,
+		"svg|svg html|div":         1,
+		"[xlink|href='#g']":        1,
+		"[*|href]":                 2,
+		"[href]":                   1,
+		"svg|use[xlink|href^='#']": 1,
+	}
+	for selector, expected := range tests {
+		count, err := countMatches(t, input, selector)
+		if err != nil {
+			t.Errorf("Unexpected error for selector %q: %s", selector, err)
+			continue
+		}
+		if count != expected {
+			t.Errorf("Expected selector %q to match %d elements but got %d", selector, expected, count)
+		}
+	}
+	if _, err := countMatches(t, input, "foo|a"); err == nil {
+		t.Error("Expected error for unknown namespace prefix")
+	}
+	if _, err := countMatches(t, input, "svg|"); err == nil {
+		t.Error("Expected error for missing type after namespace")
+	}
+}
+
+func TestSelectorsXML(t *testing.T) {
+	dom := createDOMFromXML(`<root xmlns:svg="http://www.w3.org/2000/svg"><Item/><item/><svg:rect Width="1"/></root>`)
+	tests := map[string][]string{
+		"Item":            {"<item", "<svg:rect"},
+		"svg|rect[Width]": {"<Item", "<item"},
+		"svg|rect[width]": {"<Item", "<item", "<svg:rect"},
+	}
+	for selector, expected := range tests {
+		canonical, err := dom.Canonicalize(goDOM.CanonicalOptions{IgnoreElements: selector})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, tag := range []string{"<Item", "<item", "<svg:rect"} {
+			if strings.Contains(canonical, tag) != slices.Contains(expected, tag) {
+				t.Errorf("Expected selector %q to be case-sensitive and namespace-aware in XML documents but got\n%s", selector, canonical)
+			}
+		}
+	}
+}
+
+func TestRewriterNamespaces(t *testing.T) {
+	rw := goDOM.NewRewriter()
+	rw.OnElement("svg|a, svg|*[xlink|href]", func(e *goDOM.RewriteElement) error {
+		e.SetAttribute("class", "svg")
+		return nil
+	})
+	input := `<a></a><svg><a></a><use xlink:href="#x"/><foreignObject><a></a></foreignObject></svg>`
+	expected := `<a></a><svg><a class="svg"></a><use xlink:href="#x" class="svg"/><foreignObject><a></a></foreignObject></svg>`
+	var out strings.Builder
+	if err := rw.Rewrite(&out, strings.NewReader(input)); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != expected {
+		t.Errorf("Expected\n%s\nbut got\n%s", expected, out.String())
+	}
+}
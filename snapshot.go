@@ -0,0 +1,308 @@
+package goDOM
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// ErrInvalidSnapshot is returned by ReadSnapshot if the input is not a valid snapshot.
+var ErrInvalidSnapshot = errors.New("goDOM: invalid snapshot")
+
+// snapshotMagic starts every snapshot and is followed by the version of the format.
+const (
+	snapshotMagic   = "GODOM"
+	snapshotVersion = 1
+)
+
+// Flags of a snapshot.
+const (
+	snapshotFragment = 1 << iota
+	snapshotXML
+)
+
+// WriteSnapshot writes the DOM and its descendants to w in a compact binary format which ReadSnapshot
+// loads much faster than parsing the HTML again.
+//
+// The snapshot starts with a magic string and a version byte, followed by a table of the tag names,
+// attribute names and namespaces, the nodes in document order and a CRC-32 checksum. Lengths and
+// indices are written as varints. Source positions and parse errors are not part of the snapshot.
+// This method is not part of the Javascript Document interface.
+func (d *DOM) WriteSnapshot(w io.Writer) error {
+	s := &snapshotWriter{indices: make(map[string]uint64)}
+	var nodes bytes.Buffer
+	count := 0
+	stack := []*html.Node{d.node}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		count++
+		nodes.WriteByte(byte(n.Type))
+		if n.Type == html.ElementNode {
+			s.writeUvarint(&nodes, s.index(n.Data))
+			s.writeUvarint(&nodes, s.index(n.Namespace))
+		} else {
+			s.writeString(&nodes, n.Data)
+		}
+		s.writeUvarint(&nodes, uint64(len(n.Attr)))
+		for _, attr := range n.Attr {
+			s.writeUvarint(&nodes, s.index(attr.Namespace))
+			s.writeUvarint(&nodes, s.index(attr.Key))
+			s.writeString(&nodes, attr.Val)
+		}
+		children := 0
+		for child := n.LastChild; child != nil; child = child.PrevSibling {
+			stack = append(stack, child)
+			children++
+		}
+		s.writeUvarint(&nodes, uint64(children))
+	}
+
+	var out bytes.Buffer
+	out.WriteString(snapshotMagic)
+	out.WriteByte(snapshotVersion)
+	flags := uint64(0)
+	if d.fragment {
+		flags |= snapshotFragment
+	}
+	if d.isXML() {
+		flags |= snapshotXML
+	}
+	s.writeUvarint(&out, flags)
+	encoding := "utf-8"
+	if d.doc != nil && d.doc.encoding != "" {
+		encoding = d.doc.encoding
+	}
+	s.writeString(&out, encoding)
+	s.writeUvarint(&out, uint64(len(s.strings)))
+	for _, str := range s.strings {
+		s.writeString(&out, str)
+	}
+	s.writeUvarint(&out, uint64(count))
+	out.Write(nodes.Bytes())
+	out.Write(binary.LittleEndian.AppendUint32(nil, crc32.ChecksumIEEE(out.Bytes())))
+	_, err := w.Write(out.Bytes())
+	return err
+}
+
+// snapshotWriter holds the string table of WriteSnapshot.
+type snapshotWriter struct {
+	strings []string
+	indices map[string]uint64
+	scratch [binary.MaxVarintLen64]byte
+}
+
+// index returns the index of the string in the string table, adding it if necessary.
+func (s *snapshotWriter) index(str string) uint64 {
+	i, ok := s.indices[str]
+	if !ok {
+		i = uint64(len(s.strings))
+		s.indices[str] = i
+		s.strings = append(s.strings, str)
+	}
+	return i
+}
+
+// writeUvarint writes v as varint.
+func (s *snapshotWriter) writeUvarint(b *bytes.Buffer, v uint64) {
+	b.Write(s.scratch[:binary.PutUvarint(s.scratch[:], v)])
+}
+
+// writeString writes the length of str as varint followed by its bytes.
+func (s *snapshotWriter) writeString(b *bytes.Buffer, str string) {
+	s.writeUvarint(b, uint64(len(str)))
+	b.WriteString(str)
+}
+
+// ReadSnapshot returns the DOM from a snapshot written by WriteSnapshot. An error wrapping
+// ErrInvalidSnapshot is returned if the snapshot has an unsupported version or is corrupted.
+// This function is not part of the Javascript Document interface.
+func ReadSnapshot(r io.Reader) (*DOM, error) {
+	return ReadSnapshotWithLimits(r, Limits{})
+}
+
+// ReadSnapshotWithLimits is like ReadSnapshot, but returns an error if the snapshot exceeds the limits,
+// like NewWithOptions. MaxInputBytes and MaxNodes are checked before the nodes are allocated.
+// This function is not part of the Javascript Document interface.
+func ReadSnapshotWithLimits(r io.Reader, limits Limits) (*DOM, error) {
+	data, err := io.ReadAll(limits.reader(r))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < len(snapshotMagic)+1+4 || string(data[:len(snapshotMagic)]) != snapshotMagic {
+		return nil, fmt.Errorf("%w: missing header", ErrInvalidSnapshot)
+	}
+	if version := data[len(snapshotMagic)]; version != snapshotVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidSnapshot, version)
+	}
+	body, checksum := data[:len(data)-4], binary.LittleEndian.Uint32(data[len(data)-4:])
+	if crc32.ChecksumIEEE(body) != checksum {
+		return nil, fmt.Errorf("%w: checksum mismatch", ErrInvalidSnapshot)
+	}
+	s := &snapshotReader{data: string(body), pos: len(snapshotMagic) + 1}
+	flags := s.readUvarint()
+	doc := &document{encoding: s.readString(), xml: flags&snapshotXML != 0, options: ParseOptions{Limits: limits}}
+	table := make([]string, s.readCount(1))
+	atoms := make([]atom.Atom, len(table))
+	for i := range table {
+		table[i] = s.readString()
+		atoms[i] = atom.Lookup([]byte(table[i]))
+	}
+	index := func() int {
+		i := s.readUvarint()
+		if i >= uint64(len(table)) {
+			s.fail("string index out of range")
+			return -1
+		}
+		return int(i)
+	}
+	lookup := func() string {
+		if i := index(); i >= 0 {
+			return table[i]
+		}
+		return ""
+	}
+	type entry struct {
+		node      *html.Node
+		remaining uint64
+	}
+	count := s.readCount(minSnapshotNodeSize)
+	if limits.MaxNodes > 0 && count > limits.MaxNodes {
+		return nil, fmt.Errorf("%w: more than %d nodes", ErrTooManyNodes, limits.MaxNodes)
+	}
+	var root *html.Node
+	stack := make([]entry, 0)
+	// The nodes are allocated at once, their number is bounded by the size of the input.
+	nodes := make([]html.Node, count)
+	for i := 0; i < count && s.err == nil; i++ {
+		n := &nodes[i]
+		n.Type = html.NodeType(s.readByte())
+		switch n.Type {
+		case html.ElementNode:
+			if i := index(); i >= 0 {
+				n.Data, n.DataAtom = table[i], atoms[i]
+			}
+			n.Namespace = lookup()
+		case html.TextNode, html.DocumentNode, html.CommentNode, html.DoctypeNode, html.RawNode:
+			n.Data = s.readString()
+		default:
+			s.fail("unknown node type")
+		}
+		if attributes := s.readCount(3); attributes > 0 {
+			n.Attr = make([]html.Attribute, attributes)
+			for j := range n.Attr {
+				n.Attr[j] = html.Attribute{Namespace: lookup(), Key: lookup(), Val: s.readString()}
+			}
+		}
+		children := s.readUvarint()
+		if children > 0 && n.Type != html.ElementNode && n.Type != html.DocumentNode {
+			s.fail("children of a leaf node")
+		}
+		if n.Type == html.DocumentNode && i > 0 {
+			s.fail("nested document node")
+		}
+		if s.err != nil {
+			break
+		}
+		if i == 0 {
+			root = n
+		} else {
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("%w: more nodes than children", ErrInvalidSnapshot)
+			}
+			parent := &stack[len(stack)-1]
+			parent.node.AppendChild(n)
+			parent.remaining--
+			if parent.remaining == 0 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+		if children > 0 {
+			stack = append(stack, entry{node: n, remaining: children})
+		}
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+	if root == nil || len(stack) > 0 || s.pos != len(s.data) {
+		return nil, fmt.Errorf("%w: node count does not match the tree", ErrInvalidSnapshot)
+	}
+	if err := limits.check(root); err != nil {
+		return nil, err
+	}
+	return &DOM{node: root, doc: doc, fragment: flags&snapshotFragment != 0}, nil
+}
+
+// snapshotReader decodes the values of a snapshot. The first error is kept and all following reads
+// return zero values. The data is a string, so that the strings read share its memory.
+type snapshotReader struct {
+	data string
+	pos  int
+	err  error
+}
+
+// fail records an error at the current position.
+func (s *snapshotReader) fail(reason string) {
+	if s.err == nil {
+		s.err = fmt.Errorf("%w: %s at byte %d", ErrInvalidSnapshot, reason, s.pos)
+	}
+}
+
+// readByte reads a single byte.
+func (s *snapshotReader) readByte() byte {
+	if s.err != nil || s.pos >= len(s.data) {
+		s.fail("unexpected end")
+		return 0
+	}
+	s.pos++
+	return s.data[s.pos-1]
+}
+
+// readUvarint reads a varint.
+func (s *snapshotReader) readUvarint() uint64 {
+	var v uint64
+	for shift := 0; shift < 64; shift += 7 {
+		b := s.readByte()
+		if s.err != nil {
+			return 0
+		}
+		v |= uint64(b&0x7f) << shift
+		if b < 0x80 {
+			return v
+		}
+	}
+	s.fail("invalid varint")
+	return 0
+}
+
+// minSnapshotNodeSize is the minimum number of bytes of an encoded node: the type, the data,
+// the number of attributes and the number of children take at least one byte each.
+const minSnapshotNodeSize = 4
+
+// readCount reads a varint used as number of items. Every item takes at least size bytes,
+// so counts larger than the remaining input allows are rejected before anything is allocated.
+func (s *snapshotReader) readCount(size int) int {
+	v := s.readUvarint()
+	if v > uint64((len(s.data)-s.pos)/size) {
+		s.fail("count exceeds input")
+		return 0
+	}
+	return int(v)
+}
+
+// readString reads a string prefixed by its length.
+func (s *snapshotReader) readString() string {
+	length := s.readCount(1)
+	if s.err != nil {
+		return ""
+	}
+	str := s.data[s.pos : s.pos+length]
+	s.pos += length
+	return str
+}
@@ -0,0 +1,126 @@
+package goDOM_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/richi0/goDOM"
+)
+
+func snapshot(t testing.TB, dom *goDOM.DOM) []byte {
+	t.Helper()
+	var out bytes.Buffer
+	if err := dom.WriteSnapshot(&out); err != nil {
+		t.Fatal("Unexpected snapshot error:", err)
+	}
+	return out.Bytes()
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	for _, dom := range []*goDOM.DOM{createTestDOM(), createDOMFromString(renderTestHTML)} {
+		data := snapshot(t, dom)
+		loaded, err := goDOM.ReadSnapshot(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal("Unexpected snapshot error:", err)
+		}
+		expected, _ := dom.Render()
+		if rendered, _ := loaded.Render(); rendered != expected {
+			t.Error("Expected the loaded DOM to render like the original")
+		}
+		if loaded.Hash() != dom.Hash() {
+			t.Error("Expected the loaded DOM to have the same hash")
+		}
+		if !bytes.Equal(snapshot(t, loaded), data) {
+			t.Error("Expected the loaded DOM to have the same snapshot")
+		}
+	}
+}
+
+func TestSnapshotFragment(t *testing.T) {
+	dom := createDOMFromString(`<template><i>x</i></template>`)
+	loaded, err := goDOM.ReadSnapshot(bytes.NewReader(snapshot(t, dom.GetElementsByTagName("template")[0].TemplateContent())))
+	if err != nil {
+		t.Fatal("Unexpected snapshot error:", err)
+	}
+	if rendered, _ := loaded.Render(); loaded.TagName() != "fragment" || rendered != "<i>x</i>" {
+		t.Error("Unexpected fragment:", loaded.TagName(), rendered)
+	}
+}
+
+func TestSnapshotCorruption(t *testing.T) {
+	data := snapshot(t, createDOMFromString(`<p class="a">text</p>`))
+	version := bytes.Clone(data)
+	version[5] = 99
+	flipped := bytes.Clone(data)
+	flipped[len(flipped)/2] ^= 0xff
+	for name, input := range map[string][]byte{
+		"empty":     nil,
+		"magic":     []byte("HTML!" + string(data[5:])),
+		"version":   version,
+		"flipped":   flipped,
+		"truncated": data[:len(data)-10],
+	} {
+		if _, err := goDOM.ReadSnapshot(bytes.NewReader(input)); !errors.Is(err, goDOM.ErrInvalidSnapshot) {
+			t.Errorf("%s: expected ErrInvalidSnapshot but got %v", name, err)
+		}
+	}
+}
+
+func TestSnapshotNodeCount(t *testing.T) {
+	// A snapshot with a valid checksum which claims 2000 nodes in 4096 bytes, less than a node needs.
+	body := append(bytes.Clone(snapshot(t, createDOMFromString(`x`))[:6]), 0, 0, 0, 0xd0, 0x0f)
+	body = append(body, make([]byte, 4096)...)
+	data := binary.LittleEndian.AppendUint32(body, crc32.ChecksumIEEE(body))
+	if _, err := goDOM.ReadSnapshot(bytes.NewReader(data)); !errors.Is(err, goDOM.ErrInvalidSnapshot) || !strings.Contains(err.Error(), "count exceeds input") {
+		t.Error("Expected ErrInvalidSnapshot for a node count exceeding the input but got", err)
+	}
+}
+
+func TestSnapshotLimits(t *testing.T) {
+	data := snapshot(t, createTestDOM())
+	if _, err := goDOM.ReadSnapshotWithLimits(bytes.NewReader(data), goDOM.Limits{MaxNodes: 1000000, MaxDepth: 100}); err != nil {
+		t.Fatal("Unexpected read error:", err)
+	}
+	tests := []struct {
+		limits   goDOM.Limits
+		expected error
+	}{
+		{goDOM.Limits{MaxInputBytes: 100}, goDOM.ErrInputTooLarge},
+		{goDOM.Limits{MaxNodes: 10}, goDOM.ErrTooManyNodes},
+		{goDOM.Limits{MaxDepth: 3}, goDOM.ErrTooDeep},
+		{goDOM.Limits{MaxAttributeLength: 1}, goDOM.ErrAttributeTooLong},
+	}
+	for _, test := range tests {
+		if _, err := goDOM.ReadSnapshotWithLimits(bytes.NewReader(data), test.limits); !errors.Is(err, test.expected) {
+			t.Errorf("Expected %v for %+v but got %v", test.expected, test.limits, err)
+		}
+	}
+}
+
+func BenchmarkReadSnapshot(b *testing.B) {
+	data := snapshot(b, createTestDOM())
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := goDOM.ReadSnapshot(bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParse(b *testing.B) {
+	source, err := os.ReadFile("test_data/index.html")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := goDOM.New(strings.NewReader(string(source))); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
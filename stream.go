@@ -0,0 +1,233 @@
+package goDOM
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// ErrStopStream can be returned by a handler to stop Stream without an error.
+var ErrStopStream = errors.New("goDOM: stop stream")
+
+// StreamHandlers are the handlers called by Stream. Nil handlers are skipped.
+//
+// Every handler receives the open ancestors of the event, starting with the outermost element.
+// The slice is only valid during the call and must not be retained.
+// A handler error stops Stream and is returned by it, except for ErrStopStream.
+type StreamHandlers struct {
+	// StartElement is called for every start tag.
+	StartElement func(e *StreamElement, ancestors []*StreamElement) error
+	// EndElement is called when an element is closed, whether by an end tag, implicitly or at the end of the input.
+	EndElement func(e *StreamElement, ancestors []*StreamElement) error
+	// Text is called with the unescaped content of every text token.
+	Text func(text string, ancestors []*StreamElement) error
+	// Comment is called with the content of every comment.
+	Comment func(comment string, ancestors []*StreamElement) error
+}
+
+// A StreamElement is an element in the stream of Stream.
+type StreamElement struct {
+	node        *html.Node
+	materialize func(*DOM) error
+}
+
+// openNode returns the node of the element.
+func (e *StreamElement) openNode() *html.Node {
+	return e.node
+}
+
+// TagName returns the tag name of the element.
+func (e *StreamElement) TagName() string {
+	return e.node.Data
+}
+
+// Attributes returns a map of all attributes of the element.
+func (e *StreamElement) Attributes() map[string]string {
+	attributes := make(map[string]string)
+	for _, attr := range e.node.Attr {
+		attributes[attr.Key] = attr.Val
+	}
+	return attributes
+}
+
+// GetAttribute returns the value of the attribute or the empty string if the element does not have it.
+func (e *StreamElement) GetAttribute(key string) string {
+	return nodeAttribute(e.node, key)
+}
+
+// HasAttribute returns a boolean value indicating whether the element has the attribute.
+func (e *StreamElement) HasAttribute(key string) bool {
+	for _, attr := range e.node.Attr {
+		if attr.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Materialize requests the subtree of the element as a DOM object. It may only be called from StartElement.
+//
+// The element and its content are buffered and parsed once the element is closed, then fn is called with
+// the element before EndElement. No events are emitted for the content of the element.
+func (e *StreamElement) Materialize(fn func(*DOM) error) {
+	e.materialize = fn
+}
+
+// Stream reads HTML from r and calls the handlers for the tokens of the input.
+//
+// Unlike New, Stream does not build a tree: only the current token, the stack of open elements and the
+// subtrees requested by Materialize are kept in memory. Elements are reported as they appear in the source.
+// Elements without an end tag, like li and p, are closed like the HTML parser closes them, but other
+// corrections the parser applies to misnested markup are not. The stack is limited to a depth of 512 elements.
+// This function is not part of the Javascript Document interface.
+func Stream(r io.Reader, handlers StreamHandlers) error {
+	err := stream(r, handlers)
+	if errors.Is(err, ErrStopStream) {
+		return nil
+	}
+	return err
+}
+
+// stream implements Stream.
+func stream(r io.Reader, handlers StreamHandlers) error {
+	z := html.NewTokenizer(r)
+	stack := make([]*StreamElement, 0)
+	// capture buffers the source of the element being materialized at stack[captured].
+	var capture *bytes.Buffer
+	captured := -1
+	closeElement := func() error {
+		e := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if len(stack) == captured {
+			dom, err := materialize(capture.Bytes(), stack)
+			capture, captured = nil, -1
+			if err != nil {
+				return err
+			}
+			if err := e.materialize(dom); err != nil {
+				return err
+			}
+		} else if capture != nil {
+			return nil
+		}
+		if handlers.EndElement != nil {
+			return handlers.EndElement(e, stack)
+		}
+		return nil
+	}
+	for {
+		tokenType := z.Next()
+		if tokenType == html.ErrorToken {
+			if err := z.Err(); err != io.EOF {
+				return err
+			}
+			for len(stack) > 0 {
+				if err := closeElement(); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		if tokenType == html.StartTagToken || tokenType == html.SelfClosingTagToken {
+			// The tokenizer lowercases and unescapes in place, so the raw bytes are copied first.
+			raw := append([]byte(nil), z.Raw()...)
+			token := z.Token()
+			// A start tag which closes the materialized element is not part of its source.
+			for closeTo := startTagCloses(stack, token.Data); len(stack) > closeTo; {
+				if err := closeElement(); err != nil {
+					return err
+				}
+			}
+			parent := func() *html.Node {
+				if len(stack) == 0 {
+					return nil
+				}
+				return stack[len(stack)-1].node
+			}
+			node := &html.Node{Type: html.ElementNode, Data: token.Data, DataAtom: token.DataAtom, Attr: token.Attr}
+			node.Namespace = tokenNamespace(parent(), token.Data)
+			// Like the parser, only foreign elements are closed by the self-closing flag.
+			opens := !isVoidElement(token.Data) && (tokenType == html.StartTagToken || node.Namespace == "")
+			if opens && len(stack) >= maxOpenElements {
+				if err := closeElement(); err != nil {
+					return err
+				}
+				node.Namespace = tokenNamespace(parent(), token.Data)
+			}
+			if capture != nil {
+				capture.Write(raw)
+			}
+			e := &StreamElement{node: node}
+			if capture == nil && handlers.StartElement != nil {
+				if err := handlers.StartElement(e, stack); err != nil {
+					return err
+				}
+			}
+			if capture == nil && e.materialize != nil {
+				capture, captured = &bytes.Buffer{}, len(stack)
+				capture.Write(raw)
+			}
+			stack = append(stack, e)
+			if !opens {
+				if err := closeElement(); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if capture != nil {
+			capture.Write(z.Raw())
+		}
+		switch tokenType {
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			index := endTagCloses(stack, string(name))
+			// Elements without an end tag are closed implicitly by the end tag of an ancestor.
+			for index >= 0 && len(stack) > index {
+				if err := closeElement(); err != nil {
+					return err
+				}
+			}
+		case html.TextToken:
+			if capture == nil && handlers.Text != nil {
+				if err := handlers.Text(string(z.Text()), stack); err != nil {
+					return err
+				}
+			}
+		case html.CommentToken:
+			if capture == nil && handlers.Comment != nil {
+				if err := handlers.Comment(string(z.Text()), stack); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// materialize parses the source of an element in the context of its ancestors
+// and returns the element as the child of a fragment.
+func materialize(source []byte, ancestors []*StreamElement) (*DOM, error) {
+	context := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	if len(ancestors) > 0 {
+		parent := ancestors[len(ancestors)-1].node
+		context = &html.Node{Type: html.ElementNode, Data: parent.Data, DataAtom: parent.DataAtom}
+	}
+	nodes, err := html.ParseFragment(bytes.NewReader(source), context)
+	if err != nil {
+		return nil, err
+	}
+	root := &html.Node{Type: html.DocumentNode}
+	for _, node := range nodes {
+		root.AppendChild(node)
+	}
+	doc := &document{encoding: "utf-8"}
+	for node := root.FirstChild; node != nil; node = node.NextSibling {
+		if node.Type == html.ElementNode {
+			return newDOM(node, doc), nil
+		}
+	}
+	return newDOM(root, doc), nil
+}
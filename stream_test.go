@@ -0,0 +1,205 @@
+package goDOM_test
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/richi0/goDOM"
+)
+
+func TestStreamEvents(t *testing.T) {
+	input := `<div id="a"><!-- note --><p>one<p>two &amp; <b>three</b></div><br/>`
+	events := make([]string, 0)
+	path := func(ancestors []*goDOM.StreamElement) string {
+		names := make([]string, 0)
+		for _, e := range ancestors {
+			names = append(names, e.TagName())
+		}
+		return strings.Join(names, "/")
+	}
+	err := goDOM.Stream(strings.NewReader(input), goDOM.StreamHandlers{
+		StartElement: func(e *goDOM.StreamElement, ancestors []*goDOM.StreamElement) error {
+			events = append(events, "start "+path(ancestors)+" "+e.TagName()+e.GetAttribute("id"))
+			return nil
+		},
+		EndElement: func(e *goDOM.StreamElement, ancestors []*goDOM.StreamElement) error {
+			events = append(events, "end "+e.TagName())
+			return nil
+		},
+		Text: func(text string, ancestors []*goDOM.StreamElement) error {
+			events = append(events, "text "+path(ancestors)+" "+text)
+			return nil
+		},
+		Comment: func(comment string, ancestors []*goDOM.StreamElement) error {
+			events = append(events, "comment"+comment)
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatal("Unexpected stream error:", err)
+	}
+	expected := []string{
+		"start  diva",
+		"comment note ",
+		"start div p",
+		"text div/p one",
+		"end p",
+		"start div p",
+		"text div/p two & ",
+		"start div/p b",
+		"text div/p/b three",
+		"end b",
+		"end p",
+		"end div",
+		"start  br",
+		"end br",
+	}
+	if strings.Join(events, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected events\n%s\nbut got\n%s", strings.Join(expected, "\n"), strings.Join(events, "\n"))
+	}
+}
+
+func TestStreamMaterialize(t *testing.T) {
+	input := `<table><tbody><tr class="row"><td>1</td><td>a</td></tr><tr class="row"><td>2<td>b</tr></tbody></table><span>x</span>`
+	rows := make([]string, 0)
+	ends := 0
+	err := goDOM.Stream(strings.NewReader(input), goDOM.StreamHandlers{
+		StartElement: func(e *goDOM.StreamElement, ancestors []*goDOM.StreamElement) error {
+			if e.TagName() == "td" {
+				t.Error("Expected no events inside materialized elements")
+			}
+			if e.GetAttribute("class") == "row" {
+				e.Materialize(func(dom *goDOM.DOM) error {
+					cells := dom.GetElementsByTagName("td")
+					rows = append(rows, dom.TagName()+":"+cells[0].Text(false)+cells[1].Text(false))
+					return nil
+				})
+			}
+			return nil
+		},
+		EndElement: func(e *goDOM.StreamElement, ancestors []*goDOM.StreamElement) error {
+			if e.TagName() == "tr" {
+				ends++
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatal("Unexpected stream error:", err)
+	}
+	if strings.Join(rows, ",") != "tr:1a,tr:2b" {
+		t.Error("Expected materialized rows but got", rows)
+	}
+	if ends != 2 {
+		t.Error("Expected end events of materialized elements but got", ends)
+	}
+}
+
+func TestStreamImpliedEndTags(t *testing.T) {
+	input := `<ul><li class="m">a<li>b<li class="m">c</ul><dl><dt>t<dd>d</dl><table><tr><td>1<td>2<tr><td>3</table><svg><rect/><circle/></svg>`
+	materialized := make([]string, 0)
+	paths := make([]string, 0)
+	err := goDOM.Stream(strings.NewReader(input), goDOM.StreamHandlers{
+		StartElement: func(e *goDOM.StreamElement, ancestors []*goDOM.StreamElement) error {
+			names := make([]string, 0)
+			for _, a := range ancestors {
+				names = append(names, a.TagName())
+			}
+			paths = append(paths, strings.Join(append(names, e.TagName()), "/"))
+			if e.GetAttribute("class") == "m" {
+				e.Materialize(func(dom *goDOM.DOM) error {
+					materialized = append(materialized, dom.TagName()+":"+dom.Text(false))
+					return nil
+				})
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatal("Unexpected stream error:", err)
+	}
+	if strings.Join(materialized, ",") != "li:a,li:c" {
+		t.Error("Expected materialized list items but got", materialized)
+	}
+	expected := "ul ul/li ul/li ul/li dl dl/dt dl/dd table table/tr table/tr/td table/tr/td table/tr table/tr/td svg svg/rect svg/circle"
+	if strings.Join(paths, " ") != expected {
+		t.Errorf("Expected paths\n%s\nbut got\n%s", expected, strings.Join(paths, " "))
+	}
+}
+
+func TestStreamDepth(t *testing.T) {
+	depth := 0
+	err := goDOM.Stream(strings.NewReader(strings.Repeat("<div>", 20000)), goDOM.StreamHandlers{
+		StartElement: func(e *goDOM.StreamElement, ancestors []*goDOM.StreamElement) error {
+			depth = max(depth, len(ancestors))
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatal("Unexpected stream error:", err)
+	}
+	if depth >= 512 {
+		t.Error("Expected the stack of open elements to be limited but got a depth of", depth)
+	}
+}
+
+func TestStreamStop(t *testing.T) {
+	errFailed := errors.New("failed")
+	count := 0
+	err := goDOM.Stream(strings.NewReader("<a>1</a><a>2</a><a>3</a>"), goDOM.StreamHandlers{
+		Text: func(text string, ancestors []*goDOM.StreamElement) error {
+			count++
+			if text == "2" {
+				return goDOM.ErrStopStream
+			}
+			return nil
+		},
+	})
+	if err != nil || count != 2 {
+		t.Error("Expected stream to stop without error but got", err, count)
+	}
+	err = goDOM.Stream(strings.NewReader("<a>1</a>"), goDOM.StreamHandlers{
+		StartElement: func(e *goDOM.StreamElement, ancestors []*goDOM.StreamElement) error {
+			return errFailed
+		},
+	})
+	if !errors.Is(err, errFailed) {
+		t.Error("Expected handler error but got", err)
+	}
+}
+
+func TestStreamDocument(t *testing.T) {
+	file, err := os.Open("test_data/index.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	links := 0
+	var title string
+	err = goDOM.Stream(file, goDOM.StreamHandlers{
+		StartElement: func(e *goDOM.StreamElement, ancestors []*goDOM.StreamElement) error {
+			if e.TagName() == "a" {
+				links++
+			}
+			if e.TagName() == "h1" {
+				e.Materialize(func(dom *goDOM.DOM) error {
+					title = dom.InnerText()
+					return nil
+				})
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatal("Unexpected stream error:", err)
+	}
+	dom := createTestDOM()
+	if links != len(dom.GetElementsByTagName("a")) {
+		t.Error("Expected", len(dom.GetElementsByTagName("a")), "links but got", links)
+	}
+	if expected := dom.GetElementsByTagName("h1")[0].InnerText(); title != expected {
+		t.Errorf("Expected title %q but got %q", expected, title)
+	}
+}
@@ -0,0 +1,75 @@
+package goDOM
+
+import (
+	"golang.org/x/net/html"
+)
+
+// TemplateContent returns the content of a <template> element as a document fragment,
+// or nil if the element is not a template.
+//
+// The content of templates is inert: unless the document was parsed with IncludeTemplateContent set,
+// queries like GetElementsByTagName do not descend into templates. Queries on the returned fragment
+// search the content. Since the parser keeps the content as children of the template element,
+// navigation methods like Children still return it and the fragment holds a copy of the children:
+// unlike in Javascript, changes to the fragment do not change the template.
+//
+// See Javascript equivalent: https://developer.mozilla.org/en-US/docs/Web/API/HTMLTemplateElement/content
+func (d *DOM) TemplateContent() *DOM {
+	if !d.isElementNode() || !isTemplate(d.node, d.isXML()) {
+		return nil
+	}
+	return d.templateFragment(d.node)
+}
+
+// ShadowRoot returns the content of the declarative shadow root of the element as a document fragment,
+// or nil if the element has none.
+//
+// A declarative shadow root is the first child <template> with a shadowrootmode attribute. Unlike in
+// Javascript, closed shadow roots are returned as well. Like TemplateContent, the fragment holds a copy of
+// the children of the template, so changes to the fragment do not change the element.
+//
+// See Javascript equivalent: https://developer.mozilla.org/en-US/docs/Web/API/Element/shadowRoot
+func (d *DOM) ShadowRoot() *DOM {
+	if !d.isElementNode() {
+		return nil
+	}
+	xml := d.isXML()
+	for child := d.node.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type != html.ElementNode {
+			continue
+		}
+		if isTemplate(child, xml) {
+			switch nodeAttribute(child, "shadowrootmode") {
+			case "open", "closed":
+				return d.templateFragment(child)
+			}
+		}
+	}
+	return nil
+}
+
+// templateFragment returns a document fragment with copies of the children of the template.
+func (d *DOM) templateFragment(template *html.Node) *DOM {
+	fragment := &html.Node{Type: html.DocumentNode}
+	for child := template.FirstChild; child != nil; child = child.NextSibling {
+		fragment.AppendChild(cloneDeep(child))
+	}
+	return &DOM{node: fragment, doc: d.doc, fragment: true}
+}
+
+// isTemplate returns a boolean value indicating whether the node is an HTML <template> element.
+func isTemplate(node *html.Node, xml bool) bool {
+	return node.Type == html.ElementNode && nodeLocalName(node, xml) == "template" && nodeNamespaceURI(node, xml) == NamespaceHTML
+}
+
+// isInert returns a boolean value indicating whether walks of the DOM skip the children of the node.
+// The children of templates are skipped, unless the DOM is the content of the template.
+func (d *DOM) isInert(node *html.Node) bool {
+	if d.doc != nil && d.doc.options.IncludeTemplateContent {
+		return false
+	}
+	if d.fragment && node == d.node {
+		return false
+	}
+	return isTemplate(node, d.isXML())
+}
@@ -0,0 +1,92 @@
+package goDOM_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/richi0/goDOM"
+)
+
+const templateTestHTML = `<ul id="list"><li>live</li></ul>` +
+	`<template id="row"><li class="item">template <b>content</b></li><template id="nested"><li>nested</li></template></template>` +
+	`<div id="host"><template shadowrootmode="open"><style>p{}</style><p id="shadow">shadow</p><slot></slot></template><p>light</p></div>`
+
+func TestTemplateContent(t *testing.T) {
+	dom := createDOMFromString(templateTestHTML)
+	if len(dom.GetElementsByTagName("li")) != 1 || dom.GetElementById("shadow") != nil {
+		t.Error("Expected template content to be excluded from document queries")
+	}
+	if len(dom.GetElementsByTagName("template")) != 2 {
+		t.Error("Expected top-level templates to be found")
+	}
+	if strings.Contains(dom.GetElementById("row").Text(true), "template") {
+		t.Error("Expected template text to be excluded from the template element")
+	}
+	content := dom.GetElementById("row").TemplateContent()
+	if content == nil || content.TagName() != "fragment" {
+		t.Fatal("Expected a fragment for the template")
+	}
+	items := content.GetElementsByTagName("li")
+	if len(items) != 1 || items[0].ClassName() != "item" {
+		t.Error("Expected only the direct content of the template but got", len(items))
+	}
+	if bold := items[0].GetElementsByTagName("b"); len(bold) != 1 {
+		t.Error("Expected the descendants of the template content to be copied")
+	}
+	if content.GetElementById("row") != nil {
+		t.Error("Expected the template element not to be part of its content")
+	}
+	if children := content.Children(); len(children) != 2 || children[0].Parent().IsSameNode(dom.GetElementById("row")) {
+		t.Error("Expected the content children to belong to the fragment")
+	}
+	if dom.GetElementById("row").Contains(content) || content.Contains(dom.GetElementById("row")) {
+		t.Error("Expected the fragment to be separate from the template element")
+	}
+	rendered, _ := content.Render()
+	if rendered != `<li class="item">template <b>content</b></li><template id="nested"><li>nested</li></template>` {
+		t.Error("Unexpected rendering of the template content:", rendered)
+	}
+	nested := content.GetElementById("nested").TemplateContent()
+	if text := nested.Text(true); text != "nested" {
+		t.Errorf("Expected nested template content but got %q", text)
+	}
+	if dom.GetElementById("list").TemplateContent() != nil {
+		t.Error("Expected no template content for other elements")
+	}
+}
+
+func TestIncludeTemplateContent(t *testing.T) {
+	dom, err := goDOM.NewWithOptions(strings.NewReader(templateTestHTML), goDOM.ParseOptions{IncludeTemplateContent: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dom.GetElementsByTagName("li")) != 3 || dom.GetElementById("shadow") == nil {
+		t.Error("Expected template content to be included in document queries")
+	}
+}
+
+func TestShadowRoot(t *testing.T) {
+	dom := createDOMFromString(templateTestHTML)
+	host := dom.GetElementById("host")
+	root := host.ShadowRoot()
+	if root == nil {
+		t.Fatal("Expected a declarative shadow root")
+	}
+	if root.GetElementById("shadow") == nil || len(root.GetElementsByTagName("slot")) != 1 {
+		t.Error("Expected shadow content in the shadow root")
+	}
+	if len(host.GetElementsByTagName("p")) != 1 {
+		t.Error("Expected shadow content to be excluded from the light tree")
+	}
+	if dom.GetElementById("row").ShadowRoot() != nil || dom.GetElementById("list").ShadowRoot() != nil {
+		t.Error("Expected no shadow root without shadowrootmode")
+	}
+	rendered, _ := root.RenderXML()
+	if !strings.HasPrefix(rendered, `<style xmlns="http://www.w3.org/1999/xhtml">`) {
+		t.Error("Expected namespace declarations for the fragment content but got", rendered)
+	}
+	root.GetElementById("shadow").SetAttribute("class", "changed")
+	if html, _ := dom.GetElementById("host").Render(); strings.Contains(html, "changed") {
+		t.Error("Expected the shadow root to be a copy of the template content")
+	}
+}
@@ -0,0 +1,135 @@
+package goDOM
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// TextMatchOptions configures how GetElementsByTextMatch compares the text of an element.
+type TextMatchOptions struct {
+	// MatchType is one of MatchTypeExact, MatchTypeContains, MatchTypePrefix or MatchTypeSuffix.
+	MatchType int
+	// IgnoreCase compares the text case-insensitively.
+	IgnoreCase bool
+	// NormalizeWhitespace trims the text and collapses runs of whitespace to a single space.
+	NormalizeWhitespace bool
+	// FoldUnicode applies Unicode case folding and NFKC normalization, so for example
+	// "STRASSE" matches "straße" and "ﬁ" matches "fi".
+	FoldUnicode bool
+	// Regexp, if set, is matched against the text instead of the text parameter.
+	// MatchType, IgnoreCase and FoldUnicode are ignored for regular expressions.
+	Regexp *regexp.Regexp
+	// Descendants matches against the concatenated text of all descendants instead of
+	// the element's own text nodes, so that <a><b>Buy</b> now</a> matches "Buy now".
+	Descendants bool
+	// DeepestOnly drops every matching element which contains another matching element.
+	DeepestOnly bool
+}
+
+// GetElementsByTextMatch returns a slice of elements whose text matches the given text according to opts.
+// This method is not part of the Javascript Document interface.
+func (d *DOM) GetElementsByTextMatch(text string, opts TextMatchOptions) []*DOM {
+	elements, _ := d.GetElementsByTextMatchContext(context.Background(), text, opts)
+	return elements
+}
+
+// GetElementsByTextMatchContext is like GetElementsByTextMatch but returns ctx.Err() if the context is done
+// before all elements were checked.
+// This method is not part of the Javascript Document interface.
+func (d *DOM) GetElementsByTextMatchContext(ctx context.Context, text string, opts TextMatchOptions) ([]*DOM, error) {
+	elements := make([]*DOM, 0)
+	query := opts.normalize(text)
+	nodes, err := d.getFlatElementListContext(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	for i, node := range nodes {
+		if err := checkContext(ctx, i); err != nil {
+			return nil, err
+		}
+		var content string
+		if opts.Descendants {
+			content = node.textContent()
+		} else {
+			content = node.Text(false)
+		}
+		if opts.matches(opts.normalize(content), query) {
+			elements = append(elements, node)
+		}
+	}
+	if opts.DeepestOnly {
+		elements = deepestOnly(elements)
+	}
+	return elements, nil
+}
+
+// GetElementsByTextRegexp returns a slice of elements whose text matches the given regular expression.
+// This method is not part of the Javascript Document interface.
+func (d *DOM) GetElementsByTextRegexp(re *regexp.Regexp, opts TextMatchOptions) []*DOM {
+	opts.Regexp = re
+	return d.GetElementsByTextMatch("", opts)
+}
+
+// normalize applies the configured normalizations to s.
+func (opts TextMatchOptions) normalize(s string) string {
+	if opts.NormalizeWhitespace {
+		s = strings.Join(strings.Fields(s), " ")
+	}
+	if opts.Regexp != nil {
+		return s
+	}
+	if opts.FoldUnicode {
+		s = cases.Fold().String(norm.NFKC.String(s))
+	} else if opts.IgnoreCase {
+		s = strings.ToLower(s)
+	}
+	return s
+}
+
+// matches returns a boolean value indicating whether the normalized content matches the normalized query.
+func (opts TextMatchOptions) matches(content, query string) bool {
+	if opts.Regexp != nil {
+		return opts.Regexp.MatchString(content)
+	}
+	switch opts.MatchType {
+	case MatchTypeContains:
+		return strings.Contains(content, query)
+	case MatchTypePrefix:
+		return strings.HasPrefix(content, query)
+	case MatchTypeSuffix:
+		return strings.HasSuffix(content, query)
+	default:
+		return content == query
+	}
+}
+
+// textContent returns the concatenated data of all descendant text nodes.
+//
+// See Javascript equivalent: https://developer.mozilla.org/en-US/docs/Web/API/Node/textContent
+func (d *DOM) textContent() string {
+	var sb strings.Builder
+	for _, node := range d.getFlatNodeList(false) {
+		if node.node.Type == html.TextNode {
+			sb.WriteString(node.node.Data)
+		}
+	}
+	return sb.String()
+}
+
+// deepestOnly drops every element which contains another element of the slice.
+// The elements must be in document order.
+func deepestOnly(elements []*DOM) []*DOM {
+	deepest := make([]*DOM, 0)
+	for i, element := range elements {
+		if i+1 < len(elements) && element.Contains(elements[i+1]) {
+			continue
+		}
+		deepest = append(deepest, element)
+	}
+	return deepest
+}
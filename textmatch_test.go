@@ -0,0 +1,50 @@
+package goDOM_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/richi0/goDOM"
+)
+
+func TestGetElementsByTextMatch(t *testing.T) {
+	dom := createDOMFromString(`<div><a id="buy"><b>Buy</b> now</a><p id="p">  Hello
+	World  </p><span id="s">STRASSE</span></div>`)
+	cases := []struct {
+		text     string
+		opts     goDOM.TextMatchOptions
+		expected int
+	}{
+		{"Buy now", goDOM.TextMatchOptions{}, 0},
+		{"Buy now", goDOM.TextMatchOptions{Descendants: true}, 1},
+		{"Buy", goDOM.TextMatchOptions{MatchType: goDOM.MatchTypePrefix, Descendants: true}, 5},
+		{"Buy", goDOM.TextMatchOptions{MatchType: goDOM.MatchTypePrefix, Descendants: true, DeepestOnly: true}, 1},
+		{"now", goDOM.TextMatchOptions{MatchType: goDOM.MatchTypeSuffix}, 1},
+		{"hello world", goDOM.TextMatchOptions{NormalizeWhitespace: true, IgnoreCase: true}, 1},
+		{"hello world", goDOM.TextMatchOptions{IgnoreCase: true}, 0},
+		{"straße", goDOM.TextMatchOptions{FoldUnicode: true}, 1},
+		{"strasse", goDOM.TextMatchOptions{IgnoreCase: true}, 1},
+	}
+	for _, c := range cases {
+		elements := dom.GetElementsByTextMatch(c.text, c.opts)
+		if len(elements) != c.expected {
+			t.Errorf("Expected %d elements for %q %+v but found %d", c.expected, c.text, c.opts, len(elements))
+		}
+	}
+	elements := dom.GetElementsByTextMatch("Buy", goDOM.TextMatchOptions{Descendants: true, DeepestOnly: true})
+	if len(elements) != 1 || elements[0].TagName() != "b" {
+		t.Error("Expected the deepest element to be b")
+	}
+}
+
+func TestGetElementsByTextRegexp(t *testing.T) {
+	dom := createTestDOM()
+	elements := dom.GetElementsByTextRegexp(regexp.MustCompile(`^Enumerated\s+types$`), goDOM.TextMatchOptions{})
+	if len(elements) != 2 {
+		t.Error("Expected 2 elements but found", len(elements))
+	}
+	elements = dom.GetElementsByTextRegexp(regexp.MustCompile(`(?i)^enumerated types$`), goDOM.TextMatchOptions{Descendants: true, DeepestOnly: true})
+	if len(elements) == 0 {
+		t.Error("Expected elements but found none")
+	}
+}
@@ -0,0 +1,366 @@
+package goDOM
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+)
+
+// ErrMalformedXML is returned by NewXML if the input is not well-formed XML.
+var ErrMalformedXML = errors.New("goDOM: malformed XML")
+
+// NewXML returns the parsed tree for the XML from the given Reader as a DOM object.
+//
+// Unlike New, NewXML does not repair the markup: self-closing tags close their element, CDATA sections
+// become text and names keep their case. The input must be well-formed and namespace-well-formed,
+// otherwise an error wrapping ErrMalformedXML is returned. The named character references of HTML,
+// like &nbsp;, are accepted so that XHTML documents can be parsed without their DTD.
+//
+// Elements keep their qualified name, like "svg:rect", and their namespace URI. Attributes keep their
+// qualified name, including namespace declarations like "xmlns:svg". The XML declaration is dropped,
+// other processing instructions are kept as raw nodes. Use RenderXML to serialize the document.
+func NewXML(r io.Reader) (*DOM, error) {
+	doc := &document{encoding: "utf-8", xml: true}
+	decoder := xml.NewDecoder(bufio.NewReader(r))
+	decoder.Entity = xml.HTMLEntity
+	decoder.CharsetReader = func(label string, input io.Reader) (io.Reader, error) {
+		_, name := charset.Lookup(label)
+		if name == "" {
+			return nil, fmt.Errorf("goDOM: unsupported encoding %q", label)
+		}
+		doc.encoding = name
+		return charset.NewReaderLabel(label, input)
+	}
+	root := &html.Node{Type: html.DocumentNode}
+	parent := root
+	// scopes holds the namespace bindings of the open elements, the first scope holds the implicit bindings.
+	scopes := []map[string]string{{"xml": NamespaceXML, "xmlns": NamespaceXMLNS}}
+	lookup := func(prefix string) (string, bool) {
+		for i := len(scopes) - 1; i >= 0; i-- {
+			if uri, ok := scopes[i][prefix]; ok {
+				return uri, true
+			}
+		}
+		return "", false
+	}
+	malformed := func(format string, args ...any) error {
+		line, column := decoder.InputPos()
+		return fmt.Errorf("%w: %d:%d: %s", ErrMalformedXML, line, column, fmt.Sprintf(format, args...))
+	}
+	for {
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var syntaxError *xml.SyntaxError
+			if errors.As(err, &syntaxError) {
+				return nil, fmt.Errorf("%w: line %d: %s", ErrMalformedXML, syntaxError.Line, syntaxError.Msg)
+			}
+			return nil, err
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			if parent == root && hasElementChild(root) {
+				return nil, malformed("more than one root element")
+			}
+			scope := make(map[string]string)
+			attrs := make([]html.Attribute, 0, len(token.Attr))
+			seen := make(map[string]bool)
+			for _, attr := range token.Attr {
+				name := qualifiedName(attr.Name)
+				if seen[name] {
+					return nil, malformed("duplicate attribute %q", name)
+				}
+				seen[name] = true
+				if attr.Name.Space == "" && attr.Name.Local == "xmlns" {
+					scope[""] = attr.Value
+				} else if attr.Name.Space == "xmlns" {
+					if attr.Value == "" {
+						return nil, malformed("empty namespace for prefix %q", attr.Name.Local)
+					}
+					scope[attr.Name.Local] = attr.Value
+				}
+				attrs = append(attrs, html.Attribute{Key: name, Val: attr.Value})
+			}
+			scopes = append(scopes, scope)
+			for _, attr := range token.Attr {
+				if attr.Name.Space != "" && attr.Name.Space != "xmlns" {
+					if _, ok := lookup(attr.Name.Space); !ok {
+						return nil, malformed("unbound namespace prefix %q", attr.Name.Space)
+					}
+				}
+			}
+			namespace, ok := lookup(token.Name.Space)
+			if !ok && token.Name.Space != "" {
+				return nil, malformed("unbound namespace prefix %q", token.Name.Space)
+			}
+			element := &html.Node{Type: html.ElementNode, Data: qualifiedName(token.Name), Namespace: namespace, Attr: attrs}
+			parent.AppendChild(element)
+			parent = element
+		case xml.EndElement:
+			if parent == root || parent.Data != qualifiedName(token.Name) {
+				return nil, malformed("unexpected end tag </%s>", qualifiedName(token.Name))
+			}
+			parent = parent.Parent
+			scopes = scopes[:len(scopes)-1]
+		case xml.CharData:
+			if parent == root {
+				if len(bytes.TrimSpace(token)) > 0 {
+					return nil, malformed("text outside of the root element")
+				}
+				continue
+			}
+			if last := parent.LastChild; last != nil && last.Type == html.TextNode {
+				last.Data += string(token)
+			} else {
+				parent.AppendChild(&html.Node{Type: html.TextNode, Data: string(token)})
+			}
+		case xml.Comment:
+			parent.AppendChild(&html.Node{Type: html.CommentNode, Data: string(token)})
+		case xml.ProcInst:
+			if token.Target == "xml" {
+				continue
+			}
+			parent.AppendChild(&html.Node{Type: html.RawNode, Data: "<?" + token.Target + " " + string(token.Inst) + "?>"})
+		case xml.Directive:
+			if name, ok := strings.CutPrefix(string(token), "DOCTYPE"); ok && parent == root {
+				root.AppendChild(&html.Node{Type: html.DoctypeNode, Data: strings.TrimSpace(name)})
+			}
+		}
+	}
+	if parent != root {
+		return nil, malformed("element <%s> is never closed", parent.Data)
+	}
+	if !hasElementChild(root) {
+		return nil, malformed("missing root element")
+	}
+	return newDOM(root, doc), nil
+}
+
+// qualifiedName returns the name in the form "prefix:local" as written in the source.
+func qualifiedName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
+
+// hasElementChild returns a boolean value indicating whether the node has a child element.
+func hasElementChild(node *html.Node) bool {
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode {
+			return true
+		}
+	}
+	return false
+}
+
+// RenderXML returns a well-formed XML representation of the DOM.
+//
+// Documents parsed by New are written as XHTML, with namespace declarations for HTML, SVG and MathML elements.
+// Prefixes which HTML leaves undeclared, like in <x:y> or <p x:y="1">, are bound to the namespace of the element.
+// Elements without content are self-closing, text is escaped, characters which are not allowed in XML
+// are replaced by U+FFFD and "--" in comments is broken up. Attributes whose names are not XML names are
+// dropped. The XML declaration is written for documents, but not for document fragments.
+// This method is not part of the Javascript Document interface.
+func (d *DOM) RenderXML() (string, error) {
+	var sb strings.Builder
+	xml := d.isXML()
+	if d.node.Type == html.DocumentNode && !d.fragment {
+		sb.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	}
+	// The stack holds the nodes to write, nil marks the end tag of the element on top of ends.
+	stack := []*html.Node{d.node}
+	// outside is the parent of the elements written first.
+	outside := d.node.Parent
+	if d.fragment {
+		outside = d.node
+		stack = stack[:0]
+		for child := d.node.LastChild; child != nil; child = child.PrevSibling {
+			stack = append(stack, child)
+		}
+	}
+	ends := make([]*html.Node, 0)
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if node == nil {
+			sb.WriteString("</" + ends[len(ends)-1].Data + ">")
+			ends = ends[:len(ends)-1]
+			continue
+		}
+		switch node.Type {
+		case html.DocumentNode:
+			for child := node.LastChild; child != nil; child = child.PrevSibling {
+				stack = append(stack, child)
+			}
+		case html.DoctypeNode:
+			sb.WriteString(doctype(node))
+		case html.CommentNode:
+			data := xmlCharacters(node.Data)
+			for strings.Contains(data, "--") {
+				data = strings.ReplaceAll(data, "--", "- -")
+			}
+			if strings.HasSuffix(data, "-") {
+				data += " "
+			}
+			sb.WriteString("<!--" + data + "-->")
+		case html.TextNode:
+			sb.WriteString(escapeXMLText(node.Data))
+		case html.RawNode:
+			sb.WriteString(node.Data)
+		case html.ElementNode:
+			sb.WriteString("<" + node.Data)
+			attrs := node.Attr
+			if !xml {
+				attrs = xhtmlAttributes(node, outside)
+			}
+			for _, attr := range attrs {
+				if name := attributeName(attr); isQualifiedName(name) {
+					sb.WriteString(" " + name + `="` + escapeXMLAttribute(attr.Val) + `"`)
+				}
+			}
+			if node.FirstChild == nil {
+				sb.WriteString("/>")
+				continue
+			}
+			sb.WriteString(">")
+			ends = append(ends, node)
+			stack = append(stack, nil)
+			for child := node.LastChild; child != nil; child = child.PrevSibling {
+				stack = append(stack, child)
+			}
+		}
+	}
+	return sb.String(), nil
+}
+
+// xhtmlAttributes returns the attributes of an element of a document parsed as HTML together with the
+// namespace declarations its XML representation needs. The namespace of an element is declared if it
+// differs from the namespace of its parent or if the element is the top element written, that is its
+// parent is outside. Prefixes which are not declared by the element or the ancestors written are bound
+// to the namespace of the element.
+func xhtmlAttributes(node *html.Node, outside *html.Node) []html.Attribute {
+	attrs := make([]html.Attribute, 0, len(node.Attr)+2)
+	declared := false
+	prefixes := make([]string, 0)
+	if prefix, _, ok := strings.Cut(node.Data, ":"); ok {
+		prefixes = append(prefixes, prefix)
+	}
+	for _, attr := range node.Attr {
+		declared = declared || (attr.Namespace == "" && attr.Key == "xmlns")
+		switch prefix, _, ok := strings.Cut(attr.Key, ":"); {
+		case attr.Namespace == "xlink":
+			prefixes = append(prefixes, "xlink")
+		case attr.Namespace == "" && ok && prefix != "xml" && prefix != "xmlns" && isQualifiedName(attr.Key):
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	parent := node.Parent
+	top := parent == outside
+	if !declared && (top || parent == nil || parent.Type != html.ElementNode || parent.Namespace != node.Namespace) {
+		attrs = append(attrs, html.Attribute{Key: "xmlns", Val: nodeNamespaceURI(node, false)})
+	}
+	for _, attr := range node.Attr {
+		// An empty namespace cannot be bound to a prefix in XML.
+		if attr.Val == "" && (attr.Namespace == "xmlns" || (attr.Namespace == "" && strings.HasPrefix(attr.Key, "xmlns:"))) {
+			continue
+		}
+		attrs = append(attrs, attr)
+	}
+	for i, prefix := range prefixes {
+		if slices.Contains(prefixes[:i], prefix) || declaresPrefix(node, prefix, outside) {
+			continue
+		}
+		uri := nodeNamespaceURI(node, false)
+		if prefix == "xlink" {
+			uri = NamespaceXLink
+		}
+		attrs = append(attrs, html.Attribute{Namespace: "xmlns", Key: prefix, Val: uri})
+	}
+	return attrs
+}
+
+// declaresPrefix returns a boolean value indicating whether the element or one of its ancestors
+// inside of outside declares the prefix.
+func declaresPrefix(node *html.Node, prefix string, outside *html.Node) bool {
+	for ; node != nil && node != outside && node.Type == html.ElementNode; node = node.Parent {
+		for _, attr := range node.Attr {
+			if attr.Val != "" && ((attr.Namespace == "xmlns" && attr.Key == prefix) ||
+				(attr.Namespace == "" && attr.Key == "xmlns:"+prefix)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isQualifiedName returns a boolean value indicating whether the name is a valid XML name
+// with at most one colon, like "href" or "xlink:href".
+//
+// See https://www.w3.org/TR/xml-names/#NT-QName
+func isQualifiedName(name string) bool {
+	prefix, local, ok := strings.Cut(name, ":")
+	if !ok {
+		return isNCName(name)
+	}
+	return isNCName(prefix) && isNCName(local)
+}
+
+// isNCName returns a boolean value indicating whether the name is a valid XML name without a colon.
+//
+// See https://www.w3.org/TR/xml/#NT-Name
+func isNCName(name string) bool {
+	for i, r := range name {
+		switch {
+		case r == '_' || (r >= 'A' && r <= 'Z') || (r >= 'a' && r <= 'z') || (r >= 0xc0 && r <= 0xd6) ||
+			(r >= 0xd8 && r <= 0xf6) || (r >= 0xf8 && r <= 0x2ff) || (r >= 0x370 && r <= 0x37d) ||
+			(r >= 0x37f && r <= 0x1fff) || (r >= 0x200c && r <= 0x200d) || (r >= 0x2070 && r <= 0x218f) ||
+			(r >= 0x2c00 && r <= 0x2fef) || (r >= 0x3001 && r <= 0xd7ff) || (r >= 0xf900 && r <= 0xfdcf) ||
+			(r >= 0xfdf0 && r <= 0xfffd) || (r >= 0x10000 && r <= 0xeffff):
+		case i > 0 && (r == '-' || r == '.' || (r >= '0' && r <= '9') || r == 0xb7 ||
+			(r >= 0x300 && r <= 0x36f) || (r >= 0x203f && r <= 0x2040)):
+		default:
+			return false
+		}
+	}
+	return name != ""
+}
+
+// escapeXMLText escapes text content.
+func escapeXMLText(s string) string {
+	return xmlTextEscaper.Replace(xmlCharacters(s))
+}
+
+// escapeXMLAttribute escapes a double-quoted attribute value. Whitespace is escaped so that it survives
+// the attribute value normalization of XML parsers.
+func escapeXMLAttribute(s string) string {
+	return xmlAttributeEscaper.Replace(xmlCharacters(s))
+}
+
+var (
+	xmlTextEscaper      = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\r", "&#xD;")
+	xmlAttributeEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;",
+		"\t", "&#x9;", "\n", "&#xA;", "\r", "&#xD;")
+)
+
+// xmlCharacters replaces the characters which are not allowed in XML documents by U+FFFD.
+//
+// See https://www.w3.org/TR/xml/#charsets
+func xmlCharacters(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '\t' || r == '\n' || r == '\r' || (r >= 0x20 && r <= 0xd7ff) || (r >= 0xe000 && r <= 0xfffd) || r >= 0x10000 {
+			return r
+		}
+		return '�'
+	}, s)
+}
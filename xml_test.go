@@ -0,0 +1,142 @@
+package goDOM_test
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/richi0/goDOM"
+)
+
+func createDOMFromXML(s string) *goDOM.DOM {
+	dom, err := goDOM.NewXML(strings.NewReader(s))
+	if err != nil {
+		panic("Cannot create test dom object: " + err.Error())
+	}
+	return dom
+}
+
+func TestNewXML(t *testing.T) {
+	dom := createDOMFromXML(`<?xml version="1.0"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:svg="http://www.w3.org/2000/svg">
+<body><div id="empty"/><p>a&nbsp;b <![CDATA[<raw> & text]]></p>
+<svg:svg viewBox="0 0 10 10"><svg:linearGradient id="g"/><svg:rect/></svg:svg><?php echo 1; ?></body>
+</html>`)
+	empty := dom.GetElementById("empty")
+	if empty == nil || len(empty.ChildNodes()) != 0 || empty.NextElementSibling().TagName() != "p" {
+		t.Fatal("Expected self-closing div to be empty")
+	}
+	if text := dom.GetElementsByTagName("p")[0].Text(false); text != "a b <raw> & text" {
+		t.Errorf("Expected CDATA and entities to become text but got %q", text)
+	}
+	if len(dom.GetElementsByTagName("svg:linearGradient")) != 1 || len(dom.GetElementsByTagName("svg:lineargradient")) != 0 {
+		t.Error("Expected tag names to keep their case")
+	}
+	if dom.GetElementsByTagName("svg:svg")[0].Attributes()["viewBox"] != "0 0 10 10" {
+		t.Error("Expected attribute names to keep their case")
+	}
+	if created := dom.CreateElement("fooBar"); created.TagName() != "fooBar" {
+		t.Error("Expected created elements to keep their case but got", created.TagName())
+	}
+}
+
+func TestNewXMLMalformed(t *testing.T) {
+	inputs := []string{
+		"",
+		"<a><b></a></b>",
+		"<a>",
+		"<a></a><b></b>",
+		"<a x='1' x='2'/>",
+		"<svg:rect/>",
+		"<a svg:x='1'/>",
+		"text<a/>",
+		"<a>&unknown;</a>",
+	}
+	for _, input := range inputs {
+		if _, err := goDOM.NewXML(strings.NewReader(input)); !errors.Is(err, goDOM.ErrMalformedXML) {
+			t.Errorf("Expected ErrMalformedXML for %q but got %v", input, err)
+		}
+	}
+}
+
+func TestNewXMLEncoding(t *testing.T) {
+	dom, err := goDOM.NewXML(strings.NewReader("<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?><a>caf\xe9</a>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dom.Encoding() != "windows-1252" || dom.FirstElementChild().Text(false) != "café" {
+		t.Error("Expected input to be transcoded but got", dom.Encoding(), dom.FirstElementChild().Text(false))
+	}
+}
+
+func TestRenderXML(t *testing.T) {
+	input := `<!DOCTYPE html><html xmlns="http://www.w3.org/1999/xhtml"><body>` +
+		`<svg:svg xmlns:svg="http://www.w3.org/2000/svg"><svg:rect width="1"/></svg:svg>` +
+		`<p title="a &quot;b&quot;&#10;c">x &lt; y &amp;&amp; <b>z</b></p><!-- note --></body></html>`
+	rendered, err := createDOMFromXML(input).RenderXML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `<?xml version="1.0" encoding="UTF-8"?>` + "\n" + `<!DOCTYPE html><html xmlns="http://www.w3.org/1999/xhtml"><body>` +
+		`<svg:svg xmlns:svg="http://www.w3.org/2000/svg"><svg:rect width="1"/></svg:svg>` +
+		`<p title="a &quot;b&quot;&#xA;c">x &lt; y &amp;&amp; <b>z</b></p><!-- note --></body></html>`
+	if rendered != expected {
+		t.Errorf("Expected\n%s\nbut got\n%s", expected, rendered)
+	}
+	again, err := createDOMFromXML(rendered).RenderXML()
+	if err != nil || again != rendered {
+		t.Error("Expected rendered XML to round trip")
+	}
+}
+
+func TestRenderXMLWellFormed(t *testing.T) {
+	dom := createTestDOM()
+	comment := createDOMFromString("<!-- a -- b- -->\x01<br><p>x</p>")
+	for _, d := range []*goDOM.DOM{dom, comment} {
+		rendered, err := d.RenderXML()
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoder := xml.NewDecoder(strings.NewReader(rendered))
+		for {
+			_, err := decoder.Token()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatal("Expected well-formed XML but got", err)
+			}
+		}
+		parsed, err := goDOM.NewXML(strings.NewReader(rendered))
+		if err != nil {
+			t.Fatal("Expected rendered XML to be parsed by NewXML but got", err)
+		}
+		if len(parsed.GetElementsByTagName("a")) != len(d.GetElementsByTagName("a")) {
+			t.Error("Expected all elements in the rendered XML")
+		}
+	}
+}
+
+func TestRenderXMLNames(t *testing.T) {
+	dom := createDOMFromString(`<div id="d" x:y="1" ="q" a:b:c="3" xmlns:e=""><x:el>t</x:el><svg><a xlink:href="#"/></svg></div>`)
+	rendered, err := dom.GetElementById("d").RenderXML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `<div xmlns="http://www.w3.org/1999/xhtml" id="d" x:y="1" xmlns:x="http://www.w3.org/1999/xhtml">` +
+		`<x:el xmlns:x="http://www.w3.org/1999/xhtml">t</x:el><svg xmlns="http://www.w3.org/2000/svg">` +
+		`<a xlink:href="#" xmlns:xlink="http://www.w3.org/1999/xlink"/></svg></div>`
+	if rendered != expected {
+		t.Errorf("Expected\n%s\nbut got\n%s", expected, rendered)
+	}
+	parsed, err := goDOM.NewXML(strings.NewReader(rendered))
+	if err != nil {
+		t.Fatal("Expected rendered XML to be parsed by NewXML but got", err)
+	}
+	if parsed.GetElementsByTagName("x:el")[0].NamespaceURI() != goDOM.NamespaceHTML {
+		t.Error("Expected the prefix to be bound to the namespace of the element")
+	}
+}